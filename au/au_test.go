@@ -0,0 +1,86 @@
+package au
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	roundTripTests = []struct {
+		format    int
+		bits      int
+		tolerance float64
+	}{
+		{FormatPCM, 8, 1. / 127},
+		{FormatPCM, 16, 1. / 32767},
+		{FormatPCM, 24, 1. / 8388607},
+		{FormatPCM, 32, 1. / 2147483647},
+		{FormatFloat, 32, 1e-7},
+		{FormatFloat, 64, 0},
+		{FormatMuLaw, 8, 0.03},
+		{FormatALaw, 8, 0.03},
+	}
+
+	testFrames = []wave.Frame{0, 0.5, -0.5, 0.25, -0.999, 0.999, 0.001, -0.3}
+)
+
+// TestRoundTrip writes frames in each supported encoding and reads them back
+func TestRoundTrip(t *testing.T) {
+	for _, test := range roundTripTests {
+		t.Run("", func(t *testing.T) {
+			wfmt := wave.NewWaveFmt(test.format, 2, 8000, test.bits, nil)
+			buf := bytes.Buffer{}
+			if err := WriteAuToWriter(testFrames, wfmt, &buf); err != nil {
+				t.Fatalf("Should be able to write: %v", err)
+			}
+			w, err := ReadAuFromReader(&buf)
+			if err != nil {
+				t.Fatalf("Should be able to read: %v", err)
+			}
+			if w.SampleRate != 8000 || w.NumChannels != 2 || w.BitsPerSample != test.bits {
+				t.Fatalf("Unexpected fmt: %+v", w.WaveFmt)
+			}
+			if w.AudioFormat != test.format {
+				t.Fatalf("Expected format %v, got %v", test.format, w.AudioFormat)
+			}
+			if len(w.Frames) != len(testFrames) {
+				t.Fatalf("Expected %v frames, got %v", len(testFrames), len(w.Frames))
+			}
+			for i := range testFrames {
+				if math.Abs(float64(w.Frames[i]-testFrames[i])) > test.tolerance {
+					t.Fatalf("Expected %v, got %v at %v", testFrames[i], w.Frames[i], i)
+				}
+			}
+		})
+	}
+}
+
+// TestAnnotation makes sure the data offset is respected when an annotation is present
+func TestAnnotation(t *testing.T) {
+	data := []byte{
+		0x2e, 0x73, 0x6e, 0x64, // .snd
+		0x00, 0x00, 0x00, 0x20, // offset 32
+		0xff, 0xff, 0xff, 0xff, // unknown size
+		0x00, 0x00, 0x00, 0x03, // 16-bit linear
+		0x00, 0x00, 0x1f, 0x40, // 8000 Hz
+		0x00, 0x00, 0x00, 0x01, // mono
+		'h', 'e', 'l', 'l', 'o', 0x00, 0x00, 0x00, // annotation
+		0x7f, 0xff, 0x80, 0x01,
+	}
+	w, err := ReadAuFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Should be able to read: %v", err)
+	}
+	if len(w.Frames) != 2 || w.Frames[0] != 1 || w.Frames[1] != -1 {
+		t.Fatalf("Expected [1 -1], got %v", w.Frames)
+	}
+}
+
+func TestInvalidMagic(t *testing.T) {
+	if _, err := ReadAuFromReader(bytes.NewReader(make([]byte, 32))); err == nil {
+		t.Fatal("Expected an error for a file without .snd magic")
+	}
+}
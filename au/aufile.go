@@ -0,0 +1,106 @@
+package au
+
+import "github.com/DylanMeeus/GoAudio/audio"
+
+// representation of the Sun/NeXT .au file, used by reader.go and writer.go
+
+/*
+
+╔════════╤════════════════╤══════╤═══════════════════════════════════════════════════╗
+║ Offset │ Field          │ Size │ -- all fields are big-endian                      ║
+╠════════╪════════════════╪══════╪═══════════════════════════════════════════════════╣
+║ 0      │ Magic          │ 4    │ ".snd"                                            ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 4      │ DataOffset     │ 4    │ offset of the data, at least 24                   ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 8      │ DataSize       │ 4    │ size of the data, 0xffffffff if unknown           ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 12     │ Encoding       │ 4    │ see the Encoding constants                        ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 16     │ SampleRate     │ 4    │                                                   ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 20     │ Channels       │ 4    │                                                   ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 24     │ Annotation     │ *    │ Optional! Runs up to DataOffset                   ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ *      │ Data           │ *    │ interleaved samples                               ║
+╚════════╧════════════════╧══════╧═══════════════════════════════════════════════════╝
+
+*/
+
+// Encoding of the samples in the data section of an .au file
+type Encoding int
+
+// Encodings we can read and write
+const (
+	MULAW8   Encoding = 1
+	LINEAR8  Encoding = 2
+	LINEAR16 Encoding = 3
+	LINEAR24 Encoding = 4
+	LINEAR32 Encoding = 5
+	FLOAT32  Encoding = 6
+	FLOAT64  Encoding = 7
+	ALAW8    Encoding = 27
+)
+
+// Audio formats as they are stored in wave.WaveFmt.AudioFormat
+// these match the WAVE format tags so the fmt can be written as .wav as well.
+const (
+	FormatPCM   = 1
+	FormatFloat = 3
+	FormatALaw  = 6
+	FormatMuLaw = 7
+)
+
+// Consts that appear in the .au file format
+var (
+	Magic = []byte{0x2e, 0x73, 0x6e, 0x64} // .snd
+	// Format is stored in the WaveHeader to identify the origin of the data
+	Format = "AU"
+)
+
+const (
+	headerSize  = 24
+	unknownSize = 0xffffffff
+)
+
+// encodingProps describes how an encoding maps onto a wave.WaveFmt
+type encodingProps struct {
+	format int
+	bits   int
+}
+
+var (
+	encodings = map[Encoding]encodingProps{
+		MULAW8:   {FormatMuLaw, 8},
+		ALAW8:    {FormatALaw, 8},
+		LINEAR8:  {FormatPCM, 8},
+		LINEAR16: {FormatPCM, 16},
+		LINEAR24: {FormatPCM, 24},
+		LINEAR32: {FormatPCM, 32},
+		FLOAT32:  {FormatFloat, 32},
+		FLOAT64:  {FormatFloat, 64},
+	}
+
+	// max value depending on the bit size
+	maxValues = map[int]int{
+		8:  127,
+		16: 32767,
+		24: 8388607,
+		32: 2147483647,
+	}
+)
+
+// encodingFor returns the .au encoding that matches the format and bit depth
+func encodingFor(format, bits int) (Encoding, bool) {
+	for enc, props := range encodings {
+		if props.format == format && props.bits == bits {
+			return enc, true
+		}
+	}
+	return 0, false
+}
+
+func init() {
+	audio.RegisterFormat("au", string(Magic), ReadAuFromReader)
+}
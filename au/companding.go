@@ -0,0 +1,90 @@
+package au
+
+// G.711 μ-law and A-law companding, as used by the 8-bit .au encodings.
+
+const (
+	mulawBias = 0x84
+	mulawClip = 32635
+)
+
+var (
+	alawSegEnd = []int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+)
+
+// mulawToLinear expands a μ-law byte into a 16-bit linear sample
+func mulawToLinear(u byte) int {
+	u = ^u
+	exponent := int(u>>4) & 0x07
+	mantissa := int(u) & 0x0F
+	sample := (((mantissa << 3) + mulawBias) << exponent) - mulawBias
+	if u&0x80 != 0 {
+		return -sample
+	}
+	return sample
+}
+
+// linearToMulaw compresses a 16-bit linear sample into a μ-law byte
+func linearToMulaw(sample int) byte {
+	sign := 0
+	if sample < 0 {
+		sign = 0x80
+		sample = -sample
+	}
+	if sample > mulawClip {
+		sample = mulawClip
+	}
+	sample += mulawBias
+
+	exponent := 7
+	for mask := 0x4000; sample&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (sample >> (exponent + 3)) & 0x0F
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+// alawToLinear expands an A-law byte into a 16-bit linear sample
+func alawToLinear(a byte) int {
+	a ^= 0x55
+	t := int(a&0x0F) << 4
+	seg := int(a&0x70) >> 4
+	switch seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+	if a&0x80 != 0 {
+		return t
+	}
+	return -t
+}
+
+// linearToAlaw compresses a 16-bit linear sample into an A-law byte
+func linearToAlaw(sample int) byte {
+	sample >>= 3
+	mask := 0xD5
+	if sample < 0 {
+		mask = 0x55
+		sample = -sample - 1
+	}
+
+	seg := 0
+	for seg < len(alawSegEnd) && sample > alawSegEnd[seg] {
+		seg++
+	}
+	if seg >= len(alawSegEnd) {
+		return byte(0x7F ^ mask)
+	}
+
+	aval := seg << 4
+	if seg < 2 {
+		aval |= (sample >> 1) & 0x0F
+	} else {
+		aval |= (sample >> seg) & 0x0F
+	}
+	return byte(aval ^ mask)
+}
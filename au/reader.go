@@ -0,0 +1,120 @@
+package au
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// ReadAuFile parses a .au file into a wave.Wave struct
+func ReadAuFile(f string) (wave.Wave, error) {
+	file, err := os.Open(f)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+	defer file.Close()
+
+	return ReadAuFromReader(file)
+}
+
+// ReadAuFromReader parses an io.Reader containing .au data into a wave.Wave struct
+// The WaveFmt is filled in as if the data came from a .wav file, so the result can be
+// processed (and written) like any other Wave.
+func ReadAuFromReader(reader io.Reader) (wave.Wave, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+
+	if len(data) < headerSize || !bytes.Equal(data[0:4], Magic) {
+		return wave.Wave{}, errors.New("Invalid file, expected .snd magic number")
+	}
+
+	offset := int(binary.BigEndian.Uint32(data[4:8]))
+	size := int(binary.BigEndian.Uint32(data[8:12]))
+	enc := Encoding(binary.BigEndian.Uint32(data[12:16]))
+	sr := int(binary.BigEndian.Uint32(data[16:20]))
+	channels := int(binary.BigEndian.Uint32(data[20:24]))
+
+	props, ok := encodings[enc]
+	if !ok {
+		return wave.Wave{}, fmt.Errorf("Encoding %v not supported", enc)
+	}
+	if offset < headerSize || offset > len(data) {
+		return wave.Wave{}, fmt.Errorf("Invalid data offset %v", offset)
+	}
+	if channels == 0 {
+		return wave.Wave{}, errors.New("Invalid file, no channels")
+	}
+
+	raw := data[offset:]
+	if uint32(size) != unknownSize && size < len(raw) {
+		raw = raw[:size]
+	}
+
+	frames, err := parseRawData(raw, enc, props.bits)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+
+	wfmt := wave.NewWaveFmt(props.format, channels, sr, props.bits, nil)
+	return wave.Wave{
+		WaveHeader: wave.WaveHeader{
+			ChunkID:   data[0:4],
+			ChunkSize: len(raw),
+			Format:    Format,
+		},
+		WaveFmt: wfmt,
+		WaveData: wave.WaveData{
+			Subchunk2Size: len(raw),
+			RawData:       raw,
+			Frames:        frames,
+		},
+	}, nil
+}
+
+// parseRawData turns the big-endian sample data into scaled frames
+func parseRawData(raw []byte, enc Encoding, bits int) ([]wave.Frame, error) {
+	size := bits / 8
+	n := len(raw) / size
+	frames := make([]wave.Frame, n)
+	for i := 0; i < n; i++ {
+		b := raw[i*size : i*size+size]
+		switch enc {
+		case MULAW8:
+			frames[i] = scaleFrame(mulawToLinear(b[0]), 16)
+		case ALAW8:
+			frames[i] = scaleFrame(alawToLinear(b[0]), 16)
+		case LINEAR8:
+			frames[i] = scaleFrame(int(int8(b[0])), 8)
+		case LINEAR16:
+			frames[i] = scaleFrame(int(int16(binary.BigEndian.Uint16(b))), 16)
+		case LINEAR24:
+			v := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+			if v&0x800000 != 0 {
+				v -= 1 << 24
+			}
+			frames[i] = scaleFrame(v, 24)
+		case LINEAR32:
+			frames[i] = scaleFrame(int(int32(binary.BigEndian.Uint32(b))), 32)
+		case FLOAT32:
+			frames[i] = wave.Frame(math.Float32frombits(binary.BigEndian.Uint32(b)))
+		case FLOAT64:
+			frames[i] = wave.Frame(math.Float64frombits(binary.BigEndian.Uint64(b)))
+		default:
+			return nil, fmt.Errorf("Encoding %v not supported", enc)
+		}
+	}
+	return frames, nil
+}
+
+func scaleFrame(unscaled, bits int) wave.Frame {
+	return wave.Frame(float64(unscaled) / float64(maxValues[bits]))
+}
@@ -0,0 +1,88 @@
+package au
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// WriteAuFile writes the samples to disk as a .au file
+// The encoding is derived from the AudioFormat and BitsPerSample of the WaveFmt
+func WriteAuFile(samples []wave.Frame, wfmt wave.WaveFmt, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return WriteAuToWriter(samples, wfmt, f)
+}
+
+// WriteAuToWriter writes the samples as .au data to the writer
+func WriteAuToWriter(samples []wave.Frame, wfmt wave.WaveFmt, writer io.Writer) error {
+	enc, ok := encodingFor(wfmt.AudioFormat, wfmt.BitsPerSample)
+	if !ok {
+		return fmt.Errorf("Format %v with %v bits per sample can't be stored as .au",
+			wfmt.AudioFormat, wfmt.BitsPerSample)
+	}
+
+	raw := samplesToRawData(samples, enc, wfmt.BitsPerSample)
+
+	hdr := make([]byte, headerSize)
+	copy(hdr[0:4], Magic)
+	binary.BigEndian.PutUint32(hdr[4:8], headerSize)
+	binary.BigEndian.PutUint32(hdr[8:12], uint32(len(raw)))
+	binary.BigEndian.PutUint32(hdr[12:16], uint32(enc))
+	binary.BigEndian.PutUint32(hdr[16:20], uint32(wfmt.SampleRate))
+	binary.BigEndian.PutUint32(hdr[20:24], uint32(wfmt.NumChannels))
+
+	if _, err := writer.Write(hdr); err != nil {
+		return err
+	}
+	_, err := writer.Write(raw)
+	return err
+}
+
+// samplesToRawData turns the frames into big-endian sample data
+func samplesToRawData(samples []wave.Frame, enc Encoding, bits int) []byte {
+	size := bits / 8
+	raw := make([]byte, len(samples)*size)
+	for i, s := range samples {
+		b := raw[i*size : i*size+size]
+		switch enc {
+		case MULAW8:
+			b[0] = linearToMulaw(rescaleFrame(s, 16))
+		case ALAW8:
+			b[0] = linearToAlaw(rescaleFrame(s, 16))
+		case LINEAR8:
+			b[0] = byte(int8(rescaleFrame(s, 8)))
+		case LINEAR16:
+			binary.BigEndian.PutUint16(b, uint16(rescaleFrame(s, 16)))
+		case LINEAR24:
+			v := rescaleFrame(s, 24)
+			b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+		case LINEAR32:
+			binary.BigEndian.PutUint32(b, uint32(rescaleFrame(s, 32)))
+		case FLOAT32:
+			binary.BigEndian.PutUint32(b, math.Float32bits(float32(s)))
+		case FLOAT64:
+			binary.BigEndian.PutUint64(b, math.Float64bits(float64(s)))
+		}
+	}
+	return raw
+}
+
+// rescale frames back to integer values, clipping anything outside of [-1, 1]
+func rescaleFrame(s wave.Frame, bits int) int {
+	f := float64(s)
+	if f > 1 {
+		f = 1
+	} else if f < -1 {
+		f = -1
+	}
+	return int(math.Round(f * float64(maxValues[bits])))
+}
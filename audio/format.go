@@ -0,0 +1,135 @@
+// Package audio implements a registry of audio formats, so files can be decoded without
+// knowing their encoding up front.
+//
+// Decoding any particular format requires the format to be registered first. WAVE is
+// registered by default, other formats register themselves when their package is
+// imported, usually for side-effects only:
+//
+//	import _ "github.com/DylanMeeus/GoAudio/au"
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// ErrFormat indicates that decoding encountered an unknown format.
+var ErrFormat = errors.New("audio: unknown format")
+
+// DecodeFunc decodes the data of a single format into a wave.Wave
+type DecodeFunc func(io.Reader) (wave.Wave, error)
+
+// format holds a registered format's name, magic header and how to decode it
+type format struct {
+	name, magic string
+	decode      DecodeFunc
+}
+
+var (
+	formatsMu     sync.Mutex
+	atomicFormats atomic.Value
+)
+
+func init() {
+	RegisterFormat("wav", "RIFF????WAVE", decodeWave)
+}
+
+// RegisterFormat registers an audio format for use by Decode.
+// Name is the name of the format, like "wav" or "au".
+// Magic is the magic prefix that identifies the format's encoding. The magic
+// string can contain "?" wildcards that each match any one byte.
+// Decode is the function that decodes the encoded audio.
+func RegisterFormat(name, magic string, decode DecodeFunc) {
+	formatsMu.Lock()
+	formats, _ := atomicFormats.Load().([]format)
+	atomicFormats.Store(append(formats, format{name, magic, decode}))
+	formatsMu.Unlock()
+}
+
+// Decode decodes audio that has been encoded in a registered format.
+// The string returned is the format name used during format registration.
+func Decode(r io.Reader) (wave.Wave, string, error) {
+	rr := asReader(r)
+	f := sniff(rr)
+	if f.decode == nil {
+		return wave.Wave{}, "", ErrFormat
+	}
+	w, err := f.decode(rr)
+	return w, f.name, err
+}
+
+// DecodeFile opens the file and decodes it with Decode
+func DecodeFile(file string) (wave.Wave, string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return wave.Wave{}, "", err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Formats returns the names of all registered formats in order of registration
+func Formats() []string {
+	formats, _ := atomicFormats.Load().([]format)
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.name
+	}
+	return names
+}
+
+// reader is an io.Reader that can also peek ahead.
+type reader interface {
+	io.Reader
+	Peek(int) ([]byte, error)
+}
+
+// asReader converts an io.Reader to a reader.
+func asReader(r io.Reader) reader {
+	if rr, ok := r.(reader); ok {
+		return rr
+	}
+	return bufio.NewReader(r)
+}
+
+// match reports whether magic matches b. Magic may contain "?" wildcards.
+func match(magic string, b []byte) bool {
+	if len(magic) != len(b) {
+		return false
+	}
+	for i, c := range b {
+		if magic[i] != c && magic[i] != '?' {
+			return false
+		}
+	}
+	return true
+}
+
+// sniff determines the format of r's data.
+func sniff(r reader) format {
+	formats, _ := atomicFormats.Load().([]format)
+	for _, f := range formats {
+		b, err := r.Peek(len(f.magic))
+		if err == nil && match(f.magic, b) {
+			return f
+		}
+	}
+	return format{}
+}
+
+// decodeWave wraps wave.ReadWaveFromReader, which panics on malformed input
+func decodeWave(r io.Reader) (w wave.Wave, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("audio: invalid wav data: %v", rec)
+		}
+	}()
+	return wave.ReadWaveFromReader(r)
+}
@@ -0,0 +1,63 @@
+package audio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/au"
+	"github.com/DylanMeeus/GoAudio/audio"
+	"github.com/DylanMeeus/GoAudio/w64"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	testFrames = []wave.Frame{0, 0.5, -0.5, 0.25}
+
+	decodeTests = []struct {
+		name  string
+		write func([]wave.Frame, wave.WaveFmt, io.Writer) error
+	}{
+		{"wav", wave.WriteWaveToWriter},
+		{"au", au.WriteAuToWriter},
+		{"w64", w64.WriteW64ToWriter},
+	}
+)
+
+// TestDecode makes sure each registered format is sniffed and decoded
+func TestDecode(t *testing.T) {
+	wfmt := wave.NewWaveFmt(1, 2, 22050, 16, nil)
+	for _, test := range decodeTests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := test.write(testFrames, wfmt, &buf); err != nil {
+				t.Fatalf("Should be able to write: %v", err)
+			}
+			w, name, err := audio.Decode(&buf)
+			if err != nil {
+				t.Fatalf("Should be able to decode: %v", err)
+			}
+			if name != test.name {
+				t.Fatalf("Expected format %v, got %v", test.name, name)
+			}
+			if w.SampleRate != 22050 || w.NumChannels != 2 || len(w.Frames) != len(testFrames) {
+				t.Fatalf("Unexpected result: %+v, %v frames", w.WaveFmt, len(w.Frames))
+			}
+		})
+	}
+}
+
+func TestDecodeUnknown(t *testing.T) {
+	_, _, err := audio.Decode(bytes.NewReader([]byte("definitely not audio")))
+	if err != audio.ErrFormat {
+		t.Fatalf("Expected ErrFormat, got %v", err)
+	}
+}
+
+// TestDecodeWavError makes sure a broken wav file returns an error instead of panicking
+func TestDecodeWavError(t *testing.T) {
+	_, name, err := audio.Decode(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WAVE")))
+	if err == nil || name != "wav" {
+		t.Fatalf("Expected an error decoding wav, got %v (%v)", err, name)
+	}
+}
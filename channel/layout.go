@@ -0,0 +1,111 @@
+// Package channel maps audio between speaker layouts, such as folding 5.1 down to stereo
+// or spreading mono over a stereo pair.
+//
+// Mixing is described by a Matrix, which can either be derived from two named layouts
+// or supplied by the caller.
+package channel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Speaker is a single channel position within a layout
+type Speaker int
+
+// Speaker positions, matching the channel masks of WAVE_FORMAT_EXTENSIBLE
+const (
+	FL  Speaker = iota // front left
+	FR                 // front right
+	FC                 // front centre
+	LFE                // low frequency effects
+	BL                 // back left
+	BR                 // back right
+	SL                 // side left
+	SR                 // side right
+)
+
+var (
+	speakerNames = map[Speaker]string{
+		FL:  "FL",
+		FR:  "FR",
+		FC:  "FC",
+		LFE: "LFE",
+		BL:  "BL",
+		BR:  "BR",
+		SL:  "SL",
+		SR:  "SR",
+	}
+)
+
+func (s Speaker) String() string {
+	if n, ok := speakerNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("Speaker(%d)", int(s))
+}
+
+// Layout is a named, ordered set of speakers.
+// Interleaved frames store one sample per speaker in this order.
+type Layout struct {
+	Name     string
+	Speakers []Speaker
+}
+
+// Layouts for which we know how to mix, in WAVE channel order
+var (
+	Mono       = Layout{"mono", []Speaker{FC}}
+	Stereo     = Layout{"stereo", []Speaker{FL, FR}}
+	Surround21 = Layout{"2.1", []Speaker{FL, FR, LFE}}
+	Quad       = Layout{"quad", []Speaker{FL, FR, BL, BR}}
+	Surround51 = Layout{"5.1", []Speaker{FL, FR, FC, LFE, BL, BR}}
+	Surround71 = Layout{"7.1", []Speaker{FL, FR, FC, LFE, BL, BR, SL, SR}}
+
+	layouts = []Layout{Mono, Stereo, Surround21, Quad, Surround51, Surround71}
+)
+
+// Channels returns the number of channels in the layout
+func (l Layout) Channels() int {
+	return len(l.Speakers)
+}
+
+// index returns the position of the speaker in the layout, or -1
+func (l Layout) index(s Speaker) int {
+	for i, sp := range l.Speakers {
+		if sp == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// has returns true if all the speakers are part of the layout
+func (l Layout) has(ss ...Speaker) bool {
+	for _, s := range ss {
+		if l.index(s) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLayout returns the layout with the given name (e.g "5.1" or "stereo")
+func ParseLayout(name string) (Layout, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, l := range layouts {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return Layout{}, fmt.Errorf("Unknown layout %q", name)
+}
+
+// DefaultLayout returns the layout usually meant by a number of channels
+func DefaultLayout(channels int) (Layout, error) {
+	for _, l := range layouts {
+		if l.Channels() == channels {
+			return l, nil
+		}
+	}
+	return Layout{}, fmt.Errorf("No default layout for %v channels", channels)
+}
@@ -0,0 +1,241 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// minus3dB is the ITU-R BS.775 gain for folding centre and surrounds into the front pair
+var minus3dB = math.Sqrt(0.5)
+
+// Matrix describes how input channels are mixed into output channels.
+// Matrix[out][in] is the gain of input channel 'in' in output channel 'out'.
+type Matrix [][]float64
+
+// Options tweak the matrices created by MixMatrix
+type Options struct {
+	// LFEGain is the gain of the LFE channel when the target has no LFE speaker.
+	// ITU-R BS.775 discards it, which is the default of 0.
+	LFEGain float64
+	// Normalize scales the output channels so their gains sum to at most 1,
+	// which guarantees the mix can't clip.
+	Normalize bool
+}
+
+// Inputs returns the number of input channels the matrix expects
+func (m Matrix) Inputs() int {
+	if len(m) == 0 {
+		return 0
+	}
+	return len(m[0])
+}
+
+// Outputs returns the number of channels the matrix produces
+func (m Matrix) Outputs() int {
+	return len(m)
+}
+
+// validate makes sure the matrix is rectangular and not empty
+func (m Matrix) validate() error {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return errors.New("Matrix needs at least one input and output")
+	}
+	for _, row := range m {
+		if len(row) != len(m[0]) {
+			return errors.New("Matrix rows should all have the same length")
+		}
+	}
+	return nil
+}
+
+// multiply returns the matrix applying b first, and then a
+func multiply(a, b Matrix) Matrix {
+	out := make(Matrix, a.Outputs())
+	for i := range out {
+		out[i] = make([]float64, b.Inputs())
+		for j := range out[i] {
+			for k := 0; k < a.Inputs(); k++ {
+				out[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return out
+}
+
+// MixMatrix creates the matrix to go from one layout to another.
+// Downmixing uses the ITU-R BS.775 coefficients: centre and surrounds are folded into the
+// front pair at -3dB and the LFE is dropped. Mono takes the average of the stereo downmix,
+// so it stays compatible with the stereo fold.
+// Upmixing is kept simple: mono goes to the centre, or to the front pair at -3dB when there
+// is no centre, and speakers missing from the source stay silent.
+func MixMatrix(from, to Layout, opts Options) (Matrix, error) {
+	if from.Channels() == 0 || to.Channels() == 0 {
+		return nil, errors.New("Layouts need at least one speaker")
+	}
+
+	if to.Channels() == 1 && from.Channels() > 1 {
+		// mono downmix: fold to stereo, then average
+		if from.Channels() == 2 && from.has(FL, FR) {
+			return normalize(Matrix{{0.5, 0.5}}, opts), nil
+		}
+		stereo, err := MixMatrix(from, Stereo, Options{LFEGain: opts.LFEGain})
+		if err != nil {
+			return nil, err
+		}
+		return normalize(multiply(Matrix{{0.5, 0.5}}, stereo), opts), nil
+	}
+
+	m := make(Matrix, to.Channels())
+	for i := range m {
+		m[i] = make([]float64, from.Channels())
+	}
+	for in, s := range from.Speakers {
+		gains, err := fold(s, to, opts)
+		if err != nil {
+			return nil, err
+		}
+		for out, g := range gains {
+			m[out][in] += g
+		}
+	}
+	return normalize(m, opts), nil
+}
+
+// fold returns the gain per output channel for a single input speaker
+func fold(s Speaker, to Layout, opts Options) (map[int]float64, error) {
+	if i := to.index(s); i >= 0 {
+		return map[int]float64{i: 1}, nil
+	}
+
+	// pairs of speakers on the same side of the room
+	left := map[Speaker]bool{FL: true, BL: true, SL: true}
+	front, back, side := FR, BR, SR
+	if left[s] {
+		front, back, side = FL, BL, SL
+	}
+
+	switch s {
+	case FC:
+		if to.has(FL, FR) {
+			return map[int]float64{to.index(FL): minus3dB, to.index(FR): minus3dB}, nil
+		}
+	case LFE:
+		if opts.LFEGain == 0 {
+			return nil, nil
+		}
+		if to.has(FC) {
+			return map[int]float64{to.index(FC): opts.LFEGain}, nil
+		}
+		if to.has(FL, FR) {
+			return map[int]float64{to.index(FL): opts.LFEGain, to.index(FR): opts.LFEGain}, nil
+		}
+	case BL, BR:
+		if to.has(side) {
+			return map[int]float64{to.index(side): minus3dB}, nil
+		}
+		if to.has(front) {
+			return map[int]float64{to.index(front): minus3dB}, nil
+		}
+	case SL, SR:
+		if to.has(back) {
+			return map[int]float64{to.index(back): minus3dB}, nil
+		}
+		if to.has(front) {
+			return map[int]float64{to.index(front): minus3dB}, nil
+		}
+	case FL, FR:
+		if to.has(FC) {
+			return map[int]float64{to.index(FC): minus3dB}, nil
+		}
+	}
+	return nil, fmt.Errorf("Can't map speaker %v to layout %v", s, to.Name)
+}
+
+// normalize scales down rows whose gains sum to more than 1, if requested
+func normalize(m Matrix, opts Options) Matrix {
+	if !opts.Normalize {
+		return m
+	}
+	for _, row := range m {
+		sum := 0.0
+		for _, g := range row {
+			sum += math.Abs(g)
+		}
+		if sum > 1 {
+			for i := range row {
+				row[i] /= sum
+			}
+		}
+	}
+	return m
+}
+
+// Apply mixes the interleaved frames with the matrix.
+// The input needs to have Matrix.Inputs() channels, the output has Matrix.Outputs() channels.
+func Apply(frames []wave.Frame, m Matrix) ([]wave.Frame, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	in, outs := m.Inputs(), m.Outputs()
+	if len(frames)%in != 0 {
+		return nil, fmt.Errorf("Expected a multiple of %v samples, got %v", in, len(frames))
+	}
+	n := len(frames) / in
+	out := make([]wave.Frame, n*outs)
+	for i := 0; i < n; i++ {
+		src := frames[i*in : i*in+in]
+		for o, row := range m {
+			sum := 0.0
+			for c, g := range row {
+				sum += g * float64(src[c])
+			}
+			out[i*outs+o] = wave.Frame(sum)
+		}
+	}
+	return out, nil
+}
+
+// Mix applies the matrix to the frames and updates the fmt to the new channel count
+func Mix(frames []wave.Frame, wfmt *wave.WaveFmt, m Matrix) ([]wave.Frame, error) {
+	if wfmt.NumChannels != m.Inputs() {
+		return nil, fmt.Errorf("Matrix expects %v channels, fmt has %v", m.Inputs(), wfmt.NumChannels)
+	}
+	out, err := Apply(frames, m)
+	if err != nil {
+		return nil, err
+	}
+	wfmt.SetChannels(uint(m.Outputs()))
+	return out, nil
+}
+
+// Remix converts the frames from one layout to another, updating the fmt
+func Remix(frames []wave.Frame, wfmt *wave.WaveFmt, from, to Layout, opts Options) ([]wave.Frame, error) {
+	m, err := MixMatrix(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Mix(frames, wfmt, m)
+}
+
+// RemixFunc returns a wave.RemixFunc that mixes between the default layouts for the
+// channel counts, for use with wave.Convert
+func RemixFunc(opts Options) wave.RemixFunc {
+	return func(frames []wave.Frame, from, to int) ([]wave.Frame, error) {
+		fl, err := DefaultLayout(from)
+		if err != nil {
+			return nil, err
+		}
+		tl, err := DefaultLayout(to)
+		if err != nil {
+			return nil, err
+		}
+		m, err := MixMatrix(fl, tl, opts)
+		if err != nil {
+			return nil, err
+		}
+		return Apply(frames, m)
+	}
+}
@@ -0,0 +1,134 @@
+package channel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	r = math.Sqrt(0.5)
+
+	mixMatrixTests = []struct {
+		from, to Layout
+		out      Matrix
+	}{
+		{Stereo, Stereo, Matrix{{1, 0}, {0, 1}}},
+		{Mono, Stereo, Matrix{{r}, {r}}},
+		{Stereo, Mono, Matrix{{0.5, 0.5}}},
+		{Mono, Surround51, Matrix{{0}, {0}, {1}, {0}, {0}, {0}}},
+		{Stereo, Quad, Matrix{{1, 0}, {0, 1}, {0, 0}, {0, 0}}},
+		{Surround51, Stereo, Matrix{
+			{1, 0, r, 0, r, 0},
+			{0, 1, r, 0, 0, r},
+		}},
+		{Surround71, Stereo, Matrix{
+			{1, 0, r, 0, r, 0, r, 0},
+			{0, 1, r, 0, 0, r, 0, r},
+		}},
+		{Surround71, Surround51, Matrix{
+			{1, 0, 0, 0, 0, 0, 0, 0},
+			{0, 1, 0, 0, 0, 0, 0, 0},
+			{0, 0, 1, 0, 0, 0, 0, 0},
+			{0, 0, 0, 1, 0, 0, 0, 0},
+			{0, 0, 0, 0, 1, 0, r, 0},
+			{0, 0, 0, 0, 0, 1, 0, r},
+		}},
+		{Surround51, Mono, Matrix{{0.5, 0.5, r, 0, r / 2, r / 2}}},
+		{Surround21, Stereo, Matrix{{1, 0, 0}, {0, 1, 0}}},
+		{Quad, Surround21, Matrix{{1, 0, r, 0}, {0, 1, 0, r}, {0, 0, 0, 0}}},
+	}
+)
+
+func matricesEqual(a, b Matrix) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if math.Abs(a[i][j]-b[i][j]) > 1e-12 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestMixMatrix(t *testing.T) {
+	for _, test := range mixMatrixTests {
+		t.Run(test.from.Name+"->"+test.to.Name, func(t *testing.T) {
+			m, err := MixMatrix(test.from, test.to, Options{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !matricesEqual(m, test.out) {
+				t.Fatalf("expected %v, got %v", test.out, m)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	m, err := MixMatrix(Surround51, Stereo, Options{LFEGain: 0.5, Normalize: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sum := 1 + 2*r + 0.5
+	expected := Matrix{
+		{1 / sum, 0, r / sum, 0.5 / sum, r / sum, 0},
+		{0, 1 / sum, r / sum, 0.5 / sum, 0, r / sum},
+	}
+	if !matricesEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+}
+
+func TestMix(t *testing.T) {
+	wfmt := wave.NewWaveFmt(1, 2, 44100, 16, nil)
+	// user supplied matrix swapping left and right, and adding a mid channel
+	m := Matrix{{0, 1}, {1, 0}, {0.5, 0.5}}
+	out, err := Mix([]wave.Frame{1, 0, 0.5, -0.5}, &wfmt, m)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []wave.Frame{0, 1, 0.5, -0.5, 0.5, 0}
+	for i := range expected {
+		if out[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, out)
+		}
+	}
+	if wfmt.NumChannels != 3 || wfmt.BlockAlign != 6 || wfmt.ByteRate != 44100*6 {
+		t.Fatalf("Fmt was not updated: %+v", wfmt)
+	}
+
+	if _, err := Mix([]wave.Frame{1, 0, 0}, &wfmt, Matrix{{1, 0}}); err == nil {
+		t.Fatal("Expected an error for a mismatched matrix")
+	}
+}
+
+func TestRemixFunc(t *testing.T) {
+	out, err := RemixFunc(Options{})([]wave.Frame{1, 1, 1, 0, 1, 1}, 6, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(out) != 2 || math.Abs(float64(out[0])-(1+2*r)) > 1e-12 {
+		t.Fatalf("Unexpected downmix %v", out)
+	}
+	if _, err := RemixFunc(Options{})([]wave.Frame{1, 1, 1, 1, 1}, 5, 2); err == nil {
+		t.Fatal("Expected an error for a channel count without layout")
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	l, err := ParseLayout(" 5.1 ")
+	if err != nil || l.Channels() != 6 {
+		t.Fatalf("Expected 5.1 layout, got %v (%v)", l, err)
+	}
+	if _, err := ParseLayout("9.1.6"); err == nil {
+		t.Fatal("Expected an error for an unknown layout")
+	}
+}
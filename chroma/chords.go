@@ -0,0 +1,136 @@
+package chroma
+
+import (
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Chord is a major or minor triad. A Root of -1 means there is no chord (silence).
+type Chord struct {
+	Root int
+	Mode Mode
+}
+
+// NoChord is used where nothing is playing
+var NoChord = Chord{Root: -1}
+
+// String returns the name of the chord, e.g "A minor", or "N" for no chord
+func (c Chord) String() string {
+	if c.Root < 0 {
+		return "N"
+	}
+	return PitchClassName(c.Root) + " " + c.Mode.String()
+}
+
+// Segment is a chord that plays from Start until End (in seconds)
+type Segment struct {
+	Start, End float64
+	Chord      Chord
+}
+
+// MatchChord returns the triad whose template is closest (cosine similarity) to a frame
+// of the chromagram, and the similarity
+func MatchChord(frame []float64) (Chord, float64) {
+	norm := 0.0
+	for _, v := range frame {
+		norm += v * v
+	}
+	if norm == 0 {
+		return NoChord, 0
+	}
+	best, bestScore := NoChord, 0.0
+	for root := 0; root < 12; root++ {
+		for _, mode := range []Mode{MAJOR, MINOR} {
+			third := 4
+			if mode == MINOR {
+				third = 3
+			}
+			// the template has 1 at the root, third and fifth, so the dot product is
+			// the sum of those and the template norm is sqrt(3)
+			sum := frame[root] + frame[(root+third)%12] + frame[(root+7)%12]
+			if score := sum / math.Sqrt(3*norm); score > bestScore {
+				best, bestScore = Chord{root, mode}, score
+			}
+		}
+	}
+	return best, bestScore
+}
+
+// Chords recognizes the chords in a wave and returns them as segments.
+// The chromagram is smoothed over smoothing frames (centred) first, to avoid short spurious
+// chords. Frames that are silent, relative to the loudest frame, have no chord.
+func Chords(w wave.Wave, opts Options, smoothing int) ([]Segment, error) {
+	chroma, err := Chromagram(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	energy := frameEnergy(w, len(chroma), opts)
+	// every frame covers from halfway the previous frame to halfway the next
+	times := Times(len(chroma)+1, w.SampleRate, opts)
+	half := times[1] / 2
+	for i := range times {
+		times[i] = math.Max(0, times[i]-half)
+	}
+
+	segments := []Segment{}
+	for t := range chroma {
+		chord := NoChord
+		if energy[t] > 0 {
+			chord, _ = MatchChord(smooth(chroma, t, smoothing))
+		}
+		if n := len(segments); n > 0 && segments[n-1].Chord == chord {
+			segments[n-1].End = times[t+1]
+			continue
+		}
+		segments = append(segments, Segment{Start: times[t], End: times[t+1], Chord: chord})
+	}
+	return segments, nil
+}
+
+// smooth returns the average of the rows around t
+func smooth(chroma [][]float64, t, width int) []float64 {
+	out := make([]float64, 12)
+	lo, hi := t-width/2, t+width/2
+	for i := lo; i <= hi; i++ {
+		if i < 0 || i >= len(chroma) {
+			continue
+		}
+		for pc, v := range chroma[i] {
+			out[pc] += v
+		}
+	}
+	return out
+}
+
+// frameEnergy returns the RMS of the samples around every chromagram frame, zeroed when
+// more than 40 dB below the loudest frame
+func frameEnergy(w wave.Wave, frames int, opts Options) []float64 {
+	opts = opts.withDefaults()
+	mono := wave.MixToMono(w.Frames, w.NumChannels)
+	out := make([]float64, frames)
+	max := 0.0
+	for t := range out {
+		lo, hi := t*opts.HopSize-opts.HopSize/2, t*opts.HopSize+opts.HopSize/2
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(mono) {
+			hi = len(mono)
+		}
+		sum := 0.0
+		for _, f := range mono[lo:hi] {
+			sum += float64(f) * float64(f)
+		}
+		if hi > lo {
+			out[t] = math.Sqrt(sum / float64(hi-lo))
+		}
+		max = math.Max(max, out[t])
+	}
+	for t := range out {
+		if out[t] < max/100 {
+			out[t] = 0
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+// Package chroma computes pitch class profiles (chromagrams) of audio, and uses them to
+// estimate the key and recognize chords.
+//
+// Pitch classes are numbered from C: 0 = C, 1 = C#, .. 11 = B.
+package chroma
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	synth "github.com/DylanMeeus/GoAudio/synthesizer"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Options configure the chromagram.
+// Zero values are replaced by the defaults.
+type Options struct {
+	FrameSize int     // STFT frame size, default 8192 to resolve low notes
+	HopSize   int     // samples between frames, default 2048
+	MinFreq   float64 // lowest frequency taken into account, default 55 Hz (A1)
+	MaxFreq   float64 // highest frequency taken into account, default 5000 Hz
+}
+
+func (o Options) withDefaults() Options {
+	if o.FrameSize == 0 {
+		o.FrameSize = 8192
+	}
+	if o.HopSize == 0 {
+		o.HopSize = 2048
+	}
+	if o.MinFreq == 0 {
+		o.MinFreq = 55
+	}
+	if o.MaxFreq == 0 {
+		o.MaxFreq = 5000
+	}
+	return o
+}
+
+// PitchClassName returns the name of a pitch class, e.g "C" or "F#"
+func PitchClassName(pc int) string {
+	// the synthesizer counts from A, which is 3 semitones below C
+	return synth.NoteName(pc + 3)
+}
+
+// Chromagram returns the energy of every pitch class, one row of 12 values per STFT frame.
+// Every bin of the spectrum adds its power to the nearest pitch class. Rows are normalised
+// to a maximum of 1, silent frames stay 0. Channels are mixed to mono first.
+func Chromagram(w wave.Wave, opts Options) ([][]float64, error) {
+	opts = opts.withDefaults()
+	if w.SampleRate <= 0 {
+		return nil, errors.New("Sample rate should be positive")
+	}
+	if opts.MinFreq <= 0 || opts.MaxFreq <= opts.MinFreq {
+		return nil, errors.New("Frequency range should be positive")
+	}
+	spectrum, err := audiomath.STFT(wave.MixToMono(w.Frames, w.NumChannels), audiomath.STFTConfig{
+		FrameSize: opts.FrameSize,
+		HopSize:   opts.HopSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// pitch class of every bin, or -1 outside the range
+	classes := make([]int, opts.FrameSize/2+1)
+	for k := range classes {
+		f := float64(k) * float64(w.SampleRate) / float64(opts.FrameSize)
+		classes[k] = -1
+		if f >= opts.MinFreq && f <= opts.MaxFreq {
+			classes[k] = pitchClass(f)
+		}
+	}
+
+	out := make([][]float64, len(spectrum))
+	for t, frame := range spectrum {
+		out[t] = make([]float64, 12)
+		for k, c := range frame {
+			if classes[k] >= 0 {
+				a := cmplx.Abs(c)
+				out[t][classes[k]] += a * a
+			}
+		}
+		normalize(out[t])
+	}
+	return out, nil
+}
+
+// Times returns the time in seconds of every row of a chromagram
+func Times(rows, sr int, opts Options) []float64 {
+	opts = opts.withDefaults()
+	out := make([]float64, rows)
+	for i := range out {
+		out[i] = float64(i*opts.HopSize) / float64(sr)
+	}
+	return out
+}
+
+// pitchClass returns the pitch class of the note nearest to the frequency
+func pitchClass(f float64) int {
+	// semitones above A, which is pitch class 9
+	semitones := int(math.Round(12 * math.Log2(f/440)))
+	return ((semitones+9)%12 + 12) % 12
+}
+
+// normalize scales the values to a maximum of 1
+func normalize(xs []float64) {
+	max := 0.0
+	for _, x := range xs {
+		max = math.Max(max, x)
+	}
+	if max == 0 {
+		return
+	}
+	for i := range xs {
+		xs[i] /= max
+	}
+}
@@ -0,0 +1,133 @@
+package chroma
+
+import (
+	"math"
+	"testing"
+
+	synth "github.com/DylanMeeus/GoAudio/synthesizer"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 22050
+
+// render plays every group of notes for the duration, notes have a few harmonics
+func render(duration float64, chords ...[]string) wave.Wave {
+	frames := []wave.Frame{}
+	n := int(duration * sr)
+	for _, notes := range chords {
+		chunk := make([]wave.Frame, n)
+		for _, note := range notes {
+			freq, err := synth.ParseNoteToFrequency(note)
+			if err != nil {
+				panic(err)
+			}
+			for h := 1; h <= 4; h++ {
+				for i := range chunk {
+					chunk[i] += wave.Frame(0.1 / float64(h) * math.Sin(2*math.Pi*freq*float64(h)*float64(i)/sr))
+				}
+			}
+		}
+		frames = append(frames, chunk...)
+	}
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 1, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}
+}
+
+func TestPitchClassName(t *testing.T) {
+	expected := []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	for pc, name := range expected {
+		if n := PitchClassName(pc); n != name {
+			t.Fatalf("Expected pitch class %v to be %v, got %v", pc, name, n)
+		}
+	}
+}
+
+func TestChromagram(t *testing.T) {
+	chroma, err := Chromagram(render(1, []string{"c4", "e4", "g4"}), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	row := chroma[len(chroma)/2]
+	for pc, v := range row {
+		inChord := pc == 0 || pc == 4 || pc == 7
+		if inChord && v < 0.3 || !inChord && v > 0.1 {
+			t.Fatalf("Unexpected chroma for C major: %v", row)
+		}
+	}
+}
+
+func TestMatchChord(t *testing.T) {
+	tests := []struct {
+		notes    []string
+		expected string
+	}{
+		{[]string{"c4", "e4", "g4"}, "C major"},
+		{[]string{"a3", "c4", "e4"}, "A minor"},
+		{[]string{"f#3", "a#3", "c#4"}, "F# major"},
+		{[]string{"eb4", "gb4", "bb4"}, "D# minor"},
+		// inversions are the same chord
+		{[]string{"b3", "d4", "g4"}, "G major"},
+	}
+	for _, test := range tests {
+		chroma, _ := Chromagram(render(1, test.notes), Options{})
+		if c, _ := MatchChord(chroma[len(chroma)/2]); c.String() != test.expected {
+			t.Fatalf("Expected %v for %v, got %v", test.expected, test.notes, c)
+		}
+	}
+	if c, _ := MatchChord(make([]float64, 12)); c != NoChord || c.String() != "N" {
+		t.Fatalf("Expected no chord for silence, got %v", c)
+	}
+}
+
+func TestChords(t *testing.T) {
+	w := render(1,
+		[]string{"c4", "e4", "g4"},
+		[]string{"a3", "c4", "e4"},
+		[]string{},
+		[]string{"g3", "b3", "d4"},
+	)
+	segments, err := Chords(w, Options{}, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"C major", "A minor", "N", "G major"}
+	if len(segments) != len(expected) {
+		t.Fatalf("Expected %v segments, got %+v", len(expected), segments)
+	}
+	for i, s := range segments {
+		if s.Chord.String() != expected[i] {
+			t.Fatalf("Expected %v for segment %v, got %v", expected[i], i, s.Chord)
+		}
+		// boundaries within a hop of the truth (~93 ms)
+		if i > 0 && math.Abs(s.Start-float64(i)) > 0.1 {
+			t.Fatalf("Expected segment %v to start at %v s, got %v", i, i, s.Start)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		chords   [][]string
+		expected string
+	}{
+		// I - IV - V - I
+		{[][]string{{"c4", "e4", "g4"}, {"f4", "a4", "c5"}, {"g4", "b4", "d5"}, {"c4", "e4", "g4"}}, "C major"},
+		{[][]string{{"d4", "f#4", "a4"}, {"g4", "b4", "d5"}, {"a4", "c#5", "e5"}, {"d4", "f#4", "a4"}}, "D major"},
+		// i - iv - V - i in harmonic minor
+		{[][]string{{"a3", "c4", "e4"}, {"d4", "f4", "a4"}, {"e4", "g#4", "b4"}, {"a3", "c4", "e4"}}, "A minor"},
+	}
+	for _, test := range tests {
+		key, score, err := DetectKey(render(1, test.chords...), Options{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if key.String() != test.expected || score < 0.5 {
+			t.Fatalf("Expected %v, got %v (correlation %v)", test.expected, key, score)
+		}
+	}
+	if _, _, err := EstimateKey(nil); err == nil {
+		t.Fatalf("Expected an error for an empty chromagram")
+	}
+}
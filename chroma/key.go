@@ -0,0 +1,101 @@
+package chroma
+
+import (
+	"errors"
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Mode is major or minor
+type Mode int
+
+const (
+	// MAJOR mode (or chord)
+	MAJOR Mode = iota
+	// MINOR mode (or chord)
+	MINOR
+)
+
+func (m Mode) String() string {
+	if m == MINOR {
+		return "minor"
+	}
+	return "major"
+}
+
+// Key is a tonic pitch class and a mode
+type Key struct {
+	Tonic int
+	Mode  Mode
+}
+
+// String returns the name of the key, e.g "C major" or "F# minor"
+func (k Key) String() string {
+	return PitchClassName(k.Tonic) + " " + k.Mode.String()
+}
+
+var (
+	// Krumhansl-Kessler key profiles, starting at the tonic
+	majorProfile = []float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = []float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// EstimateKey finds the key whose Krumhansl-Schmuckler profile correlates best with the
+// average of the chromagram. It returns the key and the correlation (-1 .. 1).
+func EstimateKey(chroma [][]float64) (Key, float64, error) {
+	if len(chroma) == 0 {
+		return Key{}, 0, errors.New("Empty chromagram")
+	}
+	avg := make([]float64, 12)
+	for _, row := range chroma {
+		for pc, v := range row {
+			avg[pc] += v
+		}
+	}
+
+	best, bestScore := Key{}, math.Inf(-1)
+	for tonic := 0; tonic < 12; tonic++ {
+		for mode, profile := range [][]float64{majorProfile, minorProfile} {
+			rotated := make([]float64, 12)
+			for i, p := range profile {
+				rotated[(tonic+i)%12] = p
+			}
+			if score := correlation(avg, rotated); score > bestScore {
+				best, bestScore = Key{tonic, Mode(mode)}, score
+			}
+		}
+	}
+	return best, bestScore, nil
+}
+
+// DetectKey estimates the key of a wave
+func DetectKey(w wave.Wave, opts Options) (Key, float64, error) {
+	chroma, err := Chromagram(w, opts)
+	if err != nil {
+		return Key{}, 0, err
+	}
+	return EstimateKey(chroma)
+}
+
+// correlation returns the Pearson correlation of two profiles
+func correlation(a, b []float64) float64 {
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(len(a))
+	meanB /= float64(len(b))
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
@@ -0,0 +1,115 @@
+package main
+
+// tool to align a recording with a reference recording
+// the input is shifted (padded or trimmed at the start) so it lines up with the reference,
+// and then padded or trimmed at the end to match the length of the reference
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/DylanMeeus/GoAudio/au"
+	"github.com/DylanMeeus/GoAudio/audio"
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/w64"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	reference = flag.String("r", "", "reference file")
+	input     = flag.String("i", "", "input file to align with the reference")
+	output    = flag.String("o", "", "output file, .au and .w64 are written as such, anything else as wave")
+	maxLag    = flag.Float64("m", 0, "maximum offset to search in seconds, unlimited if 0")
+)
+
+func main() {
+	flag.Parse()
+	if *reference == "" || *input == "" || *output == "" {
+		panic("Please provide a reference (-r), input (-i) and output (-o) file")
+	}
+
+	ref, _, err := audio.DecodeFile(*reference)
+	if err != nil {
+		panic(err)
+	}
+	in, _, err := audio.DecodeFile(*input)
+	if err != nil {
+		panic(err)
+	}
+
+	lag, err := audiomath.EstimateLag(ref, in, int(*maxLag*float64(ref.SampleRate)))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("input lags %.3f samples (%.6f seconds) behind the reference\n",
+		lag, lag/float64(ref.SampleRate))
+
+	shift := int(math.Round(lag))
+	frames := align(in.Frames, in.NumChannels, shift, len(ref.Frames)/ref.NumChannels)
+	if err := write(frames, in.WaveFmt, *output); err != nil {
+		panic(err)
+	}
+	fmt.Println("done")
+}
+
+// align drops 'shift' samples from the start (or pads with silence when shift is negative),
+// and pads or trims the end so the result has 'length' samples per channel
+func align(frames []wave.Frame, channels, shift, length int) []wave.Frame {
+	out := make([]wave.Frame, length*channels)
+	for i := 0; i < length; i++ {
+		src := i + shift
+		if src < 0 || (src+1)*channels > len(frames) {
+			continue
+		}
+		copy(out[i*channels:(i+1)*channels], frames[src*channels:(src+1)*channels])
+	}
+	return out
+}
+
+// outputs for the file extensions other than wave
+var outputs = map[string]target{
+	".au": {au.WriteAuFile, func(format, bits int) bool {
+		return linear(format, bits) || (format == au.FormatMuLaw || format == au.FormatALaw) && bits == 8
+	}},
+	".w64": {w64.WriteW64File, linear},
+}
+
+// target is a file format, which can store the samples for which stores returns true
+type target struct {
+	write  func([]wave.Frame, wave.WaveFmt, string) error
+	stores func(format, bits int) bool
+}
+
+// linear reports whether the samples are 8 to 32-bit PCM or 32 or 64-bit float, which
+// .au and .w64 both store
+func linear(format, bits int) bool {
+	switch format {
+	case 1:
+		return bits == 8 || bits == 16 || bits == 24 || bits == 32
+	case 3:
+		return bits == 32 || bits == 64
+	}
+	return false
+}
+
+// write keeps the format of the input when the output format can store it, and warns when
+// it has to fall back to PCM
+func write(frames []wave.Frame, wfmt wave.WaveFmt, file string) error {
+	out, ok := outputs[strings.ToLower(filepath.Ext(file))]
+	if !ok {
+		// wave files are written as PCM only
+		out = target{wave.WriteWaveFile, func(format, bits int) bool {
+			return format == 1 && linear(format, bits)
+		}}
+	}
+	if !out.stores(wfmt.AudioFormat, wfmt.BitsPerSample) {
+		pcm, _ := wave.PCMFormat(wfmt)
+		fmt.Printf("warning: can't write audio format %v (%v bits) to %v, writing %v-bit PCM\n",
+			wfmt.AudioFormat, wfmt.BitsPerSample, file, pcm.BitsPerSample)
+		wfmt = pcm
+	}
+	return out.write(frames, wfmt, file)
+}
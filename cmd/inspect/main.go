@@ -3,20 +3,49 @@ package main
 // tool to print the interpreted the content of a .wave file
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 
+	"github.com/DylanMeeus/GoAudio/audio"
+	"github.com/DylanMeeus/GoAudio/loudness"
+	"github.com/DylanMeeus/GoAudio/stats"
 	"github.com/DylanMeeus/GoAudio/wave"
-	wav "github.com/DylanMeeus/GoAudio/wave"
-	"strings"
+
+	// register the formats we can inspect besides wave
+	_ "github.com/DylanMeeus/GoAudio/au"
+	_ "github.com/DylanMeeus/GoAudio/w64"
 )
 
 var (
 	input       = flag.String("i", "", "input file")
-	withSamples = flag.Bool("s", false, "with raw audio samples")
+	withSamples = flag.Bool("s", false, "with the decoded audio samples, a column (JSON: an array) per channel")
+	asJSON      = flag.Bool("json", false, "print the report as JSON")
 )
 
+// report is everything we print with -json
+type report struct {
+	File          string         `json:"file"`
+	Format        string         `json:"format"`
+	AudioFormat   int            `json:"audio_format"`
+	Channels      int            `json:"channels"`
+	SampleRate    int            `json:"sample_rate"`
+	BitsPerSample int            `json:"bits_per_sample"`
+	Duration      float64        `json:"duration"`
+	Loudness      *loudnessStats `json:"loudness,omitempty"`
+	Stats         stats.Stats    `json:"stats"`
+	Samples       [][]wave.Frame `json:"samples,omitempty"` // per channel, with -s
+}
+
+type loudnessStats struct {
+	Integrated    stats.DB   `json:"integrated_lufs"`
+	LoudnessRange float64    `json:"loudness_range_lu"`
+	MaxMomentary  stats.DB   `json:"max_momentary_lufs"`
+	MaxShortTerm  stats.DB   `json:"max_short_term_lufs"`
+	TruePeak      []stats.DB `json:"true_peak_dbtp"`
+}
+
 // printHeader
 func printHeader(h wave.WaveHeader) {
 	fmt.Println("Header")
@@ -42,49 +71,142 @@ func printFormat(f wave.WaveFmt) {
 func printDerivedData(w wave.Wave) {
 	bps := w.BitsPerSample * w.SampleRate
 	fmt.Printf("Bits / second: %v\n", bps)
+	fmt.Printf("Duration: %.3f s\n", duration(w))
+}
 
-	// duration = number of samples / samplerate
-	duration := (len(w.Frames) / w.NumChannels) / w.SampleRate
-	fmt.Printf("Duration: %v\n", duration)
+// duration = number of samples / samplerate
+func duration(w wave.Wave) float64 {
+	if w.NumChannels == 0 || w.SampleRate == 0 {
+		return 0
+	}
+	return float64(len(w.Frames)/w.NumChannels) / float64(w.SampleRate)
+}
 
+// print the EBU R128 loudness measurements
+func printLoudness(w wave.Wave) {
+	r, err := loudness.Measure(w)
+	if err != nil {
+		fmt.Printf("Could not measure loudness: %v\n", err)
+		return
+	}
+	fmt.Println("Loudness")
+	fmt.Printf("Integrated: %.1f LUFS\n", r.Integrated)
+	fmt.Printf("Loudness range: %.1f LU\n", r.LoudnessRange)
+	fmt.Printf("Max momentary: %.1f LUFS\n", r.MaxMomentary)
+	fmt.Printf("Max short-term: %.1f LUFS\n", r.MaxShortTerm)
+	fmt.Printf("True peak: %.1f dBTP %.1f\n", r.MaxTruePeak(), r.TruePeak)
+	fmt.Printf("Sample peak: %.1f dBFS %.1f\n", r.MaxSamplePeak(), r.SamplePeak)
 }
 
-// validFile makes sure we can inspect this file based on the extension
-func validFile(file string) bool {
-	parts := strings.Split(strings.ToLower(file), ".")
-	if len(parts) == 0 {
-		return false
+// print the signal statistics of every channel
+func printStats(s stats.Stats) {
+	fmt.Println("Statistics")
+	for i, c := range s.Channels {
+		fmt.Printf("Channel %v\n", i)
+		fmt.Printf("  Peak: %.2f dBFS\n", c.Peak)
+		fmt.Printf("  RMS: %.2f dBFS\n", c.RMS)
+		fmt.Printf("  Crest factor: %.2f dB\n", c.CrestFactor)
+		fmt.Printf("  DC offset: %.6f\n", c.DCOffset)
+		fmt.Printf("  Clipped samples: %v\n", c.Clipped)
+		fmt.Printf("  Silence: %.1f%%\n", c.Silence)
+		fmt.Printf("  Zero crossings: %.1f / s\n", c.ZeroCrossings)
+	}
+	if s.Correlation != nil {
+		fmt.Printf("Stereo correlation: %.3f\n", *s.Correlation)
+	}
+}
+
+// print the decoded samples, a line per sample with a column per channel
+func printSamples(w wave.Wave) {
+	fmt.Println("Samples")
+	channels := wave.SplitChannels(w.Frames, w.NumChannels)
+	if len(channels) == 0 {
+		return
+	}
+	for i := range channels[0] {
+		for c := range channels {
+			if c > 0 {
+				fmt.Print("\t")
+			}
+			fmt.Printf("%v", channels[c][i])
+		}
+		fmt.Println()
 	}
+}
 
-	ext := parts[len(parts)-1]
-	return ext == "wav" || ext == "wave"
+func printJSON(file, format string, w wave.Wave, s stats.Stats, withSamples bool) {
+	rep := report{
+		File:          file,
+		Format:        format,
+		AudioFormat:   w.AudioFormat,
+		Channels:      w.NumChannels,
+		SampleRate:    w.SampleRate,
+		BitsPerSample: w.BitsPerSample,
+		Duration:      duration(w),
+		Stats:         s,
+	}
+	if withSamples {
+		rep.Samples = wave.SplitChannels(w.Frames, w.NumChannels)
+	}
+	if r, err := loudness.Measure(w); err == nil {
+		rep.Loudness = &loudnessStats{
+			Integrated:    stats.DB(r.Integrated),
+			LoudnessRange: r.LoudnessRange,
+			MaxMomentary:  stats.DB(r.MaxMomentary),
+			MaxShortTerm:  stats.DB(r.MaxShortTerm),
+		}
+		for _, tp := range r.TruePeak {
+			rep.Loudness.TruePeak = append(rep.Loudness.TruePeak, stats.DB(tp))
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		panic(err)
+	}
 }
 
 func main() {
 	flag.Parse()
-	as := os.Args[1:]
-	if len(as) == 0 {
+	infile := *input
+	if infile == "" {
+		infile = flag.Arg(0)
+	}
+	if infile == "" {
 		panic("Please provide file")
 	}
 
-	infile := as[0]
-	if !validFile(infile) {
-		panic("Please provide valid file (.wav or .wave supported)")
+	w, format, err := audio.DecodeFile(infile)
+	if err == audio.ErrFormat {
+		panic(fmt.Sprintf("Please provide a valid file (supported: %v)", audio.Formats()))
 	}
-	ws := *withSamples
-	wave, err := wav.ReadWaveFile(infile)
 	if err != nil {
 		panic(err)
 	}
+	s, err := stats.Analyze(w, stats.Options{})
+	if err != nil {
+		panic(err)
+	}
+
+	if *asJSON {
+		printJSON(infile, format, w, s, *withSamples)
+		return
+	}
 
-	printHeader(wave.WaveHeader)
+	fmt.Printf("File format: %v\n", format)
+	fmt.Println("===============")
+	printHeader(w.WaveHeader)
+	fmt.Println("===============")
+	printFormat(w.WaveFmt)
+	fmt.Println("===============")
+	printDerivedData(w)
 	fmt.Println("===============")
-	printFormat(wave.WaveFmt)
+	printLoudness(w)
 	fmt.Println("===============")
-	printDerivedData(wave)
+	printStats(s)
 
-	if ws {
-		fmt.Printf("===============\nSamples:\n")
-		fmt.Printf("%v\n", wave.RawData)
+	if *withSamples {
+		fmt.Println("===============")
+		printSamples(w)
 	}
 }
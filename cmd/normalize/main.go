@@ -0,0 +1,120 @@
+package main
+
+// tool to normalize wave files to a loudness or peak level.
+//
+// normalize -m lufs -t -16 -o out.wav in.wav
+// normalize -m peak -t -1 -d normalized/ a.wav b.wav c.wav
+// normalize -album -limit -c -1 -d normalized/ track1.wav track2.wav
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/DylanMeeus/GoAudio/normalize"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	mode      = flag.String("m", "lufs", "normalization mode (lufs, rms, peak, truepeak)")
+	target    = flag.Float64("t", -23, "target level in LUFS, dBFS or dBTP depending on the mode")
+	output    = flag.String("o", "", "output file, for a single input")
+	outDir    = flag.String("d", "", "output directory, for several inputs")
+	album     = flag.Bool("album", false, "apply one gain to all inputs")
+	limit     = flag.Bool("limit", false, "limit the peaks to the ceiling")
+	ceiling   = flag.Float64("c", -1, "ceiling of the limiter in dBFS")
+	lookAhead = flag.Float64("la", 0.005, "look-ahead of the limiter in seconds")
+	release   = flag.Float64("r", 0.05, "release of the limiter in seconds")
+)
+
+func main() {
+	flag.Parse()
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		panic("Please provide one or more input files")
+	}
+	if *output == "" && *outDir == "" {
+		panic("Please provide an output file (-o) or directory (-d)")
+	}
+	if *output != "" && len(inputs) > 1 {
+		panic("Use an output directory (-d) for several input files")
+	}
+	m, ok := normalize.Modes[*mode]
+	if !ok {
+		panic(fmt.Sprintf("Unknown mode %v", *mode))
+	}
+
+	opts := normalize.Options{Mode: m, Target: *target}
+	if *limit {
+		opts.Limiter = &normalize.LimiterOptions{
+			Ceiling:   *ceiling,
+			LookAhead: *lookAhead,
+			Release:   *release,
+		}
+	}
+
+	waves := make([]wave.Wave, len(inputs))
+	for i, in := range inputs {
+		w, err := wave.ReadWaveFile(in)
+		if err != nil {
+			panic(err)
+		}
+		waves[i] = w
+	}
+
+	if *album {
+		out, gain, err := normalize.NormalizeAlbum(waves, opts)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("album gain: %+.2f dB\n", gain)
+		for i := range out {
+			write(out[i], inputs[i])
+		}
+		return
+	}
+
+	for i, w := range waves {
+		out, gain, err := normalize.Normalize(w, opts)
+		if err != nil {
+			panic(fmt.Sprintf("%v: %v", inputs[i], err))
+		}
+		fmt.Printf("%v: %+.2f dB\n", inputs[i], gain)
+		write(out, inputs[i])
+	}
+}
+
+// write the wave next to the others in the output directory, or to the output file
+func write(w wave.Wave, input string) {
+	file := *output
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0755); err != nil {
+			panic(err)
+		}
+		file = filepath.Join(*outDir, filepath.Base(input))
+	}
+
+	// anything over full scale would wrap around when written as PCM
+	clipped := 0
+	for i, f := range w.Frames {
+		if math.Abs(float64(f)) > 1 {
+			w.Frames[i] = wave.Frame(math.Copysign(1, float64(f)))
+			clipped++
+		}
+	}
+	if clipped > 0 {
+		fmt.Printf("%v: clipped %v samples, consider -limit\n", file, clipped)
+	}
+
+	// wave files are written as PCM only
+	wfmt, changed := wave.PCMFormat(w.WaveFmt)
+	if changed {
+		fmt.Printf("%v: can't write audio format %v (%v bits), writing %v-bit PCM\n",
+			file, w.AudioFormat, w.BitsPerSample, wfmt.BitsPerSample)
+	}
+	if err := wave.WriteWaveFile(w.Frames, wfmt, file); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+// program to extract the pitch of a (monophonic) recording as breakpoint data.
+// Every line of the output is time:frequency, unvoiced frames get a frequency of 0.
+// The result can be read with breakpoint.ParseBreakpoints, e.g. to drive an oscillator.
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/DylanMeeus/GoAudio/audio"
+	"github.com/DylanMeeus/GoAudio/pitch"
+	synth "github.com/DylanMeeus/GoAudio/synthesizer"
+
+	// register the formats we can analyse besides wave
+	_ "github.com/DylanMeeus/GoAudio/au"
+	_ "github.com/DylanMeeus/GoAudio/w64"
+)
+
+var (
+	input     = flag.String("i", "", "input file")
+	output    = flag.String("o", "", "output file (breakpoints)")
+	method    = flag.String("m", "yin", "detection method (yin, mpm)")
+	minFreq   = flag.Float64("min", 50, "lowest frequency to detect")
+	maxFreq   = flag.Float64("max", 2000, "highest frequency to detect")
+	frameSize = flag.Int("frame", 2048, "analysis frame size in samples")
+	hopSize   = flag.Int("hop", 512, "samples between analysis frames")
+	minConf   = flag.Float64("c", 0.5, "minimum confidence for a frame to be voiced")
+	notes     = flag.Bool("notes", false, "print the detected notes")
+)
+
+func main() {
+	flag.Parse()
+	if *input == "" || *output == "" {
+		panic("Please provide an input (-i) and output (-o) file")
+	}
+	m, ok := pitch.Methods[*method]
+	if !ok {
+		panic(fmt.Sprintf("Unknown method %v", *method))
+	}
+
+	wave, _, err := audio.DecodeFile(*input)
+	if err != nil {
+		panic(err)
+	}
+	track, err := pitch.Track(wave, pitch.Options{
+		Method:        m,
+		FrameSize:     *frameSize,
+		HopSize:       *hopSize,
+		MinFreq:       *minFreq,
+		MaxFreq:       *maxFreq,
+		MinConfidence: *minConf,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	strout := strings.Builder{}
+	for _, e := range track {
+		freq := 0.0
+		if e.Voiced {
+			freq = e.Frequency
+		}
+		ts := strconv.FormatFloat(e.Time, 'f', 8, 64)
+		fs := strconv.FormatFloat(freq, 'f', 8, 64)
+		strout.WriteString(ts + ":" + fs + "\n")
+
+		if *notes && e.Voiced {
+			note, octave, cents := synth.FrequencyToNote(e.Frequency)
+			fmt.Printf("%.3fs\t%.2f Hz\t%v%v %+.0f cents\n", e.Time, e.Frequency, note, octave, cents)
+		}
+	}
+
+	err = ioutil.WriteFile(*output, []byte(strout.String()), 0644)
+	if err != nil {
+		panic(err)
+	}
+}
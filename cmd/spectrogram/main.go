@@ -0,0 +1,103 @@
+package main
+
+// tool to render the spectrogram of an audio file to PNG
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/DylanMeeus/GoAudio/audio"
+	"github.com/DylanMeeus/GoAudio/spectrogram"
+
+	// register the formats we can render besides wave
+	_ "github.com/DylanMeeus/GoAudio/au"
+	_ "github.com/DylanMeeus/GoAudio/w64"
+)
+
+var (
+	input   = flag.String("i", "", "input file")
+	output  = flag.String("o", "spectrogram.png", "output file, channels > 1 get a -<channel> suffix")
+	scale   = flag.String("scale", "linear", "frequency axis: linear, log or mel")
+	cmap    = flag.String("cmap", "viridis", "colormap: gray, viridis, magma, inferno or jet")
+	floor   = flag.Float64("floor", -100, "level in dBFS shown as the bottom of the colormap")
+	frame   = flag.Int("frame", 2048, "samples per STFT frame")
+	hop     = flag.Int("hop", 512, "samples between frames, one pixel column each")
+	height  = flag.Int("height", 512, "image height in pixels")
+	minFreq = flag.Float64("min", 0, "lowest frequency shown (Hz)")
+	maxFreq = flag.Float64("max", 0, "highest frequency shown (Hz), Nyquist if 0")
+	channel = flag.Int("c", -1, "only render this channel (0 based), all channels if < 0")
+)
+
+func main() {
+	flag.Parse()
+	if *input == "" {
+		panic("Please provide an input file with -i")
+	}
+
+	sc, ok := spectrogram.Scales[*scale]
+	if !ok {
+		panic(fmt.Sprintf("Unknown scale %q", *scale))
+	}
+	cm, ok := spectrogram.Colormaps[*cmap]
+	if !ok {
+		panic(fmt.Sprintf("Unknown colormap %q", *cmap))
+	}
+
+	w, _, err := audio.DecodeFile(*input)
+	if err != nil {
+		panic(err)
+	}
+	if *channel >= w.NumChannels {
+		panic(fmt.Sprintf("Channel %v out of range, the file has %v channels", *channel, w.NumChannels))
+	}
+
+	imgs, err := spectrogram.Render(w, spectrogram.Options{
+		FrameSize: *frame,
+		HopSize:   *hop,
+		Scale:     sc,
+		MinFreq:   *minFreq,
+		MaxFreq:   *maxFreq,
+		Floor:     *floor,
+		Height:    *height,
+		Colormap:  cm,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for c, img := range imgs {
+		if *channel >= 0 && c != *channel {
+			continue
+		}
+		name := *output
+		if len(imgs) > 1 && *channel < 0 {
+			name = channelFile(*output, c)
+		}
+		if err := writePNG(name, img); err != nil {
+			panic(err)
+		}
+		fmt.Printf("wrote %v\n", name)
+	}
+}
+
+// channelFile adds the channel number to the file name, before the extension
+func channelFile(file string, c int) string {
+	ext := ""
+	if i := strings.LastIndex(file, "."); i >= 0 {
+		file, ext = file[:i], file[i:]
+	}
+	return fmt.Sprintf("%v-%v%v", file, c, ext)
+}
+
+func writePNG(file string, img image.Image) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
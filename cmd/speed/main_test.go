@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/au"
+	"github.com/DylanMeeus/GoAudio/w64"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// TestFormats runs inputs that can't be stored in a PCM wave file through speed, the
+// output should be PCM that holds the same signal
+func TestFormats(t *testing.T) {
+	const sr = 16000
+	in := make([]wave.Frame, 2*sr)
+	for i := range in {
+		in[i] = wave.Frame(0.5 * math.Sin(2*math.Pi*440*float64(i)/sr))
+	}
+	dir := t.TempDir()
+	inputs := map[string]func() error{
+		"float.w64": func() error {
+			return w64.WriteW64File(in, wave.NewWaveFmt(w64.FormatFloat, 1, sr, 64, nil), filepath.Join(dir, "float.w64"))
+		},
+		"mulaw.au": func() error {
+			return au.WriteAuFile(in, wave.NewWaveFmt(au.FormatMuLaw, 1, sr, 8, nil), filepath.Join(dir, "mulaw.au"))
+		},
+	}
+	for name, create := range inputs {
+		if err := create(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+		output := filepath.Join(dir, name+".wav")
+		if err := run(filepath.Join(dir, name), output); err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+
+		w, err := wave.ReadWaveFile(output)
+		if err != nil {
+			t.Fatalf("%v: can't read the output: %v", name, err)
+		}
+		if w.AudioFormat != 1 || w.BitsPerSample != 16 {
+			t.Fatalf("%v: expected 16-bit PCM, got format %v with %v bits", name, w.AudioFormat, w.BitsPerSample)
+		}
+		expected := float64(len(in)) / *speed
+		if math.Abs(float64(len(w.Frames))-expected) > 0.05*expected {
+			t.Fatalf("%v: expected about %v samples, got %v", name, expected, len(w.Frames))
+		}
+		peak := 0.0
+		for _, f := range w.Frames {
+			peak = math.Max(peak, math.Abs(float64(f)))
+		}
+		if math.Abs(peak-0.5) > 0.05 {
+			t.Fatalf("%v: expected a peak of 0.5, got %v", name, peak)
+		}
+	}
+}
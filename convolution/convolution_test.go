@@ -0,0 +1,158 @@
+package convolution
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+func randomFrames(n int, seed int64) []wave.Frame {
+	rng := rand.New(rand.NewSource(seed))
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(rng.Float64()*2 - 1)
+	}
+	return out
+}
+
+// directConvolve is the textbook O(N*M) convolution
+func directConvolve(x, h []wave.Frame) []wave.Frame {
+	out := make([]wave.Frame, len(x)+len(h)-1)
+	for i := range x {
+		for j := range h {
+			out[i+j] += x[i] * h[j]
+		}
+	}
+	return out
+}
+
+func assertFramesClose(t *testing.T, expected, got []wave.Frame) {
+	t.Helper()
+	if len(expected) != len(got) {
+		t.Fatalf("Expected %v samples, got %v", len(expected), len(got))
+	}
+	for i := range expected {
+		if math.Abs(float64(expected[i]-got[i])) > 1e-9 {
+			t.Fatalf("Sample %v: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestConvolve(t *testing.T) {
+	sizes := []struct{ signal, ir int }{
+		{1, 1}, {10, 3}, {3, 10}, {1000, 37}, {5000, 600}, {257, 256},
+	}
+	for _, size := range sizes {
+		t.Run("", func(t *testing.T) {
+			x := randomFrames(size.signal, 1)
+			h := randomFrames(size.ir, 2)
+			assertFramesClose(t, directConvolve(x, h), Convolve(x, h))
+		})
+	}
+}
+
+// TestConvolver makes sure streaming in odd sized blocks matches the offline convolution
+func TestConvolver(t *testing.T) {
+	x := randomFrames(3000, 3)
+	h := randomFrames(700, 4)
+	conv, err := NewConvolver(h, 64)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	input := append(append([]wave.Frame{}, x...), make([]wave.Frame, len(h)-1+conv.Latency())...)
+	out := []wave.Frame{}
+	for start := 0; start < len(input); start += 100 {
+		end := start + 100
+		if end > len(input) {
+			end = len(input)
+		}
+		out = append(out, conv.Process(input[start:end])...)
+	}
+	assertFramesClose(t, directConvolve(x, h), out[conv.Latency():])
+}
+
+func TestInvalidConvolver(t *testing.T) {
+	if _, err := NewConvolver(randomFrames(10, 1), 100); err == nil {
+		t.Fatal("Expected an error for a block size that isn't a power of two")
+	}
+	if _, err := NewConvolver(nil, 64); err == nil {
+		t.Fatal("Expected an error for an empty impulse response")
+	}
+}
+
+func stereo(left, right []wave.Frame) wave.Wave {
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, 1000, 16, nil),
+		WaveData: wave.WaveData{Frames: wave.JoinChannels([][]wave.Frame{left, right})},
+	}
+}
+
+func TestReverbMix(t *testing.T) {
+	left, right := randomFrames(500, 5), randomFrames(500, 6)
+	ir := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 1, 1000, 16, nil),
+		WaveData: wave.WaveData{Frames: randomFrames(50, 7)},
+	}
+	// 10ms at 1kHz is a pre-delay of 10 samples
+	opts := ReverbOptions{Wet: 0.25, Dry: 0.5, PreDelay: 0.01, BlockSize: 32}
+	out, err := ApplyReverb(stereo(left, right), ir, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	delayed := append(make([]wave.Frame, 10), ir.Frames...)
+	for c, in := range [][]wave.Frame{left, right} {
+		wet := directConvolve(in, delayed)
+		expected := make([]wave.Frame, len(wet))
+		for i := range wet {
+			expected[i] = 0.25 * wet[i]
+			if i < len(in) {
+				expected[i] += 0.5 * in[i]
+			}
+		}
+		assertFramesClose(t, expected, wave.SplitChannels(out.Frames, 2)[c])
+	}
+}
+
+func TestTrueStereoReverb(t *testing.T) {
+	left, right := randomFrames(300, 8), randomFrames(300, 9)
+	ll, lr, rl, rr := randomFrames(40, 10), randomFrames(40, 11), randomFrames(40, 12), randomFrames(40, 13)
+	ir := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 4, 1000, 16, nil),
+		WaveData: wave.WaveData{Frames: wave.JoinChannels([][]wave.Frame{ll, lr, rl, rr})},
+	}
+	// the zero value is fully wet
+	out, err := ApplyReverb(stereo(left, right), ir, ReverbOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	outs := wave.SplitChannels(out.Frames, 2)
+	expectedLeft := directConvolve(left, ll)
+	expectedRight := directConvolve(left, lr)
+	fromRight := [][]wave.Frame{directConvolve(right, rl), directConvolve(right, rr)}
+	for i := range expectedLeft {
+		expectedLeft[i] += fromRight[0][i]
+		expectedRight[i] += fromRight[1][i]
+	}
+	assertFramesClose(t, expectedLeft, outs[0])
+	assertFramesClose(t, expectedRight, outs[1])
+}
+
+func TestReverbInvalidRouting(t *testing.T) {
+	ir := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 3, 1000, 16, nil),
+		WaveData: wave.WaveData{Frames: randomFrames(30, 1)},
+	}
+	if _, err := NewReverb(ir, 2, 1000, ReverbOptions{}); err == nil {
+		t.Fatal("Expected an error for a 3 channel impulse response on stereo")
+	}
+
+	ir.NumChannels = 0
+	if _, err := NewReverb(ir, 2, 1000, ReverbOptions{}); err == nil {
+		t.Fatal("Expected an error for an impulse response without channels")
+	}
+}
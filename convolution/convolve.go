@@ -0,0 +1,60 @@
+// Package convolution applies impulse responses to audio using FFT based convolution,
+// either offline on whole signals or block by block on a stream.
+package convolution
+
+import (
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Convolve returns the full convolution of the signal with the impulse response, which
+// is len(signal)+len(ir)-1 samples long. Long signals are processed with overlap-add.
+func Convolve(signal, ir []wave.Frame) []wave.Frame {
+	if len(signal) == 0 || len(ir) == 0 {
+		return []wave.Frame{}
+	}
+	m := len(ir)
+	n := audiomath.NextPowerOfTwo(2 * m)
+	if full := audiomath.NextPowerOfTwo(len(signal) + m - 1); full < n {
+		n = full
+	}
+	// every block of the signal fits in the FFT together with its tail
+	block := n - m + 1
+
+	h := spectrum(ir, n)
+	out := make([]wave.Frame, len(signal)+m-1)
+	for start := 0; start < len(signal); start += block {
+		end := start + block
+		if end > len(signal) {
+			end = len(signal)
+		}
+		x := spectrum(signal[start:end], n)
+		for k := range x {
+			x[k] *= h[k]
+		}
+		y := audiomath.IFFT(x)
+		for i := 0; i < n && start+i < len(out); i++ {
+			out[start+i] += wave.Frame(real(y[i]))
+		}
+	}
+	return out
+}
+
+// ConvolveChannels convolves every channel of the interleaved frames with the same
+// (mono) impulse response.
+func ConvolveChannels(frames []wave.Frame, channels int, ir []wave.Frame) []wave.Frame {
+	split := wave.SplitChannels(frames, channels)
+	for c := range split {
+		split[c] = Convolve(split[c], ir)
+	}
+	return wave.JoinChannels(split)
+}
+
+// spectrum returns the FFT of the frames, zero padded to n samples
+func spectrum(frames []wave.Frame, n int) []complex128 {
+	buf := make([]complex128, n)
+	for i, f := range frames {
+		buf[i] = complex(float64(f), 0)
+	}
+	return audiomath.ComplexFFT(buf)
+}
@@ -0,0 +1,106 @@
+package convolution
+
+import (
+	"errors"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Convolver convolves a stream of mono audio with an impulse response.
+// The impulse response is cut into partitions of the block size, which are applied in the
+// frequency domain (uniformly partitioned overlap-save), so long impulse responses only
+// cost a block of latency.
+type Convolver struct {
+	block      int
+	partitions [][]complex128 // spectra of the impulse response partitions
+	history    [][]complex128 // spectra of the most recent input blocks, newest first
+	input      []float64      // previous and current input block
+	filled     int            // samples in the current input block
+	queue      []wave.Frame   // output waiting to be returned
+}
+
+// NewConvolver creates a streaming convolver for the impulse response.
+// The block size has to be a power of two, it is also the latency in samples.
+func NewConvolver(ir []wave.Frame, blockSize int) (*Convolver, error) {
+	if blockSize <= 0 || blockSize&(blockSize-1) != 0 {
+		return nil, errors.New("Block size should be a power of two")
+	}
+	if len(ir) == 0 {
+		return nil, errors.New("Impulse response can't be empty")
+	}
+
+	c := &Convolver{block: blockSize}
+	for start := 0; start < len(ir); start += blockSize {
+		end := start + blockSize
+		if end > len(ir) {
+			end = len(ir)
+		}
+		c.partitions = append(c.partitions, spectrum(ir[start:end], 2*blockSize))
+	}
+	c.Reset()
+	return c, nil
+}
+
+// Latency returns the delay of the output in samples
+func (c *Convolver) Latency() int {
+	return c.block
+}
+
+// Reset clears the state, as if no audio was processed yet
+func (c *Convolver) Reset() {
+	c.history = make([][]complex128, len(c.partitions))
+	for i := range c.history {
+		c.history[i] = make([]complex128, 2*c.block)
+	}
+	c.input = make([]float64, 2*c.block)
+	c.filled = 0
+	c.queue = make([]wave.Frame, c.block)
+}
+
+// Process consumes a block of any size and returns as many samples of output,
+// delayed by Latency() samples.
+func (c *Convolver) Process(in []wave.Frame) []wave.Frame {
+	for _, f := range in {
+		c.input[c.block+c.filled] = float64(f)
+		c.filled++
+		if c.filled == c.block {
+			c.processBlock()
+		}
+	}
+	out := make([]wave.Frame, len(in))
+	copy(out, c.queue)
+	c.queue = append([]wave.Frame{}, c.queue[len(in):]...)
+	return out
+}
+
+// processBlock convolves a complete input block and queues the output
+func (c *Convolver) processBlock() {
+	n := 2 * c.block
+	buf := make([]complex128, n)
+	for i, x := range c.input {
+		buf[i] = complex(x, 0)
+	}
+
+	// shift the frequency domain delay line
+	last := c.history[len(c.history)-1]
+	copy(c.history[1:], c.history[:len(c.history)-1])
+	c.history[0] = last
+	copy(c.history[0], audiomath.ComplexFFT(buf))
+
+	acc := make([]complex128, n)
+	for p, h := range c.partitions {
+		x := c.history[p]
+		for k := range acc {
+			acc[k] += x[k] * h[k]
+		}
+	}
+	y := audiomath.IFFT(acc)
+	// the first half is corrupted by circular wrap-around, the second half is valid
+	for i := c.block; i < n; i++ {
+		c.queue = append(c.queue, wave.Frame(real(y[i])))
+	}
+
+	copy(c.input, c.input[c.block:])
+	c.filled = 0
+}
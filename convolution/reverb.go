@@ -0,0 +1,171 @@
+package convolution
+
+import (
+	"fmt"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// ReverbOptions configure a convolution reverb.
+// When Wet and Dry are both 0 (the zero value) the output is fully wet, Wet 1 and Dry 0.
+type ReverbOptions struct {
+	Wet       float64 // gain of the reverberated signal
+	Dry       float64 // gain of the original signal
+	PreDelay  float64 // seconds of silence before the impulse response starts
+	BlockSize int     // block size (and latency) of the convolution, 256 if 0
+}
+
+// Reverb applies an impulse response recorded in a room (or cabinet, plate, ..) to a
+// stream of interleaved audio.
+//
+// The impulse response can be mono (applied to every channel), have one channel per
+// input channel, or, for stereo input, be a true stereo response with four channels:
+// left to left, left to right, right to left and right to right.
+type Reverb struct {
+	channels int
+	opts     ReverbOptions
+	// paths[out] holds a convolver per input channel feeding that output, nil if unused
+	paths [][]*Convolver
+	dry   [][]wave.Frame // dry signal per channel, delayed to line up with the wet signal
+}
+
+// NewReverb creates a reverb for audio with the given number of channels and sample rate.
+func NewReverb(ir wave.Wave, channels, sr int, opts ReverbOptions) (*Reverb, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("Invalid number of channels %v", channels)
+	}
+	if ir.NumChannels <= 0 {
+		return nil, fmt.Errorf("Invalid number of channels %v in the impulse response", ir.NumChannels)
+	}
+	if opts.BlockSize == 0 {
+		opts.BlockSize = 256
+	}
+	if opts.Wet == 0 && opts.Dry == 0 {
+		opts.Wet = 1
+	}
+	if opts.PreDelay < 0 {
+		return nil, fmt.Errorf("Pre-delay can't be negative")
+	}
+
+	irs := wave.SplitChannels(ir.Frames, ir.NumChannels)
+	delay := make([]wave.Frame, int(opts.PreDelay*float64(sr)+0.5))
+	for i := range irs {
+		irs[i] = append(append([]wave.Frame{}, delay...), irs[i]...)
+	}
+
+	// routing[out][in] is the impulse response channel from input to output, or -1
+	routing := make([][]int, channels)
+	for out := range routing {
+		routing[out] = make([]int, channels)
+		for in := range routing[out] {
+			routing[out][in] = -1
+		}
+	}
+	switch {
+	case len(irs) == 1:
+		for c := 0; c < channels; c++ {
+			routing[c][c] = 0
+		}
+	case len(irs) == channels:
+		for c := 0; c < channels; c++ {
+			routing[c][c] = c
+		}
+	case len(irs) == 4 && channels == 2:
+		// true stereo: LL, LR, RL, RR
+		routing[0][0], routing[1][0] = 0, 1
+		routing[0][1], routing[1][1] = 2, 3
+	default:
+		return nil, fmt.Errorf("Can't apply an impulse response with %v channels to %v channels",
+			len(irs), channels)
+	}
+
+	r := &Reverb{
+		channels: channels,
+		opts:     opts,
+		paths:    make([][]*Convolver, channels),
+		dry:      make([][]wave.Frame, channels),
+	}
+	for out := range routing {
+		r.paths[out] = make([]*Convolver, channels)
+		for in, ch := range routing[out] {
+			if ch < 0 {
+				continue
+			}
+			conv, err := NewConvolver(irs[ch], opts.BlockSize)
+			if err != nil {
+				return nil, err
+			}
+			r.paths[out][in] = conv
+		}
+	}
+	r.Reset()
+	return r, nil
+}
+
+// Latency returns the delay of the output in samples per channel
+func (r *Reverb) Latency() int {
+	return r.opts.BlockSize
+}
+
+// Reset clears the state of the reverb, as if no audio was processed yet
+func (r *Reverb) Reset() {
+	for c := range r.dry {
+		r.dry[c] = make([]wave.Frame, r.opts.BlockSize)
+	}
+	for _, convs := range r.paths {
+		for _, conv := range convs {
+			if conv != nil {
+				conv.Reset()
+			}
+		}
+	}
+}
+
+// Process consumes a block of interleaved frames and returns the same number of frames,
+// delayed by Latency() samples per channel.
+func (r *Reverb) Process(in []wave.Frame) []wave.Frame {
+	split := wave.SplitChannels(in, r.channels)
+	n := len(split[0])
+
+	outs := make([][]wave.Frame, r.channels)
+	for out := range outs {
+		wet := make([]wave.Frame, n)
+		for c, conv := range r.paths[out] {
+			if conv == nil {
+				continue
+			}
+			for i, f := range conv.Process(split[c]) {
+				wet[i] += f
+			}
+		}
+
+		r.dry[out] = append(r.dry[out], split[out]...)
+		dry := r.dry[out][:n]
+		outs[out] = make([]wave.Frame, n)
+		for i := range wet {
+			outs[out][i] = wet[i]*wave.Frame(r.opts.Wet) + dry[i]*wave.Frame(r.opts.Dry)
+		}
+		r.dry[out] = append([]wave.Frame{}, r.dry[out][n:]...)
+	}
+	return wave.JoinChannels(outs)
+}
+
+// ApplyReverb runs the whole wave through a reverb, including the tail of the impulse
+// response. The output is compensated for the latency of the reverb.
+func ApplyReverb(w wave.Wave, ir wave.Wave, opts ReverbOptions) (wave.Wave, error) {
+	r, err := NewReverb(ir, w.NumChannels, w.SampleRate, opts)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+	irLength := len(ir.Frames)/ir.NumChannels + int(opts.PreDelay*float64(w.SampleRate)+0.5)
+	tail := make([]wave.Frame, (irLength-1+r.Latency())*w.NumChannels)
+
+	out := r.Process(w.Frames)
+	out = append(out, r.Process(tail)...)
+	out = out[r.Latency()*w.NumChannels:]
+
+	return wave.Wave{
+		WaveFmt:  w.WaveFmt,
+		WaveData: wave.WaveData{Frames: out},
+	}, nil
+}
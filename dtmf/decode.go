@@ -0,0 +1,244 @@
+package dtmf
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const (
+	// blockDuration is the length of the analysis blocks, the classic 205 samples at 8kHz
+	blockDuration = 205. / 8000
+	// a tone that is off by more than this fraction is closer to the probe next to it
+	// than to its own frequency. Q.24 asks to accept 1.5% and reject 3.5%.
+	probeOffset = 0.035
+	// the strongest tone of a group should be twice as strong as the others (6 dB)
+	relativePeak = 2
+	// the two tones should hold at least a third of the energy, which rejects speech.
+	// It's not more as tones that are off frequency read weaker.
+	minToneEnergy = 0.3
+)
+
+// DecodeOptions configure the decoder.
+// Zero values are replaced by the defaults, which are the usual ITU-T Q.24 limits.
+type DecodeOptions struct {
+	MinTone float64 // shortest key in seconds, default 0.04
+	// MinGap is the shortest pause between two keys in seconds, default 0.04.
+	// Shorter interruptions of a tone are bridged.
+	MinGap       float64
+	NormalTwist  float64 // dB the high group may be weaker than the low group, default 8
+	ReverseTwist float64 // dB the high group may be stronger than the low group, default 4
+	MinLevel     float64 // level of the weakest tone in dBFS, default -40
+}
+
+func (o DecodeOptions) validate() (DecodeOptions, error) {
+	if o.MinTone == 0 {
+		o.MinTone = 0.04
+	}
+	if o.MinGap == 0 {
+		o.MinGap = 0.04
+	}
+	if o.NormalTwist == 0 {
+		o.NormalTwist = 8
+	}
+	if o.ReverseTwist == 0 {
+		o.ReverseTwist = 4
+	}
+	if o.MinLevel == 0 {
+		o.MinLevel = -40
+	}
+	if o.MinTone < 0 || o.MinGap < 0 || o.NormalTwist < 0 || o.ReverseTwist < 0 {
+		return o, errors.New("Durations and twist can't be negative")
+	}
+	return o, nil
+}
+
+// Tone is a key that was recognised, with its start and end in seconds
+type Tone struct {
+	Key        rune
+	Start, End float64
+}
+
+// Keys returns the keys of the tones as a string
+func Keys(tones []Tone) string {
+	b := strings.Builder{}
+	for _, t := range tones {
+		b.WriteRune(t.Key)
+	}
+	return b.String()
+}
+
+// block is the result of a single analysis block
+type block struct {
+	key   rune // 0 if there is no key
+	level float64
+}
+
+// Decode recognises the keys in the wave. Channels are mixed to mono first.
+func Decode(w wave.Wave, opts DecodeOptions) ([]Tone, error) {
+	opts, err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+	if w.NumChannels <= 0 || w.SampleRate <= 0 {
+		return nil, errors.New("Invalid wave format")
+	}
+	sr := w.SampleRate
+	size := int(math.Round(blockDuration * float64(sr)))
+	hop := size / 4
+	if hop == 0 {
+		return nil, errors.New("Sample rate is too low")
+	}
+	// pad with a block of silence, so keys at the edges are seen by as many blocks
+	pad := make([]wave.Frame, size)
+	x := append(append(append([]wave.Frame{}, pad...), wave.MixToMono(w.Frames, w.NumChannels)...), pad...)
+
+	d := newDetector(sr, opts)
+	blocks := []block{}
+	for start := 0; start+size <= len(x); start += hop {
+		blocks = append(blocks, d.detect(x[start:start+size]))
+	}
+
+	// the time a block stands for is a hop around its centre. As a partially covered
+	// block still sees the tone, the blocks at the ends of a run are only kept when they
+	// see at least half of the amplitude (so more than half of the block holds the tone).
+	seconds := float64(hop) / float64(sr)
+	centre := func(b int) float64 {
+		return float64(b*hop-size+size/2) / float64(sr)
+	}
+	tones := []Tone{}
+	for i := 0; i < len(blocks); {
+		j := i
+		for j < len(blocks) && blocks[j].key == blocks[i].key {
+			j++
+		}
+		if blocks[i].key != 0 {
+			max := 0.0
+			for _, b := range blocks[i:j] {
+				max = math.Max(max, b.level)
+			}
+			first, last := i, j-1
+			for blocks[first].level < max/2 {
+				first++
+			}
+			for blocks[last].level < max/2 {
+				last--
+			}
+			tones = append(tones, Tone{
+				Key:   blocks[i].key,
+				Start: centre(first) - seconds/2,
+				End:   centre(last) + seconds/2,
+			})
+		}
+		i = j
+	}
+
+	// the timing is only accurate to a hop, so we're lenient by one
+	merged := []Tone{}
+	for _, t := range tones {
+		if n := len(merged); n > 0 && merged[n-1].Key == t.Key && t.Start-merged[n-1].End < opts.MinGap-seconds {
+			merged[n-1].End = t.End
+			continue
+		}
+		merged = append(merged, t)
+	}
+	out := []Tone{}
+	for _, t := range merged {
+		if t.End-t.Start >= opts.MinTone-seconds {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// detector holds the Goertzel filters for every tone and the probes next to them
+type detector struct {
+	low, high    []*audiomath.Goertzel
+	lowProbes    [][2]*audiomath.Goertzel
+	highProbes   [][2]*audiomath.Goertzel
+	minAmplitude float64
+	normalTwist  float64 // as amplitude ratios
+	reverseTwist float64
+}
+
+func newDetector(sr int, opts DecodeOptions) *detector {
+	d := &detector{
+		minAmplitude: math.Pow(10, opts.MinLevel/20),
+		normalTwist:  math.Pow(10, -opts.NormalTwist/20),
+		reverseTwist: math.Pow(10, opts.ReverseTwist/20),
+	}
+	for _, f := range LowGroup {
+		d.low = append(d.low, audiomath.NewGoertzel(f, sr))
+		d.lowProbes = append(d.lowProbes, [2]*audiomath.Goertzel{
+			audiomath.NewGoertzel(f*(1-probeOffset), sr),
+			audiomath.NewGoertzel(f*(1+probeOffset), sr),
+		})
+	}
+	for _, f := range HighGroup {
+		d.high = append(d.high, audiomath.NewGoertzel(f, sr))
+		d.highProbes = append(d.highProbes, [2]*audiomath.Goertzel{
+			audiomath.NewGoertzel(f*(1-probeOffset), sr),
+			audiomath.NewGoertzel(f*(1+probeOffset), sr),
+		})
+	}
+	return d
+}
+
+// detect returns the key in the block, if any
+func (d *detector) detect(frames []wave.Frame) block {
+	row, rowAmp, ok := strongest(d.low, d.lowProbes, frames)
+	if !ok {
+		return block{}
+	}
+	col, colAmp, ok := strongest(d.high, d.highProbes, frames)
+	if !ok {
+		return block{}
+	}
+
+	if rowAmp < d.minAmplitude || colAmp < d.minAmplitude {
+		return block{}
+	}
+	if twist := colAmp / rowAmp; twist < d.normalTwist || twist > d.reverseTwist {
+		return block{}
+	}
+	energy := 0.0
+	for _, f := range frames {
+		energy += float64(f) * float64(f)
+	}
+	energy /= float64(len(frames))
+	if (rowAmp*rowAmp+colAmp*colAmp)/2 < minToneEnergy*energy {
+		return block{}
+	}
+	return block{key: keys[row][col], level: rowAmp + colAmp}
+}
+
+// strongest returns the strongest tone of a group, as long as it stands out from the
+// others and is closer to its own frequency than to the probes
+func strongest(tones []*audiomath.Goertzel, probes [][2]*audiomath.Goertzel, frames []wave.Frame) (int, float64, bool) {
+	amps := make([]float64, len(tones))
+	best := 0
+	for i, g := range tones {
+		g.Reset()
+		g.Process(frames)
+		amps[i] = g.Amplitude()
+		if amps[i] > amps[best] {
+			best = i
+		}
+	}
+	for i, a := range amps {
+		if i != best && a*relativePeak > amps[best] {
+			return 0, 0, false
+		}
+	}
+	for _, p := range probes[best] {
+		p.Reset()
+		p.Process(frames)
+		if p.Amplitude() >= amps[best] {
+			return 0, 0, false
+		}
+	}
+	return best, amps[best], true
+}
@@ -0,0 +1,120 @@
+// Package dtmf generates and recognises DTMF (touch-tone) signals.
+//
+// Every key is the sum of a tone from the low (row) group and one from the high (column)
+// group:
+//
+//	        1209 1336 1477 1633 Hz
+//	697 Hz   1    2    3    A
+//	770 Hz   4    5    6    B
+//	852 Hz   7    8    9    C
+//	941 Hz   *    0    #    D
+package dtmf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode"
+
+	synth "github.com/DylanMeeus/GoAudio/synthesizer"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Frequencies of the tones in Hz
+var (
+	LowGroup  = []float64{697, 770, 852, 941}
+	HighGroup = []float64{1209, 1336, 1477, 1633}
+)
+
+// keys[row][column]
+var keys = [4][4]rune{
+	{'1', '2', '3', 'A'},
+	{'4', '5', '6', 'B'},
+	{'7', '8', '9', 'C'},
+	{'*', '0', '#', 'D'},
+}
+
+// Frequencies returns the low and high group frequency of a key
+func Frequencies(key rune) (float64, float64, error) {
+	key = unicode.ToUpper(key)
+	for r, row := range keys {
+		for c, k := range row {
+			if k == key {
+				return LowGroup[r], HighGroup[c], nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("%q is not a DTMF key", key)
+}
+
+// EncodeOptions configure how digits are rendered.
+// Zero values are replaced by the defaults.
+type EncodeOptions struct {
+	ToneDuration float64 // seconds per key, default 0.1
+	GapDuration  float64 // seconds of silence between keys, default 0.1
+	Amplitude    float64 // amplitude of the low group tone, default 0.4
+	// Twist is the level of the high group tone relative to the low group in dB, 0 gives
+	// both the same amplitude
+	Twist float64
+}
+
+func (o EncodeOptions) validate() (EncodeOptions, error) {
+	if o.ToneDuration == 0 {
+		o.ToneDuration = 0.1
+	}
+	if o.GapDuration == 0 {
+		o.GapDuration = 0.1
+	}
+	if o.Amplitude == 0 {
+		o.Amplitude = 0.4
+	}
+	if o.ToneDuration < 0 || o.GapDuration < 0 || o.Amplitude < 0 {
+		return o, errors.New("Durations and amplitude can't be negative")
+	}
+	if o.Amplitude*(1+math.Pow(10, o.Twist/20)) > 1 {
+		return o, errors.New("The tones would clip, lower the amplitude")
+	}
+	return o, nil
+}
+
+// Encode renders the keys (0-9, A-D, * and #) into a mono 16 bit wave
+func Encode(digits string, sr int, opts EncodeOptions) (wave.Wave, error) {
+	if sr <= 0 {
+		return wave.Wave{}, errors.New("Sample rate should be positive")
+	}
+	opts, err := opts.validate()
+	if err != nil {
+		return wave.Wave{}, err
+	}
+
+	tone := int(math.Round(opts.ToneDuration * float64(sr)))
+	gap := int(math.Round(opts.GapDuration * float64(sr)))
+	high := opts.Amplitude * math.Pow(10, opts.Twist/20)
+	frames := []wave.Frame{}
+	for i, key := range digits {
+		low, hi, err := Frequencies(key)
+		if err != nil {
+			return wave.Wave{}, err
+		}
+		if i > 0 {
+			frames = append(frames, make([]wave.Frame, gap)...)
+		}
+		// fresh oscillators start every key at a zero crossing
+		lowOsc, err := synth.NewOscillator(sr, synth.SINE)
+		if err != nil {
+			return wave.Wave{}, err
+		}
+		highOsc, err := synth.NewOscillator(sr, synth.SINE)
+		if err != nil {
+			return wave.Wave{}, err
+		}
+		for j := 0; j < tone; j++ {
+			frames = append(frames, wave.Frame(opts.Amplitude*lowOsc.Tick(low)+high*highOsc.Tick(hi)))
+		}
+	}
+
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 1, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}, nil
+}
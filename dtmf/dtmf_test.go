@@ -0,0 +1,170 @@
+package dtmf
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+func mono(frames []wave.Frame, sr int) wave.Wave {
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 1, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}
+}
+
+// pair renders two tones of the given amplitude, surrounded by 50ms of silence
+func pair(low, high, amplitude, duration float64, sr int) wave.Wave {
+	pad := make([]wave.Frame, sr/20)
+	frames := append([]wave.Frame{}, pad...)
+	for i := 0; i < int(duration*float64(sr)); i++ {
+		t := float64(i) / float64(sr)
+		frames = append(frames, wave.Frame(amplitude*(math.Sin(2*math.Pi*low*t)+math.Sin(2*math.Pi*high*t))))
+	}
+	return mono(append(frames, pad...), sr)
+}
+
+func decode(t *testing.T, w wave.Wave, opts DecodeOptions) []Tone {
+	t.Helper()
+	tones, err := Decode(w, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return tones
+}
+
+func TestFrequencies(t *testing.T) {
+	low, high, err := Frequencies('b')
+	if err != nil || low != 770 || high != 1633 {
+		t.Fatalf("Expected 770 and 1633 Hz for B, got %v and %v (%v)", low, high, err)
+	}
+	if _, _, err := Frequencies('x'); err == nil {
+		t.Fatalf("Expected an error for x")
+	}
+	if _, err := Encode("12x", 8000, EncodeOptions{}); err == nil {
+		t.Fatalf("Expected an error for a string with an x")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	digits := "0123456789*#ABCD"
+	for _, sr := range []int{8000, 44100} {
+		w, err := Encode(digits, sr, EncodeOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := int(math.Round(3.1 * float64(sr))); len(w.Frames) != expected {
+			t.Fatalf("Expected %v samples, got %v", expected, len(w.Frames))
+		}
+		tones := decode(t, w, DecodeOptions{})
+		if Keys(tones) != digits {
+			t.Fatalf("Expected %v, got %v", digits, Keys(tones))
+		}
+		for i, tone := range tones {
+			start := 0.2 * float64(i)
+			if math.Abs(tone.Start-start) > 0.01 || math.Abs(tone.End-start-0.1) > 0.01 {
+				t.Fatalf("Expected %c from %v to %v, got %v to %v", tone.Key, start, start+0.1, tone.Start, tone.End)
+			}
+		}
+	}
+}
+
+func TestTiming(t *testing.T) {
+	sr := 8000
+	// the shortest keys and pauses that have to be recognised
+	w, _ := Encode("1111", sr, EncodeOptions{ToneDuration: 0.04, GapDuration: 0.04})
+	if keys := Keys(decode(t, w, DecodeOptions{})); keys != "1111" {
+		t.Fatalf("Expected 1111 with 40ms tones and pauses, got %v", keys)
+	}
+
+	// tones this short shouldn't be recognised
+	w, _ = Encode("2", sr, EncodeOptions{ToneDuration: 0.02})
+	if keys := Keys(decode(t, w, DecodeOptions{})); keys != "" {
+		t.Fatalf("Expected nothing for a 20ms tone, got %v", keys)
+	}
+
+	// a short interruption doesn't split a key
+	w, _ = Encode("3", sr, EncodeOptions{})
+	for i := 400; i < 480; i++ {
+		w.Frames[i] = 0
+	}
+	if keys := Keys(decode(t, w, DecodeOptions{})); keys != "3" {
+		t.Fatalf("Expected 3 with a 10ms interruption, got %v", keys)
+	}
+}
+
+func TestTwist(t *testing.T) {
+	cases := []struct {
+		twist    float64
+		detected bool
+	}{
+		{0, true}, {3, true}, {6, false}, {-6, true}, {-10, false},
+	}
+	for _, c := range cases {
+		w, err := Encode("5", 8000, EncodeOptions{Amplitude: 0.2, Twist: c.twist})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if keys := Keys(decode(t, w, DecodeOptions{})); (keys == "5") != c.detected {
+			t.Fatalf("Twist of %v dB: expected detection to be %v, got %q", c.twist, c.detected, keys)
+		}
+	}
+}
+
+func TestFrequencyTolerance(t *testing.T) {
+	for _, sr := range []int{8000, 16000} {
+		for _, c := range []struct {
+			offset   float64
+			detected bool
+		}{
+			{-0.015, true}, {0.015, true}, {-0.035, false}, {0.035, false},
+		} {
+			w := pair(852*(1+c.offset), 1477*(1+c.offset), 0.3, 0.1, sr)
+			if keys := Keys(decode(t, w, DecodeOptions{})); (keys == "9") != c.detected {
+				t.Fatalf("Offset of %v%%: expected detection to be %v, got %q", 100*c.offset, c.detected, keys)
+			}
+		}
+	}
+}
+
+func TestRejection(t *testing.T) {
+	sr := 8000
+	// too quiet
+	if keys := Keys(decode(t, pair(941, 1336, 0.005, 0.1, sr), DecodeOptions{})); keys != "" {
+		t.Fatalf("Expected nothing at -46 dBFS, got %v", keys)
+	}
+	// but it can be allowed
+	if keys := Keys(decode(t, pair(941, 1336, 0.005, 0.1, sr), DecodeOptions{MinLevel: -50})); keys != "0" {
+		t.Fatalf("Expected 0 with a lower minimum level, got %v", keys)
+	}
+
+	// noise, and a key buried in noise
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]wave.Frame, sr)
+	for i := range noise {
+		noise[i] = wave.Frame(rng.NormFloat64() * 0.2)
+	}
+	if keys := Keys(decode(t, mono(noise, sr), DecodeOptions{})); keys != "" {
+		t.Fatalf("Expected nothing in noise, got %v", keys)
+	}
+	w := pair(941, 1336, 0.3, 0.1, sr)
+	for i := range w.Frames {
+		w.Frames[i] += noise[i] / 10
+	}
+	if keys := Keys(decode(t, w, DecodeOptions{})); keys != "0" {
+		t.Fatalf("Expected 0 in a little noise, got %v", keys)
+	}
+}
+
+func TestStereo(t *testing.T) {
+	w, _ := Encode("42", 8000, EncodeOptions{})
+	stereo := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, 8000, 16, nil),
+		WaveData: wave.WaveData{Frames: wave.JoinChannels([][]wave.Frame{w.Frames, w.Frames})},
+	}
+	if keys := Keys(decode(t, stereo, DecodeOptions{})); keys != "42" {
+		t.Fatalf("Expected 42, got %v", keys)
+	}
+}
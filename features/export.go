@@ -0,0 +1,91 @@
+package features
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes the matrix with one row per line and comma separated values
+func WriteCSV(w io.Writer, m [][]float64) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range m {
+		values := make([]string, len(row))
+		for i, v := range row {
+			values[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		if _, err := bw.WriteString(strings.Join(values, ",") + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteNPY writes the matrix as a NumPy array of float64 with shape (rows, columns), so it
+// can be read with numpy.load
+func WriteNPY(w io.Writer, m [][]float64) error {
+	cols := 0
+	if len(m) > 0 {
+		cols = len(m[0])
+	}
+	for _, row := range m {
+		if len(row) != cols {
+			return errors.New("All rows should have the same length")
+		}
+	}
+
+	// format version 1.0: magic, version, header length and a header padded with spaces
+	// so the data starts at a multiple of 64 bytes
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d, %d), }", len(m), cols)
+	prefix := 10
+	padding := 64 - (prefix+len(header)+1)%64
+	header += strings.Repeat(" ", padding%64) + "\n"
+
+	preamble := make([]byte, prefix, prefix+len(header))
+	copy(preamble, "\x93NUMPY\x01\x00")
+	binary.LittleEndian.PutUint16(preamble[8:], uint16(len(header)))
+	preamble = append(preamble, header...)
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(preamble); err != nil {
+		return err
+	}
+	buf := make([]byte, 8)
+	for _, row := range m {
+		for _, v := range row {
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteCSVFile writes the matrix to a CSV file
+func WriteCSVFile(file string, m [][]float64) error {
+	return writeFile(file, m, WriteCSV)
+}
+
+// WriteNPYFile writes the matrix to a .npy file
+func WriteNPYFile(file string, m [][]float64) error {
+	return writeFile(file, m, WriteNPY)
+}
+
+func writeFile(file string, m [][]float64, write func(io.Writer, [][]float64) error) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := write(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
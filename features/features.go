@@ -0,0 +1,161 @@
+// Package features extracts features for machine learning from audio: (log-)mel
+// spectrograms, MFCCs and their deltas. Results are matrices with one row per frame and
+// can be exported as CSV or NumPy (.npy) files.
+package features
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Options configure the feature extraction.
+// Zero values are replaced by the defaults.
+type Options struct {
+	FrameSize int      // STFT frame size, default 2048
+	HopSize   int      // samples between frames, default 512
+	NumMels   int      // number of mel bands, default 40
+	NumMFCC   int      // number of cepstral coefficients, default 13
+	MinFreq   float64  // lowest frequency of the filterbank, default 0
+	MaxFreq   float64  // highest frequency of the filterbank, default sample rate / 2
+	Scale     MelScale // HTK or SLANEY, default HTK
+}
+
+func (o Options) withDefaults(sr int) Options {
+	if o.FrameSize == 0 {
+		o.FrameSize = 2048
+	}
+	if o.HopSize == 0 {
+		o.HopSize = 512
+	}
+	if o.NumMels == 0 {
+		o.NumMels = 40
+	}
+	if o.NumMFCC == 0 {
+		o.NumMFCC = 13
+	}
+	if o.MaxFreq == 0 {
+		o.MaxFreq = float64(sr) / 2
+	}
+	return o
+}
+
+// MelSpectrogram returns the power in every mel band, one row per STFT frame.
+// Channels are mixed to mono first.
+func MelSpectrogram(w wave.Wave, opts Options) ([][]float64, error) {
+	opts = opts.withDefaults(w.SampleRate)
+	filters, err := MelFilterbank(opts.NumMels, opts.FrameSize, w.SampleRate, opts.MinFreq, opts.MaxFreq, opts.Scale)
+	if err != nil {
+		return nil, err
+	}
+	spectrum, err := audiomath.STFT(wave.MixToMono(w.Frames, w.NumChannels), audiomath.STFTConfig{
+		FrameSize: opts.FrameSize,
+		HopSize:   opts.HopSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]float64, len(spectrum))
+	power := make([]float64, opts.FrameSize/2+1)
+	for t, frame := range spectrum {
+		for k, c := range frame {
+			a := cmplx.Abs(c)
+			power[k] = a * a
+		}
+		out[t] = make([]float64, len(filters))
+		for m, filter := range filters {
+			for k, weight := range filter {
+				out[t][m] += weight * power[k]
+			}
+		}
+	}
+	return out, nil
+}
+
+// LogMelSpectrogram returns the mel spectrogram in dB, floored at -100 dB
+func LogMelSpectrogram(w wave.Wave, opts Options) ([][]float64, error) {
+	mel, err := MelSpectrogram(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range mel {
+		for m, p := range row {
+			row[m] = 10 * math.Log10(math.Max(p, 1e-10))
+		}
+	}
+	return mel, nil
+}
+
+// MFCC returns the mel-frequency cepstral coefficients: the DCT-II of the log-mel
+// spectrogram, keeping the first NumMFCC coefficients of every frame
+func MFCC(w wave.Wave, opts Options) ([][]float64, error) {
+	opts = opts.withDefaults(w.SampleRate)
+	if opts.NumMFCC > opts.NumMels {
+		return nil, errors.New("Can't have more coefficients than mel bands")
+	}
+	logMel, err := LogMelSpectrogram(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]float64, len(logMel))
+	for t, row := range logMel {
+		out[t] = DCT(row)[:opts.NumMFCC]
+	}
+	return out, nil
+}
+
+// DCT returns the orthonormal DCT-II of x (the "DCT" of most libraries)
+func DCT(x []float64) []float64 {
+	n := float64(len(x))
+	out := make([]float64, len(x))
+	for k := range out {
+		sum := 0.0
+		for i, v := range x {
+			sum += v * math.Cos(math.Pi/n*(float64(i)+0.5)*float64(k))
+		}
+		scale := math.Sqrt(2 / n)
+		if k == 0 {
+			scale = math.Sqrt(1 / n)
+		}
+		out[k] = sum * scale
+	}
+	return out
+}
+
+// Delta returns the time derivative of the features, as the regression over width frames
+// on either side (the HTK formula). The first and last frames are repeated at the edges.
+// Apply it twice for the delta-deltas (acceleration).
+func Delta(features [][]float64, width int) ([][]float64, error) {
+	if width < 1 {
+		return nil, errors.New("Width should be at least 1")
+	}
+	norm := 0.0
+	for n := 1; n <= width; n++ {
+		norm += 2 * float64(n*n)
+	}
+	frame := func(t int) []float64 {
+		if t < 0 {
+			t = 0
+		}
+		if t >= len(features) {
+			t = len(features) - 1
+		}
+		return features[t]
+	}
+
+	out := make([][]float64, len(features))
+	for t := range features {
+		out[t] = make([]float64, len(features[t]))
+		for n := 1; n <= width; n++ {
+			next, prev := frame(t+n), frame(t-n)
+			for i := range out[t] {
+				out[t][i] += float64(n) * (next[i] - prev[i]) / norm
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,224 @@
+package features
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 16000
+
+var (
+	melTests = []struct {
+		hz, mel float64
+		scale   MelScale
+	}{
+		{0, 0, HTK},
+		{700, 2595 * math.Log10(2), HTK},
+		{1000, 999.985, HTK},
+		{0, 0, SLANEY},
+		{500, 7.5, SLANEY},
+		{1000, 15, SLANEY},
+		{6400, 42, SLANEY},
+	}
+)
+
+func sine(freq float64, n int) wave.Wave {
+	frames := make([]wave.Frame, n)
+	for i := range frames {
+		frames[i] = wave.Frame(0.5 * math.Sin(2*math.Pi*freq*float64(i)/sr))
+	}
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 1, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}
+}
+
+func TestMelScale(t *testing.T) {
+	for _, test := range melTests {
+		if m := HzToMel(test.hz, test.scale); math.Abs(m-test.mel) > 0.01 {
+			t.Fatalf("Expected %v Hz to be %v mel, got %v", test.hz, test.mel, m)
+		}
+		if hz := MelToHz(test.mel, test.scale); math.Abs(hz-test.hz) > 0.1 {
+			t.Fatalf("Expected %v mel to be %v Hz, got %v", test.mel, test.hz, hz)
+		}
+	}
+}
+
+func TestMelFilterbank(t *testing.T) {
+	fftSize := 2048
+	df := float64(sr) / float64(fftSize)
+	for _, scale := range MelScales {
+		filters, err := MelFilterbank(20, fftSize, sr, 0, sr/2, scale)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(filters) != 20 || len(filters[0]) != fftSize/2+1 {
+			t.Fatalf("Expected 20 x %v filters, got %v x %v", fftSize/2+1, len(filters), len(filters[0]))
+		}
+		prevPeak := -1
+		for m, filter := range filters {
+			peak, area := 0, 0.0
+			for k, w := range filter {
+				if w < 0 {
+					t.Fatalf("Negative weight in filter %v", m)
+				}
+				if w > filter[peak] {
+					peak = k
+				}
+				area += w * df
+			}
+			if peak <= prevPeak {
+				t.Fatalf("Filters should be ordered by frequency")
+			}
+			prevPeak = peak
+			if scale == HTK && math.Abs(filter[peak]-1) > 0.2 {
+				t.Fatalf("HTK filters should peak at 1, filter %v peaks at %v", m, filter[peak])
+			}
+			if scale == SLANEY && math.Abs(area-1) > 0.05 {
+				t.Fatalf("Slaney filters should have an area of 1, filter %v has %v", m, area)
+			}
+		}
+	}
+	if _, err := MelFilterbank(20, 2048, sr, 0, sr, HTK); err == nil {
+		t.Fatalf("Expected an error for a range above Nyquist")
+	}
+}
+
+func TestMelSpectrogram(t *testing.T) {
+	w := sine(1000, sr)
+	mel, err := MelSpectrogram(w, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// centred frames every 512 samples
+	if expected := sr/512 + 1; len(mel) != expected || len(mel[0]) != 40 {
+		t.Fatalf("Expected %v x 40 values, got %v x %v", expected, len(mel), len(mel[0]))
+	}
+	// the band around 1 kHz holds the energy
+	filters, _ := MelFilterbank(40, 2048, sr, 0, sr/2, HTK)
+	row := mel[len(mel)/2]
+	loudest := 0
+	for m := range row {
+		if row[m] > row[loudest] {
+			loudest = m
+		}
+	}
+	if filters[loudest][1000*2048/sr] == 0 {
+		t.Fatalf("Expected the loudest band to contain 1 kHz, got band %v", loudest)
+	}
+}
+
+func TestMFCC(t *testing.T) {
+	// 500 Hz repeats every 32 samples, so every frame sees the same signal
+	mfcc, err := MFCC(sine(500, sr/2), Options{NumMFCC: 20})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mfcc[0]) != 20 {
+		t.Fatalf("Expected 20 coefficients, got %v", len(mfcc[0]))
+	}
+	// a steady tone gives the same coefficients for every (complete) frame
+	for i := range mfcc[5] {
+		if math.Abs(mfcc[5][i]-mfcc[6][i]) > 1e-3*math.Abs(mfcc[5][i])+1e-6 {
+			t.Fatalf("Expected steady coefficients, got %v and %v", mfcc[5][i], mfcc[6][i])
+		}
+	}
+	if _, err := MFCC(sine(440, sr/2), Options{NumMFCC: 50}); err == nil {
+		t.Fatalf("Expected an error for more coefficients than mel bands")
+	}
+}
+
+func TestDCT(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	out := DCT(x)
+	// orthonormal, so the energy is preserved
+	ein, eout := 0.0, 0.0
+	for i := range x {
+		ein += x[i] * x[i]
+		eout += out[i] * out[i]
+	}
+	if math.Abs(ein-eout) > 1e-9 {
+		t.Fatalf("Expected the energy %v to be preserved, got %v", ein, eout)
+	}
+	// the first coefficient is the (scaled) mean, a ramp has no even coefficients
+	if math.Abs(out[0]-4.5*math.Sqrt(8)) > 1e-9 || math.Abs(out[2]) > 1e-9 || math.Abs(out[4]) > 1e-9 {
+		t.Fatalf("Unexpected DCT of a ramp: %v", out)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	// a ramp with slope 2 in the first feature, constant in the second
+	features := make([][]float64, 10)
+	for i := range features {
+		features[i] = []float64{2 * float64(i), 1}
+	}
+	delta, err := Delta(features, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 2; i < 8; i++ {
+		if math.Abs(delta[i][0]-2) > 1e-9 || delta[i][1] != 0 {
+			t.Fatalf("Expected a delta of [2 0], got %v", delta[i])
+		}
+	}
+	deltaDelta, _ := Delta(delta, 2)
+	if math.Abs(deltaDelta[5][0]) > 1e-9 {
+		t.Fatalf("Expected no acceleration, got %v", deltaDelta[5][0])
+	}
+	if _, err := Delta(features, 0); err == nil {
+		t.Fatalf("Expected an error for a width of 0")
+	}
+}
+
+func TestExport(t *testing.T) {
+	m := [][]float64{{1, 2.5, -3}, {4, 5, 6e-7}}
+	var csv bytes.Buffer
+	if err := WriteCSV(&csv, m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if csv.String() != "1,2.5,-3\n4,5,6e-07\n" {
+		t.Fatalf("Unexpected CSV: %q", csv.String())
+	}
+
+	var npy bytes.Buffer
+	if err := WriteNPY(&npy, m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b := npy.Bytes()
+	if string(b[:6]) != "\x93NUMPY" || b[6] != 1 || b[7] != 0 {
+		t.Fatalf("Invalid NPY magic")
+	}
+	headerLen := int(binary.LittleEndian.Uint16(b[8:10]))
+	header := string(b[10 : 10+headerLen])
+	if (10+headerLen)%64 != 0 || !strings.Contains(header, "'shape': (2, 3)") || !strings.HasSuffix(header, "\n") {
+		t.Fatalf("Invalid NPY header %q", header)
+	}
+	data := b[10+headerLen:]
+	if len(data) != 6*8 || math.Float64frombits(binary.LittleEndian.Uint64(data[8:])) != 2.5 {
+		t.Fatalf("Invalid NPY data")
+	}
+
+	if err := WriteNPY(&npy, [][]float64{{1}, {1, 2}}); err == nil {
+		t.Fatalf("Expected an error for ragged rows")
+	}
+
+	// the header alone already fails
+	if err := WriteNPY(failingWriter{}, [][]float64{}); err == nil {
+		t.Fatalf("Expected the write error")
+	}
+	if err := WriteCSV(failingWriter{}, m); err == nil {
+		t.Fatalf("Expected the write error")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("Can't write")
+}
@@ -0,0 +1,96 @@
+package features
+
+import (
+	"errors"
+	"math"
+)
+
+// MelScale is a variant of the mel scale and the filterbank built on it
+type MelScale int
+
+const (
+	// HTK uses mel = 2595 * log10(1 + f/700) and triangles that peak at 1
+	HTK MelScale = iota
+	// SLANEY (the Auditory Toolbox, and librosa's default) is linear below 1 kHz and
+	// logarithmic above, with triangles of equal area
+	SLANEY
+)
+
+// MelScales maps names to the mel scale variants
+var MelScales = map[string]MelScale{
+	"htk":    HTK,
+	"slaney": SLANEY,
+}
+
+const (
+	slaneyStep   = 200. / 3 // Hz per mel below 1 kHz
+	slaneyBreak  = 1000.    // Hz where the scale turns logarithmic
+	slaneyLogMel = slaneyBreak / slaneyStep
+)
+
+var slaneyLogStep = math.Log(6.4) / 27
+
+// HzToMel converts a frequency to mels
+func HzToMel(f float64, scale MelScale) float64 {
+	if scale == HTK {
+		return 2595 * math.Log10(1+f/700)
+	}
+	if f < slaneyBreak {
+		return f / slaneyStep
+	}
+	return slaneyLogMel + math.Log(f/slaneyBreak)/slaneyLogStep
+}
+
+// MelToHz converts mels to a frequency
+func MelToHz(m float64, scale MelScale) float64 {
+	if scale == HTK {
+		return 700 * (math.Pow(10, m/2595) - 1)
+	}
+	if m < slaneyLogMel {
+		return m * slaneyStep
+	}
+	return slaneyBreak * math.Exp(slaneyLogStep*(m-slaneyLogMel))
+}
+
+// MelFilterbank returns numFilters triangular filters, spaced evenly on the mel scale
+// between minFreq and maxFreq. Every filter holds a weight for each of the fftSize/2+1
+// bins of a real FFT, so filters[m][k] is the weight of bin k in band m.
+func MelFilterbank(numFilters, fftSize, sr int, minFreq, maxFreq float64, scale MelScale) ([][]float64, error) {
+	if numFilters <= 0 || fftSize <= 0 || sr <= 0 {
+		return nil, errors.New("Number of filters, FFT size and sample rate should be positive")
+	}
+	if minFreq < 0 || maxFreq <= minFreq || maxFreq > float64(sr)/2 {
+		return nil, errors.New("Frequency range should be within 0 .. sample rate / 2")
+	}
+	if scale != HTK && scale != SLANEY {
+		return nil, errors.New("Unknown mel scale")
+	}
+
+	// the edges of the triangles: filter m spans edges m .. m+2 and peaks at m+1
+	lo, hi := HzToMel(minFreq, scale), HzToMel(maxFreq, scale)
+	edges := make([]float64, numFilters+2)
+	for i := range edges {
+		edges[i] = MelToHz(lo+(hi-lo)*float64(i)/float64(numFilters+1), scale)
+	}
+
+	bins := fftSize/2 + 1
+	filters := make([][]float64, numFilters)
+	for m := range filters {
+		filters[m] = make([]float64, bins)
+		left, centre, right := edges[m], edges[m+1], edges[m+2]
+		norm := 1.0
+		if scale == SLANEY {
+			// area of 1 (in Hz)
+			norm = 2 / (right - left)
+		}
+		for k := range filters[m] {
+			f := float64(k) * float64(sr) / float64(fftSize)
+			up := (f - left) / (centre - left)
+			down := (right - f) / (right - centre)
+			if w := math.Min(up, down); w > 0 {
+				filters[m][k] = w * norm
+			}
+		}
+	}
+	return filters, nil
+}
@@ -0,0 +1,286 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Coefficients of a second order (biquad) section, normalised so a0 = 1:
+//
+//	y[n] = b0*x[n] + b1*x[n-1] + b2*x[n-2] - a1*y[n-1] - a2*y[n-2]
+type Coefficients struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// Response returns the complex frequency response at freq Hz
+func (c Coefficients) Response(freq float64, sr int) complex128 {
+	z1 := cmplx.Exp(complex(0, -2*math.Pi*freq/float64(sr)))
+	z2 := z1 * z1
+	num := complex(c.B0, 0) + complex(c.B1, 0)*z1 + complex(c.B2, 0)*z2
+	den := 1 + complex(c.A1, 0)*z1 + complex(c.A2, 0)*z2
+	return num / den
+}
+
+// The coefficient functions below follow Robert Bristow-Johnson's Audio EQ Cookbook.
+// freq is the cutoff or centre frequency in Hz, between 0 and the Nyquist frequency, and
+// q the quality factor, above 0 (1/√2 gives a Butterworth response for lowpass and
+// highpass). Gains are in dB. Outside of those ranges they return an error, as the
+// coefficients would not be finite.
+
+// cookbook holds the intermediate values every design needs
+type cookbook struct {
+	cos, alpha float64
+}
+
+func newCookbook(freq, q float64, sr int) (cookbook, error) {
+	if sr <= 0 {
+		return cookbook{}, fmt.Errorf("Invalid sample rate %v", sr)
+	}
+	if !(freq > 0 && freq < float64(sr)/2) {
+		return cookbook{}, fmt.Errorf("Frequency %v should be between 0 and %v Hz", freq, float64(sr)/2)
+	}
+	if !(q > 0) {
+		return cookbook{}, fmt.Errorf("Q %v should be positive", q)
+	}
+	w0 := 2 * math.Pi * freq / float64(sr)
+	return cookbook{cos: math.Cos(w0), alpha: math.Sin(w0) / (2 * q)}, nil
+}
+
+// normalise divides everything by a0
+func normalise(b0, b1, b2, a0, a1, a2 float64) Coefficients {
+	return Coefficients{B0: b0 / a0, B1: b1 / a0, B2: b2 / a0, A1: a1 / a0, A2: a2 / a0}
+}
+
+// Lowpass returns the coefficients of a second order lowpass filter
+func Lowpass(freq, q float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	return normalise((1-k.cos)/2, 1-k.cos, (1-k.cos)/2, 1+k.alpha, -2*k.cos, 1-k.alpha), nil
+}
+
+// Highpass returns the coefficients of a second order highpass filter
+func Highpass(freq, q float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	return normalise((1+k.cos)/2, -(1 + k.cos), (1+k.cos)/2, 1+k.alpha, -2*k.cos, 1-k.alpha), nil
+}
+
+// Bandpass returns the coefficients of a bandpass filter with a gain of 0 dB at freq
+func Bandpass(freq, q float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	return normalise(k.alpha, 0, -k.alpha, 1+k.alpha, -2*k.cos, 1-k.alpha), nil
+}
+
+// Notch returns the coefficients of a filter that removes freq
+func Notch(freq, q float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	return normalise(1, -2*k.cos, 1, 1+k.alpha, -2*k.cos, 1-k.alpha), nil
+}
+
+// Allpass returns the coefficients of a filter that passes everything, with a phase shift
+// of 180 degrees at freq
+func Allpass(freq, q float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	return normalise(1-k.alpha, -2*k.cos, 1+k.alpha, 1+k.alpha, -2*k.cos, 1-k.alpha), nil
+}
+
+// Peaking returns the coefficients of a peaking EQ, which boosts or cuts by gain around freq
+func Peaking(freq, q, gain float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	a := math.Pow(10, gain/40)
+	return normalise(1+k.alpha*a, -2*k.cos, 1-k.alpha*a, 1+k.alpha/a, -2*k.cos, 1-k.alpha/a), nil
+}
+
+// LowShelf returns the coefficients of a filter that boosts or cuts by gain below freq
+func LowShelf(freq, q, gain float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	a := math.Pow(10, gain/40)
+	s := 2 * math.Sqrt(a) * k.alpha
+	return normalise(
+		a*((a+1)-(a-1)*k.cos+s),
+		2*a*((a-1)-(a+1)*k.cos),
+		a*((a+1)-(a-1)*k.cos-s),
+		(a+1)+(a-1)*k.cos+s,
+		-2*((a-1)+(a+1)*k.cos),
+		(a+1)+(a-1)*k.cos-s,
+	), nil
+}
+
+// HighShelf returns the coefficients of a filter that boosts or cuts by gain above freq
+func HighShelf(freq, q, gain float64, sr int) (Coefficients, error) {
+	k, err := newCookbook(freq, q, sr)
+	if err != nil {
+		return Coefficients{}, err
+	}
+	a := math.Pow(10, gain/40)
+	s := 2 * math.Sqrt(a) * k.alpha
+	return normalise(
+		a*((a+1)+(a-1)*k.cos+s),
+		-2*a*((a-1)+(a+1)*k.cos),
+		a*((a+1)+(a-1)*k.cos-s),
+		(a+1)-(a-1)*k.cos+s,
+		2*((a-1)-(a+1)*k.cos),
+		(a+1)-(a-1)*k.cos-s,
+	), nil
+}
+
+// Biquad runs a second order section over a stream of interleaved frames.
+// It remembers the last samples of every channel, so filtering a signal block by block
+// gives the same result as filtering it at once.
+//
+// The filter uses direct form I, where the state is the past input and output. That
+// keeps working when the coefficients change on every sample (e.g a filter sweep).
+type Biquad struct {
+	c        Coefficients
+	channels int
+	state    [][4]float64 // x[n-1], x[n-2], y[n-1], y[n-2] per channel
+	channel  int          // channel of the next sample
+}
+
+// NewBiquad creates a filter with the coefficients for audio with the given number of
+// channels
+func NewBiquad(c Coefficients, channels int) (*Biquad, error) {
+	if channels <= 0 {
+		return nil, errors.New("Need at least one channel to filter")
+	}
+	b := &Biquad{c: c, channels: channels}
+	b.Reset()
+	return b, nil
+}
+
+// Reset clears the state, as if no audio was processed yet
+func (b *Biquad) Reset() {
+	b.state = make([][4]float64, b.channels)
+	b.channel = 0
+}
+
+// Coefficients returns the current coefficients
+func (b *Biquad) Coefficients() Coefficients {
+	return b.c
+}
+
+// SetCoefficients changes the coefficients from the next sample on, keeping the state
+func (b *Biquad) SetCoefficients(c Coefficients) {
+	b.c = c
+}
+
+// Tick filters the next sample, which belongs to the channel after the previous one
+func (b *Biquad) Tick(x float64) float64 {
+	s := &b.state[b.channel]
+	y := b.c.B0*x + b.c.B1*s[0] + b.c.B2*s[1] - b.c.A1*s[2] - b.c.A2*s[3]
+	s[0], s[1], s[2], s[3] = x, s[0], y, s[2]
+	b.channel++
+	if b.channel == b.channels {
+		b.channel = 0
+	}
+	return y
+}
+
+// Process filters a block of interleaved frames of any size, returning as many samples
+func (b *Biquad) Process(in []wave.Frame) []wave.Frame {
+	out := make([]wave.Frame, len(in))
+	for i, x := range in {
+		out[i] = wave.Frame(b.Tick(float64(x)))
+	}
+	return out
+}
+
+// ProcessModulated filters a block of interleaved frames, asking for new coefficients
+// before every frame (all channels of a frame share them). n counts the frames in the
+// block.
+func (b *Biquad) ProcessModulated(in []wave.Frame, coefficients func(n int) Coefficients) []wave.Frame {
+	out := make([]wave.Frame, len(in))
+	for i, x := range in {
+		if b.channel == 0 {
+			b.c = coefficients(i / b.channels)
+		}
+		out[i] = wave.Frame(b.Tick(float64(x)))
+	}
+	return out
+}
+
+// Cascade runs biquads in series, giving a filter of a higher order.
+// A fourth order Linkwitz-Riley lowpass for example is two Butterworth lowpass sections
+// (Q = 1/√2) at the same frequency.
+type Cascade struct {
+	stages []*Biquad
+}
+
+// NewCascade creates a cascade of a biquad per set of coefficients, for audio with the
+// given number of channels
+func NewCascade(sections []Coefficients, channels int) (*Cascade, error) {
+	if len(sections) == 0 {
+		return nil, errors.New("Need at least one section")
+	}
+	c := &Cascade{}
+	for _, s := range sections {
+		b, err := NewBiquad(s, channels)
+		if err != nil {
+			return nil, err
+		}
+		c.stages = append(c.stages, b)
+	}
+	return c, nil
+}
+
+// Stages returns the biquads, so their coefficients can be changed
+func (c *Cascade) Stages() []*Biquad {
+	return c.stages
+}
+
+// Reset clears the state of every stage
+func (c *Cascade) Reset() {
+	for _, s := range c.stages {
+		s.Reset()
+	}
+}
+
+// Tick filters the next sample through every stage
+func (c *Cascade) Tick(x float64) float64 {
+	for _, s := range c.stages {
+		x = s.Tick(x)
+	}
+	return x
+}
+
+// Process filters a block of interleaved frames of any size, returning as many samples
+func (c *Cascade) Process(in []wave.Frame) []wave.Frame {
+	out := in
+	for _, s := range c.stages {
+		out = s.Process(out)
+	}
+	return out
+}
+
+// Response returns the complex frequency response of all stages together at freq Hz
+func (c *Cascade) Response(freq float64, sr int) complex128 {
+	h := complex(1, 0)
+	for _, s := range c.stages {
+		h *= s.c.Response(freq, sr)
+	}
+	return h
+}
@@ -0,0 +1,247 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+func db(h complex128) float64 {
+	return 20 * math.Log10(cmplx.Abs(h))
+}
+
+// must fails the test when the coefficients can't be designed
+func must(t *testing.T) func(Coefficients, error) Coefficients {
+	return func(c Coefficients, err error) Coefficients {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return c
+	}
+}
+
+func TestCookbook(t *testing.T) {
+	q := 1 / math.Sqrt2
+	cases := []struct {
+		name  string
+		c     Coefficients
+		freq  float64
+		gain  float64 // dB
+		phase float64 // degrees, checked when not NaN
+	}{
+		{"lowpass DC", must(t)(Lowpass(1000, q, sr)), 0, 0, 0},
+		{"lowpass cutoff", must(t)(Lowpass(1000, q, sr)), 1000, -3.01, -90},
+		{"lowpass stop", must(t)(Lowpass(1000, q, sr)), 3900, -50, math.NaN()},
+		{"highpass cutoff", must(t)(Highpass(1000, q, sr)), 1000, -3.01, 90},
+		{"highpass Nyquist", must(t)(Highpass(1000, q, sr)), 4000, 0, math.NaN()},
+		{"bandpass centre", must(t)(Bandpass(1000, 2, sr)), 1000, 0, 0},
+		{"notch pass", must(t)(Notch(1000, 2, sr)), 100, 0, math.NaN()},
+		{"allpass", must(t)(Allpass(1000, 2, sr)), 300, 0, math.NaN()},
+		{"allpass centre", must(t)(Allpass(1000, 2, sr)), 1000, 0, 180},
+		{"peaking centre", must(t)(Peaking(1000, 1, 6, sr)), 1000, 6, 0},
+		{"peaking far", must(t)(Peaking(1000, 4, -6, sr)), 100, 0, math.NaN()},
+		{"low shelf DC", must(t)(LowShelf(500, q, 6, sr)), 0, 6, 0},
+		{"low shelf Nyquist", must(t)(LowShelf(500, q, 6, sr)), 4000, 0, math.NaN()},
+		{"low shelf middle", must(t)(LowShelf(500, q, 6, sr)), 500, 3, math.NaN()},
+		{"high shelf Nyquist", must(t)(HighShelf(1500, q, -12, sr)), 4000, -12, math.NaN()},
+		{"high shelf DC", must(t)(HighShelf(1500, q, -12, sr)), 0, 0, math.NaN()},
+	}
+	for _, c := range cases {
+		h := c.c.Response(c.freq, sr)
+		if c.gain <= -50 {
+			if db(h) > c.gain {
+				t.Fatalf("%v: expected less than %v dB, got %v", c.name, c.gain, db(h))
+			}
+			continue
+		}
+		if math.Abs(db(h)-c.gain) > 0.05 {
+			t.Fatalf("%v: expected %v dB, got %v", c.name, c.gain, db(h))
+		}
+		if !math.IsNaN(c.phase) {
+			phase := cmplx.Phase(h) * 180 / math.Pi
+			if diff := math.Mod(phase-c.phase+540, 360) - 180; math.Abs(diff) > 0.1 {
+				t.Fatalf("%v: expected a phase of %v, got %v", c.name, c.phase, phase)
+			}
+		}
+	}
+
+	if g := cmplx.Abs(must(t)(Notch(1000, 2, sr)).Response(1000, sr)); g > 1e-9 {
+		t.Fatalf("Expected the notch to remove 1 kHz, got a gain of %v", g)
+	}
+}
+
+func sine(freq float64, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(math.Sin(2 * math.Pi * freq * float64(i) / sr))
+	}
+	return out
+}
+
+// peak of the second half, after the filter settled
+func peak(frames []wave.Frame) float64 {
+	max := 0.0
+	for _, f := range frames[len(frames)/2:] {
+		max = math.Max(max, math.Abs(float64(f)))
+	}
+	return max
+}
+
+// TestBiquad makes sure block processing is seamless and the output matches the response
+func TestBiquad(t *testing.T) {
+	c := must(t)(Peaking(700, 2, 9, sr))
+	left, right := sine(700, 4000), sine(2500, 4000)
+	in := wave.JoinChannels([][]wave.Frame{left, right})
+
+	b, err := NewBiquad(c, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	once := b.Process(in)
+	b.Reset()
+	blocks := []wave.Frame{}
+	for start := 0; start < len(in); start += 99 {
+		end := start + 99
+		if end > len(in) {
+			end = len(in)
+		}
+		blocks = append(blocks, b.Process(in[start:end])...)
+	}
+	for i := range once {
+		if once[i] != blocks[i] {
+			t.Fatalf("Sample %v: expected %v, got %v", i, once[i], blocks[i])
+		}
+	}
+
+	outs := wave.SplitChannels(once, 2)
+	for ch, freq := range []float64{700, 2500} {
+		expected := cmplx.Abs(c.Response(freq, sr))
+		if p := peak(outs[ch]); math.Abs(p-expected) > 0.01*expected {
+			t.Fatalf("Channel %v: expected a peak of %v, got %v", ch, expected, p)
+		}
+	}
+}
+
+func TestModulation(t *testing.T) {
+	in := sine(440, 2000)
+	c := must(t)(Lowpass(800, 0.7, sr))
+	b, _ := NewBiquad(c, 1)
+	expected := b.Process(in)
+
+	b.Reset()
+	steady := b.ProcessModulated(in, func(int) Coefficients { return c })
+	for i := range expected {
+		if steady[i] != expected[i] {
+			t.Fatalf("Sample %v: expected %v, got %v", i, expected[i], steady[i])
+		}
+	}
+
+	// sweep the cutoff down, the 440 Hz sine ends up in the stop band
+	b.Reset()
+	swept := b.ProcessModulated(in, func(n int) Coefficients {
+		c, _ := Lowpass(3000*math.Pow(50./3000, float64(n)/float64(len(in))), 2, sr)
+		return c
+	})
+	for i, f := range swept {
+		if math.IsNaN(float64(f)) || math.Abs(float64(f)) > 3 {
+			t.Fatalf("Sample %v: the sweep became unstable (%v)", i, f)
+		}
+	}
+	if p := peak(swept[len(swept)-400:]); p > 0.1 {
+		t.Fatalf("Expected the sweep to end up removing 440 Hz, got a peak of %v", p)
+	}
+	if b.Coefficients() == c {
+		t.Fatalf("Expected the coefficients of the sweep to stick")
+	}
+}
+
+func TestCascade(t *testing.T) {
+	// Linkwitz-Riley crossover: both sides are -6 dB at the crossover and add up flat
+	q := 1 / math.Sqrt2
+	low, err := NewCascade([]Coefficients{must(t)(Lowpass(1000, q, sr)), must(t)(Lowpass(1000, q, sr))}, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	high, _ := NewCascade([]Coefficients{must(t)(Highpass(1000, q, sr)), must(t)(Highpass(1000, q, sr))}, 1)
+	if g := db(low.Response(1000, sr)); math.Abs(g+6.02) > 0.05 {
+		t.Fatalf("Expected -6 dB at the crossover, got %v", g)
+	}
+	for _, f := range []float64{100, 1000, 3000} {
+		if g := cmplx.Abs(low.Response(f, sr) + high.Response(f, sr)); math.Abs(g-1) > 1e-6 {
+			t.Fatalf("Expected the crossover to sum flat at %v Hz, got %v", f, g)
+		}
+	}
+
+	// the cascade is the same as running the sections one after the other
+	in := randomFrames(1000, 4)
+	out := low.Process(in)
+	first, _ := NewBiquad(must(t)(Lowpass(1000, q, sr)), 1)
+	second, _ := NewBiquad(must(t)(Lowpass(1000, q, sr)), 1)
+	for i, expected := range second.Process(first.Process(in)) {
+		if out[i] != expected {
+			t.Fatalf("Sample %v: expected %v, got %v", i, expected, out[i])
+		}
+	}
+	low.Reset()
+	for i, x := range in {
+		if y := low.Tick(float64(x)); y != float64(out[i]) {
+			t.Fatalf("Sample %v: expected %v after a reset, got %v", i, out[i], y)
+		}
+	}
+	if len(low.Stages()) != 2 {
+		t.Fatalf("Expected 2 stages, got %v", len(low.Stages()))
+	}
+
+	if _, err := NewCascade(nil, 1); err == nil {
+		t.Fatalf("Expected an error for a cascade without sections")
+	}
+	if _, err := NewBiquad(Coefficients{B0: 1}, 0); err == nil {
+		t.Fatalf("Expected an error for 0 channels")
+	}
+}
+
+func TestInvalidCookbook(t *testing.T) {
+	cases := []struct {
+		name    string
+		freq, q float64
+		sr      int
+	}{
+		{"zero frequency", 0, 1, sr},
+		{"negative frequency", -100, 1, sr},
+		{"Nyquist", 4000, 1, sr},
+		{"above Nyquist", 5000, 1, sr},
+		{"zero Q", 1000, 0, sr},
+		{"negative Q", 1000, -1, sr},
+		{"NaN Q", 1000, math.NaN(), sr},
+		{"zero sample rate", 1000, 1, 0},
+	}
+	for _, c := range cases {
+		designs := map[string]func() (Coefficients, error){
+			"lowpass":    func() (Coefficients, error) { return Lowpass(c.freq, c.q, c.sr) },
+			"highpass":   func() (Coefficients, error) { return Highpass(c.freq, c.q, c.sr) },
+			"bandpass":   func() (Coefficients, error) { return Bandpass(c.freq, c.q, c.sr) },
+			"notch":      func() (Coefficients, error) { return Notch(c.freq, c.q, c.sr) },
+			"allpass":    func() (Coefficients, error) { return Allpass(c.freq, c.q, c.sr) },
+			"peaking":    func() (Coefficients, error) { return Peaking(c.freq, c.q, 6, c.sr) },
+			"low shelf":  func() (Coefficients, error) { return LowShelf(c.freq, c.q, 6, c.sr) },
+			"high shelf": func() (Coefficients, error) { return HighShelf(c.freq, c.q, 6, c.sr) },
+		}
+		for name, design := range designs {
+			if _, err := design(); err == nil {
+				t.Fatalf("%v: expected an error for %v", name, c.name)
+			}
+		}
+	}
+
+	// right inside the valid range the coefficients are finite
+	for _, freq := range []float64{1e-3, 4000 - 1e-3} {
+		coeffs := must(t)(Lowpass(freq, 1e-3, sr))
+		for _, v := range []float64{coeffs.B0, coeffs.B1, coeffs.B2, coeffs.A1, coeffs.A2} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Fatalf("Expected finite coefficients at %v Hz, got %+v", freq, coeffs)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Jacobi elliptic functions for the elliptic design, computed with Landen transformations
+// as in S. J. Orfanidis, "Lecture Notes on Elliptic Filter Design". Arguments are in
+// units of the quarter period K, and a modulus k is passed together with its complement
+// kc = √(1-k²), which keeps the precision when k is close to 1.
+
+// landen returns the descending Landen sequence of moduli, which quickly goes to 0
+func landen(k, kc float64) []float64 {
+	v := []float64{}
+	for k > 1e-15 && len(v) < 30 {
+		// k' = (k / (1+kc))² written in a form that doesn't lose precision near 1
+		k, kc = (1-kc)/(1+kc), 2*math.Sqrt(kc)/(1+kc)
+		v = append(v, k)
+	}
+	return v
+}
+
+// ascend runs w = sin or cos(uπ/2) back up the Landen sequence
+func ascend(w complex128, v []float64) complex128 {
+	for n := len(v) - 1; n >= 0; n-- {
+		w = complex(1+v[n], 0) * w / (1 + complex(v[n], 0)*w*w)
+	}
+	return w
+}
+
+// sne returns sn(uK, k)
+func sne(u complex128, k, kc float64) complex128 {
+	return ascend(cmplx.Sin(u*math.Pi/2), landen(k, kc))
+}
+
+// cde returns cd(uK, k)
+func cde(u complex128, k, kc float64) complex128 {
+	return ascend(cmplx.Cos(u*math.Pi/2), landen(k, kc))
+}
+
+// asne returns u such that sn(uK, k) = w
+func asne(w complex128, k, kc float64) complex128 {
+	v := landen(k, kc)
+	prev := k
+	for _, vn := range v {
+		w = w / (1 + cmplx.Sqrt(1-w*w*complex(prev*prev, 0))) * complex(2/(1+vn), 0)
+		prev = vn
+	}
+	return cmplx.Asin(w) * 2 / math.Pi
+}
+
+// ellipdeg solves the degree equation: it returns the modulus k (and its complement) of
+// an elliptic filter of order n with the discrimination modulus k1
+func ellipdeg(n int, k1, k1c float64) (float64, float64) {
+	kc := math.Pow(k1c, float64(n))
+	for i := 1; i <= n/2; i++ {
+		s := real(sne(complex(float64(2*i-1)/float64(n), 0), k1c, k1))
+		kc *= math.Pow(s, 4)
+	}
+	return math.Sqrt((1 - kc) * (1 + kc)), kc
+}
@@ -0,0 +1,62 @@
+// Package filter designs and runs digital filters.
+//
+// FIR filters are designed with the window method or the Parks-McClellan algorithm and
+// run by FIR, which keeps its state between blocks so a stream can be filtered in pieces.
+// Biquad does the same for second order sections, designed after the Audio EQ Cookbook,
+// which can be cascaded for higher orders. Butterworth, Chebyshev, elliptic and Bessel IIR
+// filters of any order are designed as such a cascade.
+package filter
+
+import "fmt"
+
+// Band selects which frequencies a filter passes
+type Band int
+
+// Bands of the filters we can design
+const (
+	LOWPASS Band = iota
+	HIGHPASS
+	BANDPASS
+	BANDSTOP
+)
+
+var (
+	// Bands maps the names of the bands to their constant
+	Bands = map[string]Band{
+		"lowpass":  LOWPASS,
+		"highpass": HIGHPASS,
+		"bandpass": BANDPASS,
+		"bandstop": BANDSTOP,
+	}
+)
+
+// edges returns the number of cutoff frequencies the band needs
+func (b Band) edges() int {
+	if b == BANDPASS || b == BANDSTOP {
+		return 2
+	}
+	return 1
+}
+
+// checkCutoffs makes sure there are enough cutoff frequencies, in increasing order and
+// between 0 and the Nyquist frequency
+func checkCutoffs(band Band, cutoffs []float64, sr int) error {
+	if band < LOWPASS || band > BANDSTOP {
+		return fmt.Errorf("Band %v not supported", band)
+	}
+	if len(cutoffs) != band.edges() {
+		return fmt.Errorf("Need %v cutoff frequencies, got %v", band.edges(), len(cutoffs))
+	}
+	if sr <= 0 {
+		return fmt.Errorf("Invalid sample rate %v", sr)
+	}
+	for i, c := range cutoffs {
+		if c <= 0 || c >= float64(sr)/2 {
+			return fmt.Errorf("Cutoff %v should be between 0 and %v Hz", c, float64(sr)/2)
+		}
+		if i > 0 && c <= cutoffs[i-1] {
+			return fmt.Errorf("Cutoffs should be increasing")
+		}
+	}
+	return nil
+}
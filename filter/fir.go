@@ -0,0 +1,242 @@
+package filter
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/convolution"
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// WindowedSinc designs a linear phase FIR filter with the window method: the ideal
+// (sinc) impulse response is truncated to the length of the window and tapered by it.
+// Lowpass and highpass take one cutoff frequency in Hz, bandpass and bandstop take two.
+// Highpass and bandstop filters need an odd number of taps, as an even symmetric filter
+// always blocks the Nyquist frequency.
+//
+// The taps are scaled for unity gain in the middle of the pass band (DC for lowpass and
+// bandstop, Nyquist for highpass).
+func WindowedSinc(band Band, win []float64, sr int, cutoffs ...float64) ([]float64, error) {
+	if err := checkCutoffs(band, cutoffs, sr); err != nil {
+		return nil, err
+	}
+	n := len(win)
+	if n == 0 {
+		return nil, errors.New("Window can't be empty")
+	}
+	if (band == HIGHPASS || band == BANDSTOP) && n%2 == 0 {
+		return nil, errors.New("Highpass and bandstop filters need an odd number of taps")
+	}
+
+	// cutoffs in cycles per sample
+	f := make([]float64, len(cutoffs))
+	for i, c := range cutoffs {
+		f[i] = c / float64(sr)
+	}
+	centre := float64(n-1) / 2
+	lowpass := func(fc float64, i int) float64 {
+		return 2 * fc * audiomath.Sinc(2*fc*(float64(i)-centre))
+	}
+	impulse := func(i int) float64 {
+		if float64(i) == centre {
+			return 1
+		}
+		return 0
+	}
+
+	taps := make([]float64, n)
+	for i := range taps {
+		switch band {
+		case LOWPASS:
+			taps[i] = lowpass(f[0], i)
+		case HIGHPASS:
+			taps[i] = impulse(i) - lowpass(f[0], i)
+		case BANDPASS:
+			taps[i] = lowpass(f[1], i) - lowpass(f[0], i)
+		case BANDSTOP:
+			taps[i] = impulse(i) - lowpass(f[1], i) + lowpass(f[0], i)
+		}
+		taps[i] *= win[i]
+	}
+
+	var ref float64
+	switch band {
+	case LOWPASS, BANDSTOP:
+		ref = 0
+	case HIGHPASS:
+		ref = 0.5
+	case BANDPASS:
+		ref = (f[0] + f[1]) / 2
+	}
+	gain := cmplx.Abs(firResponse(taps, ref))
+	if gain == 0 {
+		return nil, errors.New("The filter has no gain in its pass band, use more taps")
+	}
+	for i := range taps {
+		taps[i] /= gain
+	}
+	return taps, nil
+}
+
+// KaiserParameters estimates the number of taps and the Kaiser window beta for a
+// windowed-sinc filter with the given stop band attenuation (in dB) and transition width
+// (in Hz). The number of taps is odd, so it works for every band.
+func KaiserParameters(attenuation, transition float64, sr int) (int, float64) {
+	width := 2 * math.Pi * transition / float64(sr)
+	taps := int(math.Ceil((attenuation-7.95)/(2.285*width))) + 1
+	if taps < 1 {
+		taps = 1
+	}
+	if taps%2 == 0 {
+		taps++
+	}
+
+	return taps, window.KaiserBeta(attenuation)
+}
+
+// FIRResponse returns the complex frequency response of the taps at freq Hz
+func FIRResponse(taps []float64, freq float64, sr int) complex128 {
+	return firResponse(taps, freq/float64(sr))
+}
+
+// firResponse returns the frequency response at f cycles per sample
+func firResponse(taps []float64, f float64) complex128 {
+	w := 2 * math.Pi * f
+	sum := complex(0, 0)
+	for i, t := range taps {
+		sum += complex(t, 0) * cmplx.Exp(complex(0, -w*float64(i)))
+	}
+	return sum
+}
+
+const (
+	// longFilter is the number of taps above which the FFT beats computing every tap
+	longFilter = 256
+	// directTaps is the number of taps a long filter computes directly, the others are
+	// applied in the frequency domain in blocks of this size
+	directTaps = 64
+)
+
+// FIR runs an FIR filter over a stream of interleaved frames.
+// It remembers the last samples of every channel, so filtering a signal block by block
+// gives the same result as filtering it at once.
+//
+// Short filters are computed directly, which costs a multiplication per tap per sample.
+// Filters of more than 256 taps compute their first 64 taps directly and the rest with
+// partitioned FFT convolution, which costs a lot less for the hundreds of taps of a steep
+// Remez or Kaiser design. The partitions start a block after the first tap, so this adds
+// no latency.
+type FIR struct {
+	taps     []float64 // the directly computed taps, reversed to line up with the history
+	length   int       // number of taps, including the tail
+	channels int
+	// history per channel, stored twice so the last len(taps) samples are always
+	// contiguous
+	history [][]float64
+	pos     int                      // where the next sample goes in the history
+	channel int                      // channel of the next sample
+	tail    []*convolution.Convolver // the taps after the direct ones per channel, or nil
+}
+
+// NewFIR creates a filter with the given taps for audio with the given number of channels
+func NewFIR(taps []float64, channels int) (*FIR, error) {
+	if len(taps) == 0 {
+		return nil, errors.New("Need at least one tap")
+	}
+	if channels <= 0 {
+		return nil, errors.New("Need at least one channel to filter")
+	}
+	direct := taps
+	if len(taps) > longFilter {
+		direct = taps[:directTaps]
+	}
+	f := &FIR{taps: make([]float64, len(direct)), length: len(taps), channels: channels}
+	for i, t := range direct {
+		f.taps[len(direct)-1-i] = t
+	}
+	if len(direct) < len(taps) {
+		tail := wave.FloatsToFrames(taps[len(direct):])
+		for c := 0; c < channels; c++ {
+			conv, err := convolution.NewConvolver(tail, directTaps)
+			if err != nil {
+				return nil, err
+			}
+			f.tail = append(f.tail, conv)
+		}
+	}
+	f.Reset()
+	return f, nil
+}
+
+// Reset clears the state, as if no audio was processed yet
+func (f *FIR) Reset() {
+	f.history = make([][]float64, f.channels)
+	for c := range f.history {
+		f.history[c] = make([]float64, 2*len(f.taps))
+	}
+	f.pos = 0
+	f.channel = 0
+	for _, conv := range f.tail {
+		conv.Reset()
+	}
+}
+
+// Delay returns the group delay in samples, which is the same for all frequencies when
+// the taps are symmetric (linear phase)
+func (f *FIR) Delay() float64 {
+	return float64(f.length-1) / 2
+}
+
+// Process filters a block of interleaved frames of any size, returning as many samples
+func (f *FIR) Process(in []wave.Frame) []wave.Frame {
+	first := f.channel
+	n := len(f.taps)
+	out := make([]wave.Frame, len(in))
+	for i, x := range in {
+		hist := f.history[f.channel]
+		hist[f.pos] = float64(x)
+		hist[f.pos+n] = float64(x)
+		// the window ends at the newest sample
+		window := hist[f.pos+1 : f.pos+1+n]
+		sum := 0.0
+		for j, t := range f.taps {
+			sum += t * window[j]
+		}
+		out[i] = wave.Frame(sum)
+
+		f.channel++
+		if f.channel == f.channels {
+			f.channel = 0
+			f.pos++
+			if f.pos == n {
+				f.pos = 0
+			}
+		}
+	}
+
+	// the convolvers lag a block behind, which is where the tail of the taps starts
+	for c, conv := range f.tail {
+		start := (c - first + f.channels) % f.channels
+		samples := make([]wave.Frame, 0, len(in)/f.channels+1)
+		for i := start; i < len(in); i += f.channels {
+			samples = append(samples, in[i])
+		}
+		for j, y := range conv.Process(samples) {
+			out[start+j*f.channels] += y
+		}
+	}
+	return out
+}
+
+// Filter runs the taps over interleaved frames in one go. The output has the same length
+// as the input, delayed by the group delay of the filter.
+func Filter(frames []wave.Frame, channels int, taps []float64) ([]wave.Frame, error) {
+	f, err := NewFIR(taps, channels)
+	if err != nil {
+		return nil, err
+	}
+	return f.Process(frames), nil
+}
@@ -0,0 +1,237 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+const sr = 8000
+
+func randomFrames(n int, seed int64) []wave.Frame {
+	rng := rand.New(rand.NewSource(seed))
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(rng.Float64()*2 - 1)
+	}
+	return out
+}
+
+// gainDB returns the gain of the taps at freq in dB
+func gainDB(taps []float64, freq float64) float64 {
+	return 20 * math.Log10(cmplx.Abs(FIRResponse(taps, freq, sr)))
+}
+
+// worst returns the largest deviation from the gain over a range of frequencies
+func worst(taps []float64, from, to, gain float64) float64 {
+	max := 0.0
+	for f := from; f <= to; f += 5 {
+		max = math.Max(max, math.Abs(cmplx.Abs(FIRResponse(taps, f, sr))-gain))
+	}
+	return max
+}
+
+func assertSymmetric(t *testing.T, taps []float64) {
+	t.Helper()
+	for i := range taps {
+		if math.Abs(taps[i]-taps[len(taps)-1-i]) > 1e-9 {
+			t.Fatalf("Expected symmetric taps, %v != %v", taps[i], taps[len(taps)-1-i])
+		}
+	}
+}
+
+func TestWindowedSinc(t *testing.T) {
+	// Hamming windows reach about 53 dB of attenuation
+	win := window.Hamming(101, window.SYMMETRIC)
+	cases := []struct {
+		band       Band
+		cutoffs    []float64
+		pass, stop [][2]float64
+	}{
+		{LOWPASS, []float64{1000}, [][2]float64{{0, 800}}, [][2]float64{{1200, 4000}}},
+		{HIGHPASS, []float64{1000}, [][2]float64{{1200, 4000}}, [][2]float64{{0, 800}}},
+		{BANDPASS, []float64{1000, 2000}, [][2]float64{{1200, 1800}}, [][2]float64{{0, 800}, {2200, 4000}}},
+		{BANDSTOP, []float64{1000, 2000}, [][2]float64{{0, 800}, {2200, 4000}}, [][2]float64{{1200, 1800}}},
+	}
+	for _, c := range cases {
+		taps, err := WindowedSinc(c.band, win, sr, c.cutoffs...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertSymmetric(t, taps)
+		for _, p := range c.pass {
+			if d := worst(taps, p[0], p[1], 1); d > 0.01 {
+				t.Fatalf("Band %v: expected a flat pass band from %v to %v Hz, deviates %v", c.band, p[0], p[1], d)
+			}
+		}
+		for _, s := range c.stop {
+			if d := worst(taps, s[0], s[1], 0); 20*math.Log10(d) > -50 {
+				t.Fatalf("Band %v: expected at least 50 dB attenuation from %v to %v Hz, got %v dB", c.band, s[0], s[1], 20*math.Log10(d))
+			}
+		}
+		// the cutoff is halfway the transition
+		for _, cutoff := range c.cutoffs {
+			if g := gainDB(taps, cutoff); math.Abs(g+6) > 0.5 {
+				t.Fatalf("Band %v: expected -6 dB at the cutoff, got %v", c.band, g)
+			}
+		}
+	}
+}
+
+func TestKaiserParameters(t *testing.T) {
+	n, beta := KaiserParameters(80, 400, sr)
+	if n%2 == 0 {
+		t.Fatalf("Expected an odd number of taps, got %v", n)
+	}
+	taps, err := WindowedSinc(LOWPASS, window.Kaiser(n, beta, window.SYMMETRIC), sr, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d := worst(taps, 1200, 4000, 0); 20*math.Log10(d) > -79 {
+		t.Fatalf("Expected 80 dB attenuation, got %v dB", 20*math.Log10(d))
+	}
+	if d := worst(taps, 0, 800, 1); d > 1e-3 {
+		t.Fatalf("Expected a flat pass band, deviates %v", d)
+	}
+}
+
+func TestRemez(t *testing.T) {
+	for _, taps := range []int{41, 42} {
+		h, err := Remez(taps, []float64{0, 1000, 1500, 4000}, []float64{1, 0}, nil, sr)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(h) != taps {
+			t.Fatalf("Expected %v taps, got %v", taps, len(h))
+		}
+		assertSymmetric(t, h)
+		// equiripple: with equal weights the pass and stop band have the same error
+		pass, stop := worst(h, 0, 1000, 1), worst(h, 1500, 4000, 0)
+		if math.Abs(pass-stop) > 0.05*stop || stop > 0.01 {
+			t.Fatalf("%v taps: expected the same small ripple in both bands, got %v and %v", taps, pass, stop)
+		}
+	}
+
+	// weighting the stop band trades pass band ripple for attenuation
+	h, err := Remez(41, []float64{0, 1000, 1500, 4000}, []float64{1, 0}, []float64{1, 10}, sr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pass, stop := worst(h, 0, 1000, 1), worst(h, 1500, 4000, 0)
+	if math.Abs(pass-10*stop) > 0.05*pass {
+		t.Fatalf("Expected 10 times less ripple in the stop band, got %v and %v", pass, stop)
+	}
+
+	// a bandpass beats the window method with the same number of taps
+	h, err = Remez(61, []float64{0, 800, 1000, 2000, 2200, 4000}, []float64{0, 1, 0}, nil, sr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	win, _ := WindowedSinc(BANDPASS, window.Hamming(61, window.SYMMETRIC), sr, 900, 2100)
+	if worst(h, 0, 800, 0) > worst(win, 0, 800, 0) {
+		t.Fatalf("Expected more attenuation than the window method")
+	}
+	if d := worst(h, 1000, 2000, 1); d > 0.05 {
+		t.Fatalf("Expected a flat pass band, deviates %v", d)
+	}
+}
+
+func TestInvalidDesign(t *testing.T) {
+	win := window.Hamming(100, window.SYMMETRIC)
+	if _, err := WindowedSinc(HIGHPASS, win, sr, 1000); err == nil {
+		t.Fatalf("Expected an error for a highpass with an even number of taps")
+	}
+	if _, err := WindowedSinc(BANDPASS, win, sr, 1000); err == nil {
+		t.Fatalf("Expected an error for a bandpass with one cutoff")
+	}
+	if _, err := WindowedSinc(LOWPASS, win, sr, 5000); err == nil {
+		t.Fatalf("Expected an error for a cutoff above Nyquist")
+	}
+	if _, err := Remez(40, []float64{0, 1000, 1500, 4000}, []float64{0, 1}, nil, sr); err == nil {
+		t.Fatalf("Expected an error for an even highpass")
+	}
+	if _, err := Remez(41, []float64{0, 1000, 1500}, []float64{1, 0}, nil, sr); err == nil {
+		t.Fatalf("Expected an error for an odd number of band edges")
+	}
+	_, err := Remez(41, []float64{0, 1000, 1000, 2000}, []float64{1, 0}, nil, sr)
+	if err == nil || !strings.Contains(err.Error(), "transition band") {
+		t.Fatalf("Expected an error without a transition band, got %v", err)
+	}
+	// specifications the exchange can't meet should fail rather than return a bad filter
+	if _, err := Remez(201, []float64{0, 1000, 1500, 4000}, []float64{1, 0}, []float64{1, 1e12}, sr); err == nil {
+		t.Fatalf("Expected an error when the exchange doesn't converge")
+	}
+}
+
+// TestFIR makes sure filtering in odd sized blocks matches the direct convolution, for
+// interleaved channels
+func TestFIR(t *testing.T) {
+	// long filters apply the taps after the first ones in the frequency domain
+	for _, n := range []int{37, 256, 257, 501} {
+		taps := make([]float64, n)
+		for i, f := range randomFrames(n, 1) {
+			taps[i] = float64(f)
+		}
+		left, right := randomFrames(1000, 2), randomFrames(1000, 3)
+		in := wave.JoinChannels([][]wave.Frame{left, right})
+
+		f, err := NewFIR(taps, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		out := []wave.Frame{}
+		for start := 0; start < len(in); start += 77 {
+			end := start + 77
+			if end > len(in) {
+				end = len(in)
+			}
+			out = append(out, f.Process(in[start:end])...)
+		}
+
+		for c, x := range [][]wave.Frame{left, right} {
+			got := wave.SplitChannels(out, 2)[c]
+			for i := range x {
+				expected := 0.0
+				for j, tap := range taps {
+					if i-j >= 0 {
+						expected += tap * float64(x[i-j])
+					}
+				}
+				if math.Abs(float64(got[i])-expected) > 1e-9 {
+					t.Fatalf("%v taps, channel %v, sample %v: expected %v, got %v", n, c, i, expected, got[i])
+				}
+			}
+		}
+
+		f.Reset()
+		once, _ := Filter(in, 2, taps)
+		for i, o := range f.Process(in) {
+			if o != once[i] {
+				t.Fatalf("%v taps: expected the same output after a reset", n)
+			}
+		}
+	}
+}
+
+func TestFIRDelay(t *testing.T) {
+	taps, _ := WindowedSinc(LOWPASS, window.Hamming(51, window.SYMMETRIC), sr, 1000)
+	f, _ := NewFIR(taps, 1)
+	if f.Delay() != 25 {
+		t.Fatalf("Expected a delay of 25 samples, got %v", f.Delay())
+	}
+	in := make([]wave.Frame, 1000)
+	for i := range in {
+		in[i] = wave.Frame(math.Sin(2 * math.Pi * 200 * float64(i) / sr))
+	}
+	out := f.Process(in)
+	for i := 100; i < len(out); i++ {
+		if math.Abs(float64(out[i]-in[i-25])) > 5e-3 {
+			t.Fatalf("Sample %v: expected %v, got %v", i, in[i-25], out[i])
+		}
+	}
+}
@@ -0,0 +1,511 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// The IIR design functions below start from a classic analog lowpass prototype of the
+// given order, transform it to the band and turn it into a digital filter with the
+// bilinear transform, prewarping the cutoffs so they end up at the right frequency.
+// The result is a cascade of second order sections, ordered from the lowest to the
+// highest Q, which can be run with NewCascade.
+//
+// Lowpass and highpass take one cutoff frequency in Hz, bandpass and bandstop take two
+// and have twice the order.
+
+// Butterworth designs a filter with a maximally flat pass band, which is 3 dB down at the
+// cutoff
+func Butterworth(order int, band Band, sr int, cutoffs ...float64) ([]Coefficients, error) {
+	if err := checkIIR(order, band, sr, cutoffs); err != nil {
+		return nil, err
+	}
+	return design(butterworth(order), band, sr, cutoffs), nil
+}
+
+// Chebyshev1 designs a filter with ripple (in dB) in the pass band, and a steeper roll-off
+// than Butterworth. The cutoff is the edge of the pass band, the last frequency where
+// the gain is -ripple dB.
+func Chebyshev1(order int, ripple float64, band Band, sr int, cutoffs ...float64) ([]Coefficients, error) {
+	if err := checkIIR(order, band, sr, cutoffs); err != nil {
+		return nil, err
+	}
+	if ripple <= 0 {
+		return nil, errors.New("Ripple should be positive")
+	}
+	return design(chebyshev1(order, ripple), band, sr, cutoffs), nil
+}
+
+// Chebyshev2 designs a filter with a flat pass band and ripple in the stop band, which
+// is attenuated by at least attenuation dB. The cutoff is the edge of the stop band,
+// the first frequency where the attenuation is reached.
+func Chebyshev2(order int, attenuation float64, band Band, sr int, cutoffs ...float64) ([]Coefficients, error) {
+	if err := checkIIR(order, band, sr, cutoffs); err != nil {
+		return nil, err
+	}
+	if attenuation <= 0 {
+		return nil, errors.New("Attenuation should be positive")
+	}
+	return design(chebyshev2(order, attenuation), band, sr, cutoffs), nil
+}
+
+// Elliptic designs a filter with ripple in both bands, which gives the steepest roll-off
+// for an order. The pass band ripple and stop band attenuation are in dB, the cutoff
+// is the edge of the pass band, as with Chebyshev1.
+func Elliptic(order int, ripple, attenuation float64, band Band, sr int, cutoffs ...float64) ([]Coefficients, error) {
+	if err := checkIIR(order, band, sr, cutoffs); err != nil {
+		return nil, err
+	}
+	if ripple <= 0 || attenuation <= ripple {
+		return nil, errors.New("Ripple should be positive and less than the attenuation")
+	}
+	return design(elliptic(order, ripple, attenuation), band, sr, cutoffs), nil
+}
+
+// Bessel designs a filter with a maximally flat group delay, so it keeps the shape of
+// waveforms at the cost of a slow roll-off. It is 3 dB down at the cutoff.
+// The bilinear transform warps the frequencies, so the delay is only flat well below
+// the cutoff.
+func Bessel(order int, band Band, sr int, cutoffs ...float64) ([]Coefficients, error) {
+	if err := checkIIR(order, band, sr, cutoffs); err != nil {
+		return nil, err
+	}
+	return design(bessel(order), band, sr, cutoffs), nil
+}
+
+func checkIIR(order int, band Band, sr int, cutoffs []float64) error {
+	if order < 1 {
+		return fmt.Errorf("Invalid order %v", order)
+	}
+	return checkCutoffs(band, cutoffs, sr)
+}
+
+// SOSResponse returns the complex frequency response of second order sections in series
+// at freq Hz
+func SOSResponse(sections []Coefficients, freq float64, sr int) complex128 {
+	h := complex(1, 0)
+	for _, s := range sections {
+		h *= s.Response(freq, sr)
+	}
+	return h
+}
+
+// ResponsePoint is the frequency response at one frequency. Where the response is 0
+// the magnitude is -Inf and the phase and delay are undefined.
+type ResponsePoint struct {
+	Freq      float64 // Hz
+	Magnitude float64 // dB
+	Phase     float64 // radians, unwrapped
+	Delay     float64 // group delay in samples
+}
+
+// FrequencyResponse evaluates second order sections in series at n frequencies, evenly
+// spaced from 0 Hz to the Nyquist frequency, for plotting or testing a design
+func FrequencyResponse(sections []Coefficients, n, sr int) []ResponsePoint {
+	out := make([]ResponsePoint, n)
+	for i := range out {
+		f := 0.0
+		if n > 1 {
+			f = float64(i) * float64(sr) / 2 / float64(n-1)
+		}
+		h := SOSResponse(sections, f, sr)
+		phase := cmplx.Phase(h)
+		if i > 0 {
+			// unwrap: stay within π of the previous phase
+			prev := out[i-1].Phase
+			phase -= 2 * math.Pi * math.Round((phase-prev)/(2*math.Pi))
+		}
+		delay := 0.0
+		for _, s := range sections {
+			delay += s.delay(f / float64(sr))
+		}
+		out[i] = ResponsePoint{
+			Freq:      f,
+			Magnitude: 20 * math.Log10(cmplx.Abs(h)),
+			Phase:     phase,
+			Delay:     delay,
+		}
+	}
+	return out
+}
+
+// delay returns the group delay in samples at f cycles per sample. For a polynomial
+// in z^-1 the delay is Re(Σ k c[k] z^-k / Σ c[k] z^-k).
+func (c Coefficients) delay(f float64) float64 {
+	z1 := cmplx.Exp(complex(0, -2*math.Pi*f))
+	z2 := z1 * z1
+	tau := func(c0, c1, c2 float64) float64 {
+		num := complex(c1, 0)*z1 + complex(2*c2, 0)*z2
+		den := complex(c0, 0) + complex(c1, 0)*z1 + complex(c2, 0)*z2
+		return real(num / den)
+	}
+	return tau(c.B0, c.B1, c.B2) - tau(1, c.A1, c.A2)
+}
+
+// zpk is a filter as its zeros, poles and gain: H(s) = gain · Π(s-z) / Π(s-p)
+type zpk struct {
+	zeros, poles []complex128
+	gain         float64
+}
+
+// at returns the response at s (or z)
+func (f zpk) at(s complex128) complex128 {
+	h := complex(f.gain, 0)
+	for _, z := range f.zeros {
+		h *= s - z
+	}
+	for _, p := range f.poles {
+		h /= s - p
+	}
+	return h
+}
+
+// normalise sets the gain so the magnitude at s is g
+func (f zpk) normalise(s complex128, g float64) zpk {
+	f.gain = 1
+	f.gain = g / cmplx.Abs(f.at(s))
+	return f
+}
+
+// The analog prototypes are lowpass filters with a cutoff of 1 rad/s.
+
+func butterworth(n int) zpk {
+	f := zpk{}
+	for k := 0; k < n; k++ {
+		f.poles = append(f.poles, cmplx.Exp(complex(0, math.Pi*float64(2*k+n+1)/float64(2*n))))
+	}
+	return f.normalise(0, 1)
+}
+
+func chebyshev1(n int, ripple float64) zpk {
+	eps := math.Sqrt(math.Pow(10, ripple/10) - 1)
+	mu := math.Asinh(1/eps) / float64(n)
+	f := zpk{}
+	for k := 0; k < n; k++ {
+		theta := math.Pi * float64(2*k+1) / float64(2*n)
+		f.poles = append(f.poles, complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta)))
+	}
+	// an even order starts at the bottom of the ripple
+	dc := 1.0
+	if n%2 == 0 {
+		dc = 1 / math.Sqrt(1+eps*eps)
+	}
+	return f.normalise(0, dc)
+}
+
+func chebyshev2(n int, attenuation float64) zpk {
+	eps := 1 / math.Sqrt(math.Pow(10, attenuation/10)-1)
+	mu := math.Asinh(1/eps) / float64(n)
+	f := zpk{}
+	for k := 0; k < n; k++ {
+		theta := math.Pi * float64(2*k+1) / float64(2*n)
+		// the poles of Chebyshev I, inverted
+		p := complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta))
+		f.poles = append(f.poles, 1/p)
+		// the zeros are where the Chebyshev polynomial is 0, except the one at infinity of
+		// odd orders
+		if 2*k+1 != n {
+			f.zeros = append(f.zeros, complex(0, 1/math.Cos(theta)))
+		}
+	}
+	return f.normalise(0, 1)
+}
+
+func elliptic(n int, ripple, attenuation float64) zpk {
+	ep := math.Sqrt(math.Pow(10, ripple/10) - 1)
+	es := math.Sqrt(math.Pow(10, attenuation/10) - 1)
+	k1 := ep / es
+	k1c := math.Sqrt((1 - k1) * (1 + k1))
+	k, kc := ellipdeg(n, k1, k1c)
+
+	// v0 places the poles so the pass band ripple is right
+	v0 := real(complex(0, -1)*asne(complex(0, 1/ep), k1, k1c)) / float64(n)
+	f := zpk{}
+	for i := 1; i <= n/2; i++ {
+		u := float64(2*i-1) / float64(n)
+		zeta := cde(complex(u, 0), k, kc)
+		z := complex(0, 1) / (complex(k, 0) * zeta)
+		p := complex(0, 1) * cde(complex(u, -v0), k, kc)
+		f.zeros = append(f.zeros, z, cmplx.Conj(z))
+		f.poles = append(f.poles, p, cmplx.Conj(p))
+	}
+	if n%2 == 1 {
+		f.poles = append(f.poles, complex(0, 1)*sne(complex(0, v0), k, kc))
+	}
+	dc := 1.0
+	if n%2 == 0 {
+		dc = 1 / math.Sqrt(1+ep*ep)
+	}
+	return f.normalise(0, dc)
+}
+
+func bessel(n int) zpk {
+	f := zpk{poles: besselRoots(n)}
+	f = f.normalise(0, 1)
+
+	// scale the frequencies so the gain is -3 dB at 1 rad/s, by bisection on log ω
+	lo, hi := math.Log(1e-3), math.Log(1e3)
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if cmplx.Abs(f.at(complex(0, math.Exp(mid)))) > 1/math.Sqrt2 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	w := math.Exp((lo + hi) / 2)
+	for i := range f.poles {
+		f.poles[i] /= complex(w, 0)
+	}
+	return f.normalise(0, 1)
+}
+
+// besselRoots returns the roots of the reverse Bessel polynomial of order n, which has a
+// delay of 1 second at DC. They are found with the Aberth method, evaluating the
+// polynomial with its recurrence θn(s) = (2n-1)θn-1(s) + s²θn-2(s), as the coefficients
+// themselves lose too much precision at high orders.
+func besselRoots(n int) []complex128 {
+	eval := func(s complex128) (complex128, complex128) {
+		// θ0 = 1, θ1 = s+1, and their derivatives
+		prev, cur := complex(1, 0), s+1
+		dprev, dcur := complex(0, 0), complex(1, 0)
+		for k := 2; k <= n; k++ {
+			next := complex(float64(2*k-1), 0)*cur + s*s*prev
+			dnext := complex(float64(2*k-1), 0)*dcur + 2*s*prev + s*s*dprev
+			prev, cur = cur, next
+			dprev, dcur = dcur, dnext
+		}
+		return cur, dcur
+	}
+
+	// the roots come in conjugate pairs, with one real root for odd orders. Only the upper
+	// half is searched, starting on the left half of a circle with the radius of the
+	// geometric mean of the roots, the nth root of θn(0) = (2n)! / (2^n n!).
+	fact, _ := math.Lgamma(float64(2*n + 1))
+	nfact, _ := math.Lgamma(float64(n + 1))
+	radius := math.Exp((fact - float64(n)*math.Ln2 - nfact) / float64(n))
+	x := make([]complex128, (n+1)/2)
+	odd := n%2 == 1 // the last root is the real one
+	last := len(x) - 1
+	for i := range x {
+		x[i] = cmplx.Rect(radius, math.Pi/2+math.Pi*float64(2*i+1)/float64(2*n))
+	}
+	if odd {
+		x[last] = complex(-radius, 0)
+	}
+	for iter := 0; iter < 500; iter++ {
+		change := 0.0
+		for i := range x {
+			p, dp := eval(x[i])
+			ratio := p / dp
+			sum := complex(0, 0)
+			// the other roots, including the conjugate of x[i] unless it is the real one
+			for j, y := range x {
+				if j != i {
+					sum += 1 / (x[i] - y)
+				}
+				if !(odd && j == last) {
+					sum += 1 / (x[i] - cmplx.Conj(y))
+				}
+			}
+			step := ratio / (1 - ratio*sum)
+			if odd && i == last {
+				step = complex(real(step), 0)
+			}
+			x[i] -= step
+			change = math.Max(change, cmplx.Abs(step)/cmplx.Abs(x[i]))
+		}
+		if change < 1e-15 {
+			break
+		}
+	}
+	out := []complex128{}
+	for i, r := range x {
+		if odd && i == last {
+			out = append(out, r)
+		} else {
+			out = append(out, r, cmplx.Conj(r))
+		}
+	}
+	return out
+}
+
+// design transforms the analog lowpass prototype to the band at the cutoffs and returns
+// its digital version as second order sections
+func design(proto zpk, band Band, sr int, cutoffs []float64) []Coefficients {
+	// prewarp, with the bilinear transform s = 2(z-1)/(z+1)
+	w := make([]float64, len(cutoffs))
+	for i, c := range cutoffs {
+		w[i] = 2 * math.Tan(math.Pi*c/float64(sr))
+	}
+
+	var analog zpk
+	extra := len(proto.poles) - len(proto.zeros) // zeros at infinity
+	switch band {
+	case LOWPASS:
+		for _, z := range proto.zeros {
+			analog.zeros = append(analog.zeros, z*complex(w[0], 0))
+		}
+		for _, p := range proto.poles {
+			analog.poles = append(analog.poles, p*complex(w[0], 0))
+		}
+	case HIGHPASS:
+		for _, z := range proto.zeros {
+			analog.zeros = append(analog.zeros, complex(w[0], 0)/z)
+		}
+		for _, p := range proto.poles {
+			analog.poles = append(analog.poles, complex(w[0], 0)/p)
+		}
+		for i := 0; i < extra; i++ {
+			analog.zeros = append(analog.zeros, 0)
+		}
+	case BANDPASS, BANDSTOP:
+		bw := w[1] - w[0]
+		w0 := complex(math.Sqrt(w[0]*w[1]), 0)
+		// every root r becomes the two roots of s² - r·bw·s + w0² (bandpass) or the
+		// inverted ones (bandstop)
+		split := func(r complex128) []complex128 {
+			h := r * complex(bw/2, 0)
+			if band == BANDSTOP {
+				h = complex(bw/2, 0) / r
+			}
+			d := cmplx.Sqrt(h*h - w0*w0)
+			return []complex128{h + d, h - d}
+		}
+		for _, z := range proto.zeros {
+			analog.zeros = append(analog.zeros, split(z)...)
+		}
+		for _, p := range proto.poles {
+			analog.poles = append(analog.poles, split(p)...)
+		}
+		// a zero at infinity becomes one at 0 and one at infinity (bandpass) or the pair
+		// at ±j·w0 (bandstop)
+		for i := 0; i < extra; i++ {
+			if band == BANDPASS {
+				analog.zeros = append(analog.zeros, 0)
+			} else {
+				analog.zeros = append(analog.zeros, complex(0, real(w0)), complex(0, -real(w0)))
+			}
+		}
+	}
+
+	// bilinear transform: z = (2+s) / (2-s), the zeros at infinity end up at Nyquist
+	bilinear := func(s complex128) complex128 {
+		return (2 + s) / (2 - s)
+	}
+	var digital zpk
+	for _, z := range analog.zeros {
+		digital.zeros = append(digital.zeros, bilinear(z))
+	}
+	for _, p := range analog.poles {
+		digital.poles = append(digital.poles, bilinear(p))
+	}
+	for len(digital.zeros) < len(digital.poles) {
+		digital.zeros = append(digital.zeros, -1)
+	}
+
+	// keep the gain the prototype has at DC, which maps to DC (lowpass and bandstop), the
+	// Nyquist frequency (highpass) or the digital frequency of w0 (bandpass)
+	ref := 0.0
+	switch band {
+	case HIGHPASS:
+		ref = 0.5
+	case BANDPASS:
+		ref = math.Atan(math.Sqrt(w[0]*w[1])/2) / math.Pi
+	}
+	digital = digital.normalise(cmplx.Exp(complex(0, 2*math.Pi*ref)), cmplx.Abs(proto.at(0)))
+	return sections(digital)
+}
+
+// sections pairs the poles and zeros into second order sections. Every pole pair gets
+// the zeros closest to it, and the sections are ordered with the poles closest to the
+// unit circle last.
+func sections(f zpk) []Coefficients {
+	const tol = 1e-9
+	split := func(roots []complex128) (pairs, reals []complex128) {
+		for _, r := range roots {
+			switch {
+			case imag(r) > tol:
+				pairs = append(pairs, r)
+			case imag(r) >= -tol:
+				reals = append(reals, complex(real(r), 0))
+			}
+		}
+		return pairs, reals
+	}
+	poles, realPoles := split(f.poles)
+	zeros, realZeros := split(f.zeros)
+	// the lowest Q first: poles further from the unit circle
+	sort.Slice(poles, func(i, j int) bool { return cmplx.Abs(poles[i]) < cmplx.Abs(poles[j]) })
+	sort.Slice(realPoles, func(i, j int) bool { return math.Abs(real(realPoles[i])) < math.Abs(real(realPoles[j])) })
+
+	// closest returns the root closest to p, and how far it is
+	closest := func(roots *[]complex128, p complex128) (complex128, float64) {
+		best := -1
+		for i, r := range *roots {
+			if best < 0 || cmplx.Abs(r-p) < cmplx.Abs((*roots)[best]-p) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return 0, math.Inf(1)
+		}
+		return (*roots)[best], cmplx.Abs((*roots)[best] - p)
+	}
+	remove := func(roots *[]complex128, r complex128) {
+		for i := range *roots {
+			if (*roots)[i] == r {
+				*roots = append((*roots)[:i], (*roots)[i+1:]...)
+				return
+			}
+		}
+	}
+	// zerosFor takes the zeros of a section with two poles, near p
+	zerosFor := func(p complex128) (complex128, complex128) {
+		z, dz := closest(&zeros, p)
+		r, dr := closest(&realZeros, p)
+		if dz <= dr || len(realZeros) < 2 {
+			remove(&zeros, z)
+			return z, cmplx.Conj(z)
+		}
+		remove(&realZeros, r)
+		r2, _ := closest(&realZeros, p)
+		remove(&realZeros, r2)
+		return r, r2
+	}
+
+	out := []Coefficients{}
+	quadratic := func(a, b complex128) (float64, float64) {
+		return -real(a + b), real(a * b)
+	}
+	// pairs of real poles, then the complex ones
+	for len(realPoles) >= 2 {
+		p1, p2 := realPoles[0], realPoles[1]
+		realPoles = realPoles[2:]
+		z1, z2 := zerosFor(p1)
+		b1, b2 := quadratic(z1, z2)
+		a1, a2 := quadratic(p1, p2)
+		out = append(out, Coefficients{B0: 1, B1: b1, B2: b2, A1: a1, A2: a2})
+	}
+	if len(realPoles) == 1 {
+		p := realPoles[0]
+		z, _ := closest(&realZeros, p)
+		remove(&realZeros, z)
+		out = append(out, Coefficients{B0: 1, B1: -real(z), A1: -real(p)})
+	}
+	for _, p := range poles {
+		z1, z2 := zerosFor(p)
+		b1, b2 := quadratic(z1, z2)
+		a1, a2 := quadratic(p, cmplx.Conj(p))
+		out = append(out, Coefficients{B0: 1, B1: b1, B2: b2, A1: a1, A2: a2})
+	}
+
+	// put the gain in the first section
+	out[0].B0 *= f.gain
+	out[0].B1 *= f.gain
+	out[0].B2 *= f.gain
+	return out
+}
@@ -0,0 +1,204 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// assertStable checks all poles are inside the unit circle
+func assertStable(t *testing.T, sections []Coefficients) {
+	t.Helper()
+	for i, s := range sections {
+		if math.Abs(s.A2) >= 1 || math.Abs(s.A1) >= 1+s.A2 {
+			t.Fatalf("Section %v is unstable: %+v", i, s)
+		}
+	}
+}
+
+// gains returns the lowest and highest gain in dB from one frequency to another
+func gains(sections []Coefficients, from, to float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for f := from; f <= to; f += 5 {
+		g := db(SOSResponse(sections, f, sr))
+		lo, hi = math.Min(lo, g), math.Max(hi, g)
+	}
+	return lo, hi
+}
+
+func TestButterworth(t *testing.T) {
+	cases := []struct {
+		band       Band
+		cutoffs    []float64
+		pass, stop [2]float64
+	}{
+		{LOWPASS, []float64{1000}, [2]float64{0, 500}, [2]float64{2000, 4000}},
+		{HIGHPASS, []float64{1000}, [2]float64{2000, 4000}, [2]float64{0, 500}},
+		{BANDPASS, []float64{1000, 2000}, [2]float64{1300, 1600}, [2]float64{0, 500}},
+		{BANDSTOP, []float64{1000, 2000}, [2]float64{0, 500}, [2]float64{1350, 1450}},
+	}
+	for _, c := range cases {
+		s, err := Butterworth(6, c.band, sr, c.cutoffs...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertStable(t, s)
+		for _, cutoff := range c.cutoffs {
+			if g := db(SOSResponse(s, cutoff, sr)); math.Abs(g+3.01) > 0.01 {
+				t.Fatalf("Band %v: expected -3 dB at the cutoff, got %v", c.band, g)
+			}
+		}
+		if lo, hi := gains(s, c.pass[0], c.pass[1]); lo < -0.1 || hi > 1e-9 {
+			t.Fatalf("Band %v: expected a flat pass band, got %v to %v dB", c.band, lo, hi)
+		}
+		if _, hi := gains(s, c.stop[0], c.stop[1]); hi > -30 {
+			t.Fatalf("Band %v: expected at least 30 dB attenuation, got %v dB", c.band, hi)
+		}
+	}
+
+	// the order sets the number of sections
+	for order, n := range map[int]int{1: 1, 2: 1, 5: 3, 8: 4} {
+		s, _ := Butterworth(order, LOWPASS, sr, 1000)
+		if len(s) != n {
+			t.Fatalf("Order %v: expected %v sections, got %v", order, n, len(s))
+		}
+	}
+}
+
+func TestChebyshev(t *testing.T) {
+	for _, order := range []int{4, 5} {
+		s, err := Chebyshev1(order, 1, LOWPASS, sr, 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertStable(t, s)
+		if lo, hi := gains(s, 0, 1000); math.Abs(lo+1) > 0.01 || math.Abs(hi) > 0.01 {
+			t.Fatalf("Order %v: expected 1 dB of ripple in the pass band, got %v to %v dB", order, lo, hi)
+		}
+
+		s, err = Chebyshev2(order, 40, HIGHPASS, sr, 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertStable(t, s)
+		if _, hi := gains(s, 0, 1000); math.Abs(hi+40) > 0.01 {
+			t.Fatalf("Order %v: expected 40 dB attenuation in the stop band, got %v dB", order, hi)
+		}
+		if lo, hi := gains(s, 3000, 4000); lo < -0.1 || hi > 1e-9 {
+			t.Fatalf("Order %v: expected a flat pass band, got %v to %v dB", order, lo, hi)
+		}
+	}
+}
+
+func TestElliptic(t *testing.T) {
+	for _, order := range []int{4, 7} {
+		s, err := Elliptic(order, 0.5, 60, LOWPASS, sr, 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertStable(t, s)
+		if lo, hi := gains(s, 0, 1000); math.Abs(lo+0.5) > 0.01 || math.Abs(hi) > 0.01 {
+			t.Fatalf("Order %v: expected 0.5 dB of ripple in the pass band, got %v to %v dB", order, lo, hi)
+		}
+		// the stop band starts where the gain first reaches -60 dB
+		edge := 1000.0
+		for db(SOSResponse(s, edge, sr)) > -60 {
+			edge++
+		}
+		if _, hi := gains(s, edge, 4000); hi > -59.99 {
+			t.Fatalf("Order %v: expected 60 dB attenuation in the stop band, got %v dB", order, hi)
+		}
+	}
+
+	// for the same order elliptic filters are the steepest, then Chebyshev, Butterworth
+	e, _ := Elliptic(5, 1, 60, LOWPASS, sr, 1000)
+	c, _ := Chebyshev1(5, 1, LOWPASS, sr, 1000)
+	b, _ := Butterworth(5, LOWPASS, sr, 1000)
+	ge, gc, gb := db(SOSResponse(e, 1300, sr)), db(SOSResponse(c, 1300, sr)), db(SOSResponse(b, 1300, sr))
+	if !(ge < gc && gc < gb) {
+		t.Fatalf("Expected elliptic < Chebyshev < Butterworth at 1300 Hz, got %v, %v and %v dB", ge, gc, gb)
+	}
+}
+
+func TestBessel(t *testing.T) {
+	for _, order := range []int{2, 5, 12} {
+		s, err := Bessel(order, LOWPASS, sr, 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertStable(t, s)
+		if g := db(SOSResponse(s, 1000, sr)); math.Abs(g+3.01) > 0.01 {
+			t.Fatalf("Order %v: expected -3 dB at the cutoff, got %v", order, g)
+		}
+		// the group delay is flat well below the cutoff
+		r := FrequencyResponse(s, 81, sr)
+		for _, p := range r[:6] {
+			if math.Abs(p.Delay-r[0].Delay) > 0.01*r[0].Delay {
+				t.Fatalf("Order %v: expected a flat delay, got %v at %v Hz and %v at DC", order, p.Delay, p.Freq, r[0].Delay)
+			}
+		}
+	}
+}
+
+func TestFrequencyResponse(t *testing.T) {
+	s, _ := Chebyshev1(6, 0.5, BANDPASS, sr, 1000, 1500)
+	r := FrequencyResponse(s, 4001, sr)
+	if len(r) != 4001 || r[0].Freq != 0 || r[4000].Freq != 4000 {
+		t.Fatalf("Expected 4001 points from 0 to 4000 Hz")
+	}
+	for i := 1; i < len(r)-1; i++ {
+		p := r[i]
+		if g := db(SOSResponse(s, p.Freq, sr)); math.Abs(g-p.Magnitude) > 1e-9 {
+			t.Fatalf("%v Hz: expected %v dB, got %v", p.Freq, g, p.Magnitude)
+		}
+		if d := math.Abs(p.Phase - r[i-1].Phase); d >= math.Pi {
+			t.Fatalf("%v Hz: expected an unwrapped phase, jumps %v", p.Freq, d)
+		}
+		// the delay is minus the derivative of the phase, in samples
+		dw := 2 * math.Pi * (r[i+1].Freq - r[i-1].Freq) / sr
+		if p.Freq > 500 && p.Freq < 2000 {
+			if d := -(r[i+1].Phase - r[i-1].Phase) / dw; math.Abs(d-p.Delay) > 0.01*p.Delay {
+				t.Fatalf("%v Hz: expected a delay of about %v samples, got %v", p.Freq, d, p.Delay)
+			}
+		}
+	}
+}
+
+// TestIIRProcess runs a high order design, which should stay stable as a cascade
+func TestIIRProcess(t *testing.T) {
+	s, err := Elliptic(10, 0.1, 100, HIGHPASS, 44100, 50)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertStable(t, s)
+	c, _ := NewCascade(s, 1)
+	in := make([]wave.Frame, 44100)
+	for i := range in {
+		in[i] = wave.Frame(math.Sin(2 * math.Pi * 1000 * float64(i) / 44100))
+	}
+	out := c.Process(in)
+	expected := cmplx.Abs(SOSResponse(s, 1000, 44100))
+	if p := peak(out); math.Abs(p-expected) > 0.01 {
+		t.Fatalf("Expected a peak of %v, got %v", expected, p)
+	}
+}
+
+func TestInvalidIIR(t *testing.T) {
+	if _, err := Butterworth(0, LOWPASS, sr, 1000); err == nil {
+		t.Fatalf("Expected an error for order 0")
+	}
+	if _, err := Bessel(4, BANDPASS, sr, 1000); err == nil {
+		t.Fatalf("Expected an error for a bandpass with one cutoff")
+	}
+	if _, err := Chebyshev1(4, 0, LOWPASS, sr, 1000); err == nil {
+		t.Fatalf("Expected an error without ripple")
+	}
+	if _, err := Chebyshev2(4, 40, LOWPASS, sr, 4000); err == nil {
+		t.Fatalf("Expected an error for a cutoff at Nyquist")
+	}
+	if _, err := Elliptic(4, 3, 2, LOWPASS, sr, 1000); err == nil {
+		t.Fatalf("Expected an error for less attenuation than ripple")
+	}
+}
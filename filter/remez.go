@@ -0,0 +1,296 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	remezDensity    = 16  // grid points per extremal frequency
+	remezIterations = 100 // iterations before we give up on convergence
+)
+
+// Remez designs a linear phase FIR filter with the Parks-McClellan algorithm, which
+// spreads the error evenly (equiripple) and so needs fewer taps than the window method
+// for the same specification.
+//
+// bands holds pairs of band edges in Hz, between 0 and the Nyquist frequency, desired the
+// gain in every band and weights how much the error in every band counts (nil for equal
+// weights). The frequencies between bands are transition bands, they don't count but can't
+// be empty.
+// As with WindowedSinc an even number of taps can't pass the Nyquist frequency.
+//
+// For example a lowpass with a pass band up to 1kHz and a stop band from 1.5kHz, where
+// the stop band error counts ten times more:
+//
+//	Remez(101, []float64{0, 1000, 1500, 22050}, []float64{1, 0}, []float64{1, 10}, 44100)
+func Remez(taps int, bands, desired, weights []float64, sr int) ([]float64, error) {
+	if taps < 3 {
+		return nil, errors.New("Need at least 3 taps")
+	}
+	if sr <= 0 {
+		return nil, fmt.Errorf("Invalid sample rate %v", sr)
+	}
+	if len(bands) == 0 || len(bands)%2 != 0 {
+		return nil, errors.New("Bands should hold pairs of band edges")
+	}
+	if len(desired) != len(bands)/2 {
+		return nil, errors.New("Need a desired gain for every band")
+	}
+	if weights == nil {
+		weights = make([]float64, len(desired))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != len(desired) {
+		return nil, errors.New("Need a weight for every band")
+	}
+	edges := make([]float64, len(bands))
+	for i, b := range bands {
+		edges[i] = b / float64(sr)
+		if edges[i] < 0 || edges[i] > 0.5 || (i > 0 && edges[i] < edges[i-1]) {
+			return nil, errors.New("Band edges should be increasing, between 0 and the Nyquist frequency")
+		}
+		if i%2 == 0 && i > 0 && edges[i] == edges[i-1] {
+			return nil, fmt.Errorf("Bands touch at %v Hz, they need a transition band between them", b)
+		}
+	}
+	for _, w := range weights {
+		if w <= 0 {
+			return nil, errors.New("Weights should be positive")
+		}
+	}
+
+	// an odd number of taps is a cosine series of r terms. With an even number the
+	// response has a factor cos(πf), which we divide out of the desired response.
+	even := taps%2 == 0
+	r := (taps + 1) / 2
+	if even {
+		r = taps / 2
+		last := len(desired) - 1
+		if edges[len(edges)-1] == 0.5 && desired[last] != 0 {
+			return nil, errors.New("An even number of taps can't pass the Nyquist frequency")
+		}
+	}
+
+	g := newGrid(edges, desired, weights, r, even)
+	if len(g.freqs) <= r {
+		return nil, errors.New("The bands are too narrow for this many taps")
+	}
+	p, err := g.exchange(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// sample the amplitude response at taps equally spaced frequencies, and turn it into
+	// the impulse response with an inverse DFT
+	amp := func(f float64) float64 {
+		a := p.at(math.Cos(2 * math.Pi * f))
+		if even {
+			a *= math.Cos(math.Pi * f)
+		}
+		return a
+	}
+	out := make([]float64, taps)
+	centre := float64(taps-1) / 2
+	for n := range out {
+		sum := amp(0)
+		for m := 1; 2*m < taps; m++ {
+			f := float64(m) / float64(taps)
+			sum += 2 * amp(f) * math.Cos(2*math.Pi*f*(float64(n)-centre))
+		}
+		out[n] = sum / float64(taps)
+	}
+	return out, nil
+}
+
+// grid is the dense set of frequencies on which the error is evaluated
+type grid struct {
+	freqs   []float64 // cycles per sample
+	desired []float64
+	weights []float64
+	band    []int // the band every frequency belongs to
+}
+
+func newGrid(edges, desired, weights []float64, r int, even bool) grid {
+	step := 0.5 / float64(remezDensity*r)
+	g := grid{}
+	for b := 0; b < len(edges); b += 2 {
+		lo, hi := edges[b], edges[b+1]
+		if even && hi > 0.5-step {
+			// the response is 0 at Nyquist, there is nothing to fit
+			hi = 0.5 - step
+		}
+		n := int(math.Ceil((hi-lo)/step)) + 1
+		if hi <= lo {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			f := lo + float64(i)*(hi-lo)/math.Max(float64(n-1), 1)
+			d, w := desired[b/2], weights[b/2]
+			if even {
+				c := math.Cos(math.Pi * f)
+				d, w = d/c, w*c
+			}
+			g.freqs = append(g.freqs, f)
+			g.desired = append(g.desired, d)
+			g.weights = append(g.weights, w)
+			g.band = append(g.band, b/2)
+		}
+	}
+	return g
+}
+
+// exchange runs the Remez exchange algorithm, returning the polynomial (in cos 2πf) of
+// r terms with the smallest maximum weighted error over the grid. It returns an error when
+// the error doesn't settle into r+1 alternations.
+func (g grid) exchange(r int) (polynomial, error) {
+	// start with extremal frequencies spread evenly over the grid
+	ext := make([]int, r+1)
+	for i := range ext {
+		ext[i] = i * (len(g.freqs) - 1) / r
+	}
+
+	var p polynomial
+	for iter := 0; iter < remezIterations; iter++ {
+		x := make([]float64, len(ext))
+		for i, e := range ext {
+			x[i] = math.Cos(2 * math.Pi * g.freqs[e])
+		}
+
+		// the error that alternates in sign with equal size on the extremal frequencies
+		b := baryWeights(x)
+		num, den := 0.0, 0.0
+		sign := 1.0
+		for i, e := range ext {
+			num += b[i] * g.desired[e]
+			den += sign * b[i] / g.weights[e]
+			sign = -sign
+		}
+		delta := num / den
+
+		// the polynomial through r of the points, the last one follows from delta
+		ys := make([]float64, r)
+		sign = 1
+		for i := 0; i < r; i++ {
+			ys[i] = g.desired[ext[i]] - sign*delta/g.weights[ext[i]]
+			sign = -sign
+		}
+		p = newPolynomial(x[:r], ys)
+
+		errs := make([]float64, len(g.freqs))
+		maxErr := 0.0
+		for i, f := range g.freqs {
+			errs[i] = g.weights[i] * (g.desired[i] - p.at(math.Cos(2*math.Pi*f)))
+			maxErr = math.Max(maxErr, math.Abs(errs[i]))
+		}
+		if maxErr-math.Abs(delta) <= 1e-6*math.Abs(delta) {
+			return p, nil
+		}
+
+		next := g.extrema(errs, r+1)
+		if len(next) < r+1 {
+			return p, fmt.Errorf("Remez didn't converge, found %v of %v alternations", len(next), r+1)
+		}
+		same := true
+		for i := range next {
+			same = same && next[i] == ext[i]
+		}
+		if same {
+			// the extremal frequencies don't move anymore, this is as good as it gets
+			return p, nil
+		}
+		ext = next
+	}
+	return p, fmt.Errorf("Remez didn't converge in %v iterations", remezIterations)
+}
+
+// extrema returns the indices of the local extrema of the error with alternating signs,
+// dropping the smallest ones at the ends until there are at most n
+func (g grid) extrema(errs []float64, n int) []int {
+	// the largest error of every lobe of the same sign within a band
+	peak := func(i, j int) bool {
+		if j < 0 || j >= len(errs) || g.band[j] != g.band[i] {
+			return true
+		}
+		return math.Signbit(errs[j]) != math.Signbit(errs[i]) || math.Abs(errs[i]) >= math.Abs(errs[j])
+	}
+	candidates := []int{}
+	for i, e := range errs {
+		if e != 0 && peak(i, i-1) && peak(i, i+1) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// of consecutive extrema with the same sign only the largest counts
+	out := []int{}
+	for _, c := range candidates {
+		last := len(out) - 1
+		if last >= 0 && math.Signbit(errs[c]) == math.Signbit(errs[out[last]]) {
+			if math.Abs(errs[c]) > math.Abs(errs[out[last]]) {
+				out[last] = c
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	for len(out) > n {
+		if math.Abs(errs[out[0]]) < math.Abs(errs[out[len(out)-1]]) {
+			out = out[1:]
+		} else {
+			out = out[:len(out)-1]
+		}
+	}
+	return out
+}
+
+// baryWeights returns the weights of the barycentric Lagrange interpolation through x,
+// 1 / prod(x[i] - x[j]). They are scaled to avoid overflow, only their ratios matter.
+func baryWeights(x []float64) []float64 {
+	logs := make([]float64, len(x))
+	signs := make([]float64, len(x))
+	max := math.Inf(-1)
+	for i := range x {
+		signs[i] = 1
+		for j := range x {
+			if i == j {
+				continue
+			}
+			d := x[i] - x[j]
+			if d < 0 {
+				signs[i] = -signs[i]
+			}
+			logs[i] -= math.Log(math.Abs(d))
+		}
+		max = math.Max(max, logs[i])
+	}
+	out := make([]float64, len(x))
+	for i := range out {
+		out[i] = signs[i] * math.Exp(logs[i]-max)
+	}
+	return out
+}
+
+// polynomial is evaluated by barycentric interpolation through its points
+type polynomial struct {
+	x, y, w []float64
+}
+
+func newPolynomial(x, y []float64) polynomial {
+	return polynomial{x: x, y: y, w: baryWeights(x)}
+}
+
+func (p polynomial) at(x float64) float64 {
+	num, den := 0.0, 0.0
+	for i, xi := range p.x {
+		d := x - xi
+		if d == 0 {
+			return p.y[i]
+		}
+		num += p.w[i] * p.y[i] / d
+		den += p.w[i] / d
+	}
+	return num / den
+}
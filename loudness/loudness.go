@@ -0,0 +1,344 @@
+// Package loudness measures loudness according to ITU-R BS.1770-4 and EBU R128.
+//
+// Loudness is expressed in LUFS (loudness units relative to full scale): integrated over a
+// whole programme, short-term (3 s) and momentary (400 ms). The loudness range (LRA, EBU
+// Tech 3342) and the true peak (dBTP) are measured as well.
+package loudness
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/DylanMeeus/GoAudio/channel"
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const (
+	absoluteGate  = -70. // LUFS
+	relativeGate  = -10. // LU below the absolute-gated loudness, for the integrated loudness
+	rangeGate     = -20. // LU below the absolute-gated loudness, for the loudness range
+	momentaryLen  = 4    // 400 ms in sub-blocks
+	shortTermLen  = 30   // 3 s in sub-blocks
+	subBlocksPerS = 10   // sub-blocks of 100 ms
+)
+
+// Result holds the measurements of a complete programme
+type Result struct {
+	Integrated    float64   // LUFS
+	LoudnessRange float64   // LU
+	MaxMomentary  float64   // LUFS
+	MaxShortTerm  float64   // LUFS
+	TruePeak      []float64 // dBTP per channel
+	SamplePeak    []float64 // dBFS per channel
+}
+
+// MaxTruePeak returns the highest true peak of all channels
+func (r Result) MaxTruePeak() float64 {
+	return audiomath.Max(r.TruePeak)
+}
+
+// MaxSamplePeak returns the highest sample peak of all channels
+func (r Result) MaxSamplePeak() float64 {
+	return audiomath.Max(r.SamplePeak)
+}
+
+// Meter measures loudness of a stream of interleaved frames.
+// Momentary and short-term loudness can be read while processing.
+type Meter struct {
+	channels  int
+	weights   []float64
+	filters   [][2]biquad
+	tp        []*oversampler
+	blockSize int
+
+	// mean square of the current sub-block per channel
+	sums []float64
+	pos  int
+	ch   int // channel of the next sample, when a block of frames ended mid-frame
+
+	// weighted mean square of every completed sub-block
+	blocks []float64
+
+	truePeak, samplePeak       []float64
+	maxMomentary, maxShortTerm float64
+}
+
+// ChannelWeights returns the BS.1770 weights of the speakers in the layout. Surround
+// channels count 1.41 (+1.5 dB) and the LFE channel is ignored.
+func ChannelWeights(l channel.Layout) []float64 {
+	weights := make([]float64, l.Channels())
+	for i, s := range l.Speakers {
+		switch s {
+		case channel.LFE:
+			weights[i] = 0
+		case channel.BL, channel.BR, channel.SL, channel.SR:
+			weights[i] = 1.41
+		default:
+			weights[i] = 1
+		}
+	}
+	return weights
+}
+
+// NewMeter creates a loudness meter for interleaved frames.
+// When weights is nil they are derived from the default layout for the number of
+// channels, or all channels weigh 1 if there is no such layout.
+func NewMeter(channels, sr int, weights []float64) (*Meter, error) {
+	if channels <= 0 || sr <= 0 {
+		return nil, errors.New("Channels and sample rate should be positive")
+	}
+	if sr < subBlocksPerS {
+		return nil, errors.New("Sample rate too low")
+	}
+	if weights == nil {
+		if l, err := channel.DefaultLayout(channels); err == nil {
+			weights = ChannelWeights(l)
+		} else {
+			weights = make([]float64, channels)
+			for i := range weights {
+				weights[i] = 1
+			}
+		}
+	}
+	if len(weights) != channels {
+		return nil, errors.New("Need one weight per channel")
+	}
+
+	m := &Meter{
+		channels:  channels,
+		weights:   weights,
+		filters:   make([][2]biquad, channels),
+		tp:        make([]*oversampler, channels),
+		blockSize: int(math.Round(float64(sr) / subBlocksPerS)),
+		sums:      make([]float64, channels),
+	}
+	factor := oversampling(sr)
+	for c := 0; c < channels; c++ {
+		m.filters[c] = kWeighting(sr)
+		m.tp[c] = newOversampler(factor)
+	}
+	m.Reset()
+	return m, nil
+}
+
+// Reset clears all measurements
+func (m *Meter) Reset() {
+	for c := range m.filters {
+		for i := range m.filters[c] {
+			m.filters[c][i].z1, m.filters[c][i].z2 = 0, 0
+		}
+		m.tp[c].reset()
+		m.sums[c] = 0
+	}
+	m.pos, m.ch = 0, 0
+	m.blocks = []float64{}
+	m.truePeak = make([]float64, m.channels)
+	m.samplePeak = make([]float64, m.channels)
+	m.maxMomentary, m.maxShortTerm = math.Inf(-1), math.Inf(-1)
+}
+
+// Process measures the frames. They don't have to contain whole frames (one sample per
+// channel), the next call continues where this one stopped.
+func (m *Meter) Process(frames []wave.Frame) {
+	for _, f := range frames {
+		x := float64(f)
+		c := m.ch
+		if a := math.Abs(x); a > m.samplePeak[c] {
+			m.samplePeak[c] = a
+		}
+		if p := m.tp[c].peak(x); p > m.truePeak[c] {
+			m.truePeak[c] = p
+		}
+		y := m.filters[c][0].process(x)
+		y = m.filters[c][1].process(y)
+		m.sums[c] += y * y
+
+		m.ch++
+		if m.ch < m.channels {
+			continue
+		}
+		m.ch = 0
+		m.pos++
+		if m.pos == m.blockSize {
+			m.endBlock()
+		}
+	}
+}
+
+// endBlock stores the weighted mean square of the sub-block that just completed
+func (m *Meter) endBlock() {
+	z := 0.0
+	for c, s := range m.sums {
+		z += m.weights[c] * s / float64(m.blockSize)
+		m.sums[c] = 0
+	}
+	m.pos = 0
+	m.blocks = append(m.blocks, z)
+
+	if l := m.Momentary(); l > m.maxMomentary {
+		m.maxMomentary = l
+	}
+	if l := m.ShortTerm(); l > m.maxShortTerm {
+		m.maxShortTerm = l
+	}
+}
+
+// Momentary returns the loudness of the last 400 ms, -Inf before that much was processed
+func (m *Meter) Momentary() float64 {
+	return m.window(len(m.blocks), momentaryLen)
+}
+
+// ShortTerm returns the loudness of the last 3 s, -Inf before that much was processed
+func (m *Meter) ShortTerm() float64 {
+	return m.window(len(m.blocks), shortTermLen)
+}
+
+// MaxMomentary returns the highest momentary loudness so far
+func (m *Meter) MaxMomentary() float64 {
+	return m.maxMomentary
+}
+
+// MaxShortTerm returns the highest short-term loudness so far
+func (m *Meter) MaxShortTerm() float64 {
+	return m.maxShortTerm
+}
+
+// window returns the loudness of the n sub-blocks that end at (excluding) end
+func (m *Meter) window(end, n int) float64 {
+	return lufs(m.energy(end, n))
+}
+
+func (m *Meter) energy(end, n int) float64 {
+	if end < n {
+		return 0
+	}
+	z := 0.0
+	for _, b := range m.blocks[end-n : end] {
+		z += b
+	}
+	return z / float64(n)
+}
+
+// Integrated returns the gated loudness of everything processed so far.
+// Gating blocks of 400 ms overlap by 75%. Blocks below -70 LUFS are dropped, then the
+// blocks more than 10 LU below the loudness of the remaining ones.
+func (m *Meter) Integrated() float64 {
+	energies := m.gatingBlocks(momentaryLen)
+	return lufs(mean(gate(energies, relativeGate)))
+}
+
+// IntegratedAll returns the integrated loudness of several meters together, as if their
+// programmes were played one after the other. This is the loudness of an album.
+func IntegratedAll(meters ...*Meter) float64 {
+	energies := []float64{}
+	for _, m := range meters {
+		energies = append(energies, m.gatingBlocks(momentaryLen)...)
+	}
+	return lufs(mean(gate(energies, relativeGate)))
+}
+
+// LoudnessRange returns the LRA in LU of everything processed so far (EBU Tech 3342).
+// It is the difference between the 10th and 95th percentile of the short-term loudness,
+// after gating at -70 LUFS and at 20 LU below the absolute-gated loudness.
+func (m *Meter) LoudnessRange() float64 {
+	energies := gate(m.gatingBlocks(shortTermLen), rangeGate)
+	if len(energies) == 0 {
+		return 0
+	}
+	values := make([]float64, len(energies))
+	for i, z := range energies {
+		values[i] = lufs(z)
+	}
+	sort.Float64s(values)
+	return percentile(values, 0.95) - percentile(values, 0.10)
+}
+
+// TruePeak returns the true peak of each channel in dBTP
+func (m *Meter) TruePeak() []float64 {
+	peaks := make([]float64, m.channels)
+	for c, p := range m.truePeak {
+		peaks[c] = audiomath.AmplitudeToDB(math.Max(p, m.tp[c].tail()))
+	}
+	return peaks
+}
+
+// SamplePeak returns the sample peak of each channel in dBFS
+func (m *Meter) SamplePeak() []float64 {
+	peaks := make([]float64, len(m.samplePeak))
+	for c, p := range m.samplePeak {
+		peaks[c] = audiomath.AmplitudeToDB(p)
+	}
+	return peaks
+}
+
+// Result returns all measurements
+func (m *Meter) Result() Result {
+	return Result{
+		Integrated:    m.Integrated(),
+		LoudnessRange: m.LoudnessRange(),
+		MaxMomentary:  m.MaxMomentary(),
+		MaxShortTerm:  m.MaxShortTerm(),
+		TruePeak:      m.TruePeak(),
+		SamplePeak:    m.SamplePeak(),
+	}
+}
+
+// gatingBlocks returns the energy of all windows of n sub-blocks, one per sub-block,
+// that are above the absolute gate
+func (m *Meter) gatingBlocks(n int) []float64 {
+	out := []float64{}
+	for end := n; end <= len(m.blocks); end++ {
+		if z := m.energy(end, n); lufs(z) > absoluteGate {
+			out = append(out, z)
+		}
+	}
+	return out
+}
+
+// Measure returns the loudness measurements of a wave
+func Measure(w wave.Wave) (Result, error) {
+	m, err := NewMeter(w.NumChannels, w.SampleRate, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	m.Process(w.Frames)
+	return m.Result(), nil
+}
+
+// gate drops the energies that are more than offset LU below their mean loudness
+func gate(energies []float64, offset float64) []float64 {
+	threshold := lufs(mean(energies)) + offset
+	out := []float64{}
+	for _, z := range energies {
+		if lufs(z) > threshold {
+			out = append(out, z)
+		}
+	}
+	return out
+}
+
+// lufs converts a weighted mean square to loudness
+func lufs(z float64) float64 {
+	if z <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(z)
+}
+
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// percentile of sorted values, nearest rank
+func percentile(sorted []float64, p float64) float64 {
+	return sorted[int(math.Round(p*float64(len(sorted)-1)))]
+}
@@ -0,0 +1,232 @@
+package loudness
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/channel"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// The EBU test files are not distributed with GoAudio, so the test signals of
+// EBU Tech 3341 and Tech 3342 are synthesized: stereo 1 kHz sines at 48 kHz with the
+// level (dBFS) and duration of every segment given in the documents.
+
+type segment struct {
+	level   float64 // dBFS, peak amplitude of the sine
+	seconds float64
+}
+
+const sr = 48000
+
+var (
+	integratedTests = []struct {
+		name     string
+		segments []segment
+		expected float64
+	}{
+		{"Tech 3341 case 1", []segment{{-23, 20}}, -23},
+		{"Tech 3341 case 2", []segment{{-33, 20}}, -33},
+		{"Tech 3341 case 3", []segment{{-36, 10}, {-23, 60}, {-36, 10}}, -23},
+		{"Tech 3341 case 4", []segment{{-72, 10}, {-36, 10}, {-23, 60}, {-36, 10}, {-72, 10}}, -23},
+		{"Tech 3341 case 5", []segment{{-26, 20}, {-20, 20.1}, {-26, 20}}, -23},
+	}
+
+	rangeTests = []struct {
+		name     string
+		segments []segment
+		expected float64
+	}{
+		{"Tech 3342 case 1", []segment{{-20, 20}, {-30, 20}}, 10},
+		{"Tech 3342 case 2", []segment{{-20, 20}, {-15, 20}}, 5},
+		{"Tech 3342 case 3", []segment{{-40, 20}, {-20, 20}}, 20},
+		{"Tech 3342 case 4", []segment{{-50, 20}, {-35, 20}, {-20, 20}, {-35, 20}, {-50, 20}}, 15},
+	}
+)
+
+// tone renders a 1 kHz sine with the given segments on every channel
+func tone(freq float64, channels int, segments ...segment) []wave.Frame {
+	out := []wave.Frame{}
+	i := 0
+	for _, s := range segments {
+		amp := math.Pow(10, s.level/20)
+		for n := 0; n < int(s.seconds*sr); n++ {
+			x := wave.Frame(amp * math.Sin(2*math.Pi*freq*float64(i)/sr))
+			for c := 0; c < channels; c++ {
+				out = append(out, x)
+			}
+			i++
+		}
+	}
+	return out
+}
+
+func stereo(frames []wave.Frame) wave.Wave {
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}
+}
+
+func TestIntegrated(t *testing.T) {
+	for _, test := range integratedTests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := Measure(stereo(tone(1000, 2, test.segments...)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(r.Integrated-test.expected) > 0.1 {
+				t.Fatalf("Expected %v LUFS, got %v", test.expected, r.Integrated)
+			}
+		})
+	}
+}
+
+// TestIntegratedAll splits Tech 3341 case 5 over three meters
+func TestIntegratedAll(t *testing.T) {
+	meters := []*Meter{}
+	for _, s := range integratedTests[4].segments {
+		m, _ := NewMeter(2, sr, nil)
+		m.Process(tone(1000, 2, s))
+		meters = append(meters, m)
+	}
+	if l := IntegratedAll(meters...); math.Abs(l-(-23)) > 0.1 {
+		t.Fatalf("Expected -23 LUFS, got %v", l)
+	}
+	if !math.IsInf(IntegratedAll(), -1) {
+		t.Fatalf("Expected -Inf without meters")
+	}
+}
+
+// TestMomentaryShortTerm follows Tech 3341 cases 1 and 2, a steady tone should read the
+// same on every meter
+func TestMomentaryShortTerm(t *testing.T) {
+	for _, level := range []float64{-23, -33} {
+		m, _ := NewMeter(2, sr, nil)
+		m.Process(tone(1000, 2, segment{level, 5}))
+		for name, l := range map[string]float64{
+			"momentary":      m.Momentary(),
+			"short-term":     m.ShortTerm(),
+			"max momentary":  m.MaxMomentary(),
+			"max short-term": m.MaxShortTerm(),
+		} {
+			if math.Abs(l-level) > 0.1 {
+				t.Fatalf("Expected %v %v loudness, got %v", level, name, l)
+			}
+		}
+	}
+
+	m, _ := NewMeter(2, sr, nil)
+	m.Process(tone(1000, 2, segment{-23, 0.3}))
+	if !math.IsInf(m.Momentary(), -1) || !math.IsInf(m.ShortTerm(), -1) {
+		t.Fatalf("Expected -Inf before a full window was measured")
+	}
+}
+
+func TestLoudnessRange(t *testing.T) {
+	for _, test := range rangeTests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := Measure(stereo(tone(1000, 2, test.segments...)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(r.LoudnessRange-test.expected) > 1 {
+				t.Fatalf("Expected %v LU, got %v", test.expected, r.LoudnessRange)
+			}
+		})
+	}
+}
+
+// TestSurround weighs the surround channels of a 5.0 signal by +1.5 dB
+func TestSurround(t *testing.T) {
+	layout := channel.Layout{Name: "5.0", Speakers: []channel.Speaker{
+		channel.FL, channel.FR, channel.FC, channel.BL, channel.BR,
+	}}
+	m, err := NewMeter(5, sr, ChannelWeights(layout))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	front, back := tone(1000, 1, segment{-28, 10}), tone(1000, 1, segment{-30, 10})
+	m.Process(wave.JoinChannels([][]wave.Frame{front, front, front, back, back}))
+	// a single channel sine at L dBFS measures L - 3.01 LUFS, so the sum is
+	// 10 * log10(3 * 10^(-31.01/10) + 2 * 1.41 * 10^(-33.01/10))
+	expected := 10 * math.Log10(3*math.Pow(10, -3.101)+2*1.41*math.Pow(10, -3.301))
+	if l := m.Integrated(); math.Abs(l-expected) > 0.1 {
+		t.Fatalf("Expected %v LUFS, got %v", expected, l)
+	}
+
+	// the LFE channel of 5.1 does not count
+	m, _ = NewMeter(6, sr, nil)
+	silent := make([]wave.Frame, len(front))
+	m.Process(wave.JoinChannels([][]wave.Frame{front, front, silent, front, silent, silent}))
+	if l := m.Integrated(); math.Abs(l-(-28-3.01+10*math.Log10(2))) > 0.1 {
+		t.Fatalf("LFE should not be measured, got %v LUFS", l)
+	}
+}
+
+// TestTruePeak follows the idea of Tech 3341 cases 15 - 19: a sine at a quarter of the
+// sample rate, sampled 45 degrees out of phase, peaks 3 dB above its samples.
+func TestTruePeak(t *testing.T) {
+	frames := make([]wave.Frame, 2*sr)
+	for i := range frames {
+		frames[i] = wave.Frame(0.5 * math.Sin(math.Pi/2*float64(i/2)+math.Pi/4))
+	}
+	r, err := Measure(stereo(frames))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := 20 * math.Log10(0.5)
+	// EBU Tech 3341 allows +0.2 / -0.4 dB
+	if tp := r.MaxTruePeak(); tp > expected+0.2 || tp < expected-0.4 {
+		t.Fatalf("Expected a true peak of %v dBTP, got %v", expected, tp)
+	}
+	if sp := r.MaxSamplePeak(); math.Abs(sp-(expected-3.01)) > 0.01 {
+		t.Fatalf("Expected a sample peak of %v dBFS, got %v", expected-3.01, sp)
+	}
+
+	// the true peak is never below the sample peak, also at the very end of the stream
+	frames = make([]wave.Frame, 2*sr)
+	frames[len(frames)-1] = 0.5
+	r, _ = Measure(stereo(frames))
+	if r.MaxTruePeak() < r.MaxSamplePeak() {
+		t.Fatalf("True peak %v dBTP below the sample peak %v dBFS", r.MaxTruePeak(), r.MaxSamplePeak())
+	}
+
+	// a low frequency sine has no inter-sample peaks to speak of
+	r, _ = Measure(stereo(tone(1000, 2, segment{-6, 1})))
+	if tp := r.MaxTruePeak(); tp > -6+0.2 || tp < -6-0.4 {
+		t.Fatalf("Expected a true peak of -6 dBTP, got %v", tp)
+	}
+}
+
+// TestStreaming makes sure odd block sizes give the same result as processing at once
+func TestStreaming(t *testing.T) {
+	frames := tone(1000, 2, segment{-30, 2}, segment{-20, 2})
+	whole, _ := NewMeter(2, sr, nil)
+	whole.Process(frames)
+	blocks, _ := NewMeter(2, sr, nil)
+	for start := 0; start < len(frames); start += 333 {
+		end := start + 333
+		if end > len(frames) {
+			end = len(frames)
+		}
+		blocks.Process(frames[start:end])
+	}
+	if whole.Integrated() != blocks.Integrated() || whole.MaxMomentary() != blocks.MaxMomentary() {
+		t.Fatalf("Expected %v, got %v", whole.Result(), blocks.Result())
+	}
+
+	whole.Reset()
+	if !math.IsInf(whole.Integrated(), -1) {
+		t.Fatalf("Expected -Inf after a reset, got %v", whole.Integrated())
+	}
+}
+
+func TestInvalidMeter(t *testing.T) {
+	if _, err := NewMeter(0, sr, nil); err == nil {
+		t.Fatalf("Expected an error for 0 channels")
+	}
+	if _, err := NewMeter(2, sr, []float64{1}); err == nil {
+		t.Fatalf("Expected an error for missing weights")
+	}
+}
@@ -0,0 +1,112 @@
+package loudness
+
+import (
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// tapsPerPhase is the length of every polyphase branch of the true-peak interpolator.
+// BS.1770 suggests 12, a few more give a flatter passband.
+const tapsPerPhase = 16
+
+// oversampler estimates the peaks between samples by upsampling with a windowed-sinc
+// interpolator (ITU-R BS.1770 Annex 2)
+type oversampler struct {
+	phases [][]float64 // phases[p][i] weighs the input i samples ago
+	// the last tapsPerPhase inputs are history[pos:pos+tapsPerPhase], newest first.
+	// Every input is stored twice so that window never wraps around.
+	history []float64
+	pos     int
+}
+
+// oversampling returns the factor that brings the sample rate to at least 192 kHz
+func oversampling(sr int) int {
+	switch {
+	case sr < 96000:
+		return 4
+	case sr < 192000:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func newOversampler(factor int) *oversampler {
+	n := factor * tapsPerPhase
+	// centred on an input sample, so the first phase returns the samples themselves
+	kaiser := window.Kaiser(n+1, 8, window.SYMMETRIC)
+	centre := float64(n / 2)
+	phases := make([][]float64, factor)
+	for p := range phases {
+		phases[p] = make([]float64, tapsPerPhase)
+		sum := 0.0
+		for i := range phases[p] {
+			k := p + i*factor
+			x := (float64(k) - centre) / float64(factor)
+			h := kaiser[k]
+			if x != 0 {
+				h *= math.Sin(math.Pi*x) / (math.Pi * x)
+			}
+			phases[p][i] = h
+			sum += h
+		}
+		// unity gain at DC for every phase
+		for i := range phases[p] {
+			phases[p][i] /= sum
+		}
+	}
+	return &oversampler{
+		phases:  phases,
+		history: make([]float64, 2*tapsPerPhase),
+	}
+}
+
+// peak adds a sample and returns the highest absolute value of the interpolated samples
+// that it completes
+func (o *oversampler) peak(x float64) float64 {
+	if len(o.phases) == 1 {
+		return math.Abs(x)
+	}
+	if o.pos == 0 {
+		o.pos = tapsPerPhase
+	}
+	o.pos--
+	o.history[o.pos], o.history[o.pos+tapsPerPhase] = x, x
+	recent := o.history[o.pos : o.pos+tapsPerPhase]
+	max := 0.0
+	for _, phase := range o.phases {
+		y := 0.0
+		for i, h := range phase {
+			y += h * recent[i]
+		}
+		if math.Abs(y) > max {
+			max = math.Abs(y)
+		}
+	}
+	return max
+}
+
+func (o *oversampler) reset() {
+	for i := range o.history {
+		o.history[i] = 0
+	}
+	o.pos = 0
+}
+
+// tail returns the peak of the interpolated samples that are still pending at the end of
+// the stream, due to the delay of the filter. It doesn't change the state.
+func (o *oversampler) tail() float64 {
+	rest := &oversampler{
+		phases:  o.phases,
+		history: append([]float64{}, o.history...),
+		pos:     o.pos,
+	}
+	max := 0.0
+	for i := 0; i < tapsPerPhase; i++ {
+		if p := rest.peak(0); p > max {
+			max = p
+		}
+	}
+	return max
+}
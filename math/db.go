@@ -0,0 +1,18 @@
+package math
+
+import "math"
+
+// AmplitudeToDB converts an amplitude to decibels relative to 1, so full scale is 0 dBFS
+// and silence is -Inf
+func AmplitudeToDB(amplitude float64) float64 {
+	return 20 * math.Log10(amplitude)
+}
+
+// Max returns the largest of the values, -Inf when there are none
+func Max(xs []float64) float64 {
+	max := math.Inf(-1)
+	for _, x := range xs {
+		max = math.Max(max, x)
+	}
+	return max
+}
@@ -0,0 +1,31 @@
+package math
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAmplitudeToDB(t *testing.T) {
+	tests := []struct {
+		amplitude, db float64
+	}{
+		{1, 0},
+		{0.5, -6.0206},
+		{10, 20},
+		{0, math.Inf(-1)},
+	}
+	for _, test := range tests {
+		if db := AmplitudeToDB(test.amplitude); math.Abs(db-test.db) > 1e-4 && db != test.db {
+			t.Fatalf("Expected %v dB for %v, got %v", test.db, test.amplitude, db)
+		}
+	}
+}
+
+func TestMax(t *testing.T) {
+	if m := Max([]float64{-3, 2, math.Inf(-1), 1}); m != 2 {
+		t.Fatalf("Expected 2, got %v", m)
+	}
+	if m := Max(nil); !math.IsInf(m, -1) {
+		t.Fatalf("Expected -Inf without values, got %v", m)
+	}
+}
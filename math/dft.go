@@ -11,6 +11,8 @@ const (
 )
 
 // DFT is a discrete fourier transformation on the input frames
+// X[k] = 1/N * sum(x[n] * e^(2*pi*i*k*n/N)), which is the complex conjugate of FFT scaled
+// by 1/N.
 // DEPRECATED
 // Please use FFT unless you are sure  you want this one..
 func DFT(input []wave.Frame) []complex128 {
@@ -20,14 +22,15 @@ func DFT(input []wave.Frame) []complex128 {
 
 	reals := make([]float64, len(input))
 	imgs := make([]float64, len(input))
-	for i, frame := range input {
-		for n := 0; n < N; n++ {
-			reals[i] += float64(frame) * math.Cos(float64(i*n)*tau/float64(N))
-			imgs[i] += float64(frame) * math.Sin(float64(i*n)*tau/float64(N))
+	for k := range input {
+		for n, frame := range input {
+			angle := float64((k*n)%N) * tau / float64(N)
+			reals[k] += float64(frame) * math.Cos(angle)
+			imgs[k] += float64(frame) * math.Sin(angle)
 		}
 
-		reals[i] /= float64(N)
-		imgs[i] /= float64(N)
+		reals[k] /= float64(N)
+		imgs[k] /= float64(N)
 	}
 
 	for i := 0; i < len(reals); i++ {
@@ -38,6 +41,7 @@ func DFT(input []wave.Frame) []complex128 {
 }
 
 // HFFT mutates freqs!
+// It is the recursive radix-2 step of the FFT, n has to be a power of two.
 func HFFT(input []wave.Frame, freqs []complex128, n, step int) {
 	if n == 1 {
 		freqs[0] = complex(input[0], 0)
@@ -50,15 +54,8 @@ func HFFT(input []wave.Frame, freqs []complex128, n, step int) {
 	HFFT(input[step:], freqs[h:], h, 2*step)
 
 	for k := 0; k < h; k++ {
-		a := -2 * math.Pi * float64(k) * float64(n)
+		a := -2 * math.Pi * float64(k) / float64(n)
 		e := cmplx.Rect(1, a) * freqs[k+h]
 		freqs[k], freqs[k+h] = freqs[k]+e, freqs[k]-e
 	}
 }
-
-// FFT (Fast Fourier Transform) implementation
-func FFT(input []wave.Frame) []complex128 {
-	freqs := make([]complex128, len(input))
-	HFFT(input, freqs, len(input), 1)
-	return freqs
-}
@@ -0,0 +1,166 @@
+package math
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// FFT (Fast Fourier Transform) implementation
+// Works for any input length, lengths that are a power of two are the fastest.
+func FFT(input []wave.Frame) []complex128 {
+	x := make([]complex128, len(input))
+	for i, f := range input {
+		x[i] = complex(float64(f), 0)
+	}
+	return ComplexFFT(x)
+}
+
+// RFFT is the FFT of a real signal, only returning the N/2+1 non-negative frequency bins.
+// The other bins are the complex conjugates of these.
+func RFFT(input []wave.Frame) []complex128 {
+	freqs := FFT(input)
+	return freqs[:len(input)/2+1]
+}
+
+// ComplexFFT returns the discrete Fourier transform of x.
+// Power of two lengths use radix-2, other lengths use Bluestein's algorithm.
+// Does not modify the input.
+func ComplexFFT(x []complex128) []complex128 {
+	n := len(x)
+	out := make([]complex128, n)
+	copy(out, x)
+	if n <= 1 {
+		return out
+	}
+	if isPowerOfTwo(n) {
+		radix2(out, false)
+		return out
+	}
+	return bluestein(out)
+}
+
+// IFFT returns the inverse discrete Fourier transform of freqs, scaled by 1/N so that
+// IFFT(ComplexFFT(x)) == x.
+func IFFT(freqs []complex128) []complex128 {
+	n := len(freqs)
+	if n == 0 {
+		return []complex128{}
+	}
+	// ifft(X) = conj(fft(conj(X))) / N
+	x := make([]complex128, n)
+	for i, f := range freqs {
+		x[i] = cmplx.Conj(f)
+	}
+	x = ComplexFFT(x)
+	scale := complex(1/float64(n), 0)
+	for i := range x {
+		x[i] = cmplx.Conj(x[i]) * scale
+	}
+	return x
+}
+
+// IRFFT turns the N/2+1 bins returned by RFFT back into a real signal of length n
+func IRFFT(bins []complex128, n int) []float64 {
+	full := make([]complex128, n)
+	for k := 0; k < n && k < len(bins); k++ {
+		full[k] = bins[k]
+	}
+	// restore the negative frequencies from the conjugate symmetry
+	for k := n/2 + 1; k < n; k++ {
+		if n-k < len(bins) {
+			full[k] = cmplx.Conj(bins[n-k])
+		}
+	}
+	x := IFFT(full)
+	out := make([]float64, n)
+	for i, c := range x {
+		out[i] = real(c)
+	}
+	return out
+}
+
+// NextPowerOfTwo returns the smallest power of two >= n
+func NextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// radix2 is an in-place iterative Cooley-Tukey FFT, len(x) has to be a power of two.
+func radix2(x []complex128, inverse bool) {
+	n := len(x)
+	shift := 64 - uint(bits.Len(uint(n-1)))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if j > i {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	// twiddle factors of the last stage, earlier stages use every (n/size)th one
+	twiddles := make([]complex128, n/2)
+	for k := range twiddles {
+		twiddles[k] = cmplx.Rect(1, sign*tau*float64(k)/float64(n))
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		h := size / 2
+		stride := n / size
+		for start := 0; start < n; start += size {
+			for k := 0; k < h; k++ {
+				e := twiddles[k*stride] * x[start+k+h]
+				x[start+k], x[start+k+h] = x[start+k]+e, x[start+k]-e
+			}
+		}
+	}
+}
+
+// bluestein computes an arbitrary length FFT as a convolution of power of two length.
+func bluestein(x []complex128) []complex128 {
+	n := len(x)
+	m := NextPowerOfTwo(2*n - 1)
+
+	// chirp[k] = exp(-i*pi*k^2/n), k^2 is taken mod 2n to keep the angle accurate
+	chirp := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		kk := (k * k) % (2 * n)
+		chirp[k] = cmplx.Rect(1, -math.Pi*float64(kk)/float64(n))
+	}
+
+	a := make([]complex128, m)
+	for k := 0; k < n; k++ {
+		a[k] = x[k] * chirp[k]
+	}
+	b := make([]complex128, m)
+	b[0] = cmplx.Conj(chirp[0])
+	for k := 1; k < n; k++ {
+		b[k] = cmplx.Conj(chirp[k])
+		b[m-k] = cmplx.Conj(chirp[k])
+	}
+
+	radix2(a, false)
+	radix2(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	radix2(a, true)
+
+	out := make([]complex128, n)
+	scale := complex(1/float64(m), 0)
+	for k := 0; k < n; k++ {
+		out[k] = a[k] * scale * chirp[k]
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package math
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	// both power of two lengths and lengths that need Bluestein
+	fftLengths = []int{1, 2, 4, 8, 64, 256, 3, 5, 12, 100, 255, 441}
+)
+
+func randomFrames(n int) []wave.Frame {
+	rng := rand.New(rand.NewSource(int64(n)))
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(rng.Float64()*2 - 1)
+	}
+	return out
+}
+
+func complexEquals(a, b complex128, tolerance float64) bool {
+	return cmplx.Abs(a-b) <= tolerance
+}
+
+// TestFFTAgainstDFT validates the FFT against the (slow) DFT
+func TestFFTAgainstDFT(t *testing.T) {
+	for _, n := range fftLengths {
+		t.Run("", func(t *testing.T) {
+			input := randomFrames(n)
+			fft := FFT(input)
+			// DFT is scaled by 1/N and uses the opposite sign in the exponent
+			dft := DFT(input)
+			for k := range dft {
+				expected := cmplx.Conj(dft[k]) * complex(float64(n), 0)
+				if !complexEquals(fft[k], expected, 1e-9) {
+					t.Fatalf("N=%v bin %v: expected %v, got %v", n, k, expected, fft[k])
+				}
+			}
+		})
+	}
+}
+
+// TestHFFT makes sure the recursive radix-2 step agrees with the FFT
+func TestHFFT(t *testing.T) {
+	input := randomFrames(32)
+	freqs := make([]complex128, len(input))
+	HFFT(input, freqs, len(input), 1)
+	fft := FFT(input)
+	for k := range fft {
+		if !complexEquals(freqs[k], fft[k], 1e-9) {
+			t.Fatalf("bin %v: expected %v, got %v", k, fft[k], freqs[k])
+		}
+	}
+}
+
+func TestIFFT(t *testing.T) {
+	for _, n := range fftLengths {
+		t.Run("", func(t *testing.T) {
+			input := randomFrames(n)
+			back := IFFT(FFT(input))
+			for i := range input {
+				if !complexEquals(back[i], complex(float64(input[i]), 0), 1e-9) {
+					t.Fatalf("N=%v sample %v: expected %v, got %v", n, i, input[i], back[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRFFT(t *testing.T) {
+	for _, n := range fftLengths {
+		t.Run("", func(t *testing.T) {
+			input := randomFrames(n)
+			bins := RFFT(input)
+			if len(bins) != n/2+1 {
+				t.Fatalf("Expected %v bins, got %v", n/2+1, len(bins))
+			}
+			back := IRFFT(bins, n)
+			for i := range input {
+				if math.Abs(back[i]-float64(input[i])) > 1e-9 {
+					t.Fatalf("N=%v sample %v: expected %v, got %v", n, i, input[i], back[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSineBin makes sure a sine ends up in the right bin with the right magnitude
+func TestSineBin(t *testing.T) {
+	n, bin := 1000, 50
+	input := make([]wave.Frame, n)
+	for i := range input {
+		input[i] = wave.Frame(math.Sin(tau * float64(bin*i) / float64(n)))
+	}
+	bins := RFFT(input)
+	for k, b := range bins {
+		mag := cmplx.Abs(b) * 2 / float64(n)
+		if k == bin && math.Abs(mag-1) > 1e-9 {
+			t.Fatalf("Expected magnitude 1 in bin %v, got %v", k, mag)
+		}
+		if k != bin && mag > 1e-9 {
+			t.Fatalf("Expected no energy in bin %v, got %v", k, mag)
+		}
+	}
+}
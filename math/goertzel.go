@@ -0,0 +1,78 @@
+package math
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Goertzel evaluates a single frequency of the DFT, one sample at a time.
+// This is cheaper than an FFT when only a few frequencies are of interest (e.g to detect
+// tones), and the frequency doesn't have to fall on a bin.
+type Goertzel struct {
+	omega  float64 // frequency in radians per sample
+	coeff  float64 // 2 * cos(omega)
+	s1, s2 float64 // the last two values of the filter
+	n      int     // number of samples processed
+}
+
+// NewGoertzel creates a detector for the frequency (in Hz) at the given sample rate
+func NewGoertzel(freq float64, sr int) *Goertzel {
+	omega := tau * freq / float64(sr)
+	return &Goertzel{omega: omega, coeff: 2 * math.Cos(omega)}
+}
+
+// Reset clears the state, so a new block can be analysed
+func (g *Goertzel) Reset() {
+	g.s1, g.s2, g.n = 0, 0, 0
+}
+
+// Tick processes a single sample
+func (g *Goertzel) Tick(x float64) {
+	g.s1, g.s2 = x+g.coeff*g.s1-g.s2, g.s1
+	g.n++
+}
+
+// Process processes every frame
+func (g *Goertzel) Process(frames []wave.Frame) {
+	for _, f := range frames {
+		g.Tick(float64(f))
+	}
+}
+
+// Len returns the number of samples processed since the last reset
+func (g *Goertzel) Len() int {
+	return g.n
+}
+
+// DFT returns sum(x[n] * e^(-i*omega*n)) over the processed samples, the same (unscaled)
+// definition as FFT
+func (g *Goertzel) DFT() complex128 {
+	if g.n == 0 {
+		return 0
+	}
+	// the filter output lags the DFT by the phase of the last sample
+	y := complex(g.s1, 0) - cmplx.Exp(complex(0, -g.omega))*complex(g.s2, 0)
+	return y * cmplx.Exp(complex(0, -g.omega*float64(g.n-1)))
+}
+
+// Power returns |DFT|², which doesn't need the phase correction
+func (g *Goertzel) Power() float64 {
+	return g.s1*g.s1 + g.s2*g.s2 - g.coeff*g.s1*g.s2
+}
+
+// Amplitude returns the amplitude of a sinusoid at the frequency, 1 being full scale
+func (g *Goertzel) Amplitude() float64 {
+	if g.n == 0 {
+		return 0
+	}
+	return 2 * math.Sqrt(math.Max(g.Power(), 0)) / float64(g.n)
+}
+
+// GoertzelPower returns |DFT|² of the frames at a single frequency (in Hz)
+func GoertzelPower(frames []wave.Frame, freq float64, sr int) float64 {
+	g := NewGoertzel(freq, sr)
+	g.Process(frames)
+	return g.Power()
+}
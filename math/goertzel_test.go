@@ -0,0 +1,71 @@
+package math
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+func TestGoertzelAgainstFFT(t *testing.T) {
+	input := randomFrames(256)
+	fft := FFT(input)
+	for _, k := range []int{0, 1, 17, 100, 128} {
+		g := NewGoertzel(float64(k), 256)
+		g.Process(input)
+		if cmplx.Abs(g.DFT()-fft[k]) > 1e-9 {
+			t.Fatalf("Bin %v: expected %v, got %v", k, fft[k], g.DFT())
+		}
+		if p := cmplx.Abs(fft[k]) * cmplx.Abs(fft[k]); math.Abs(g.Power()-p) > 1e-6 {
+			t.Fatalf("Bin %v: expected a power of %v, got %v", k, p, g.Power())
+		}
+	}
+}
+
+// TestGoertzelBetweenBins makes sure frequencies that don't fall on a bin match the
+// DFT definition
+func TestGoertzelBetweenBins(t *testing.T) {
+	input := randomFrames(205)
+	freq, sr := 697.0, 8000
+	expected := complex(0, 0)
+	for n, x := range input {
+		expected += complex(float64(x), 0) * cmplx.Exp(complex(0, -tau*freq*float64(n)/float64(sr)))
+	}
+	g := NewGoertzel(freq, sr)
+	g.Process(input)
+	if cmplx.Abs(g.DFT()-expected) > 1e-9 {
+		t.Fatalf("Expected %v, got %v", expected, g.DFT())
+	}
+	if p := GoertzelPower(input, freq, sr); math.Abs(p-g.Power()) > 1e-9 {
+		t.Fatalf("Expected a power of %v, got %v", g.Power(), p)
+	}
+}
+
+func TestGoertzelAmplitude(t *testing.T) {
+	sr := 8000
+	input := make([]wave.Frame, 400)
+	for i := range input {
+		input[i] = wave.Frame(0.3 * math.Sin(tau*1000*float64(i)/float64(sr)))
+	}
+	g := NewGoertzel(1000, sr)
+	g.Process(input)
+	if a := g.Amplitude(); math.Abs(a-0.3) > 1e-6 {
+		t.Fatalf("Expected an amplitude of 0.3, got %v", a)
+	}
+	if g.Len() != 400 {
+		t.Fatalf("Expected 400 samples, got %v", g.Len())
+	}
+
+	// an other frequency at a whole number of periods doesn't leak into the bin
+	other := NewGoertzel(1200, sr)
+	other.Process(input)
+	if a := other.Amplitude(); a > 1e-6 {
+		t.Fatalf("Expected nothing at 1200 Hz, got %v", a)
+	}
+
+	g.Reset()
+	if g.Power() != 0 || g.Len() != 0 || g.DFT() != 0 {
+		t.Fatalf("Expected an empty detector after Reset")
+	}
+}
@@ -0,0 +1,196 @@
+package math
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// Spectrum is a one-sided spectrum with its bins labelled in Hz
+type Spectrum struct {
+	Frequencies []float64 // centre frequency of each bin in Hz
+	Values      []float64
+	// Density is true when Values hold a power spectral density (FS²/Hz),
+	// otherwise Values hold the amplitude of sinusoids (FS, 1 being full scale)
+	Density bool
+}
+
+// DB returns the values in decibels: dB/Hz (relative to 1 FS²/Hz) for a density,
+// dBFS for amplitudes (a full scale sine reads 0 dBFS).
+func (s Spectrum) DB() []float64 {
+	out := make([]float64, len(s.Values))
+	for i, v := range s.Values {
+		if s.Density {
+			out[i] = 10 * math.Log10(v)
+		} else {
+			out[i] = 20 * math.Log10(v)
+		}
+	}
+	return out
+}
+
+// PSDConfig describes how a signal is cut into segments to be averaged
+type PSDConfig struct {
+	SegmentSize int       // samples per segment
+	Overlap     int       // samples shared by consecutive segments
+	Window      []float64 // window of SegmentSize samples, periodic Hann if nil
+	FFTSize     int       // segments are zero padded up to FFTSize, SegmentSize if 0
+}
+
+// validate checks the config and fills in the defaults
+func (c PSDConfig) validate() (PSDConfig, error) {
+	if c.SegmentSize <= 0 {
+		return c, errors.New("SegmentSize should be positive")
+	}
+	if c.Overlap < 0 || c.Overlap >= c.SegmentSize {
+		return c, errors.New("Overlap should be between 0 and SegmentSize")
+	}
+	if c.FFTSize == 0 {
+		c.FFTSize = c.SegmentSize
+	}
+	if c.FFTSize < c.SegmentSize {
+		return c, errors.New("FFTSize can't be smaller than SegmentSize")
+	}
+	if c.Window == nil {
+		c.Window = window.Hann(c.SegmentSize, window.PERIODIC)
+	}
+	if len(c.Window) != c.SegmentSize {
+		return c, errors.New("Window should be SegmentSize samples long")
+	}
+	return c, nil
+}
+
+// BinFrequencies returns the centre frequency in Hz of the fftSize/2+1 bins of an RFFT
+func BinFrequencies(fftSize, sr int) []float64 {
+	out := make([]float64, fftSize/2+1)
+	for k := range out {
+		out[k] = float64(k) * float64(sr) / float64(fftSize)
+	}
+	return out
+}
+
+// Welch estimates the one-sided power spectral density by averaging the periodograms
+// of overlapping, windowed segments.
+func Welch(frames []wave.Frame, sr int, cfg PSDConfig) (Spectrum, error) {
+	cfg, err := cfg.validate()
+	if err != nil {
+		return Spectrum{}, err
+	}
+	if sr <= 0 {
+		return Spectrum{}, errors.New("Need a positive sample rate")
+	}
+
+	squares := 0.0
+	for _, w := range cfg.Window {
+		squares += w * w
+	}
+	scale := 1 / (float64(sr) * squares)
+
+	sum := make([]float64, cfg.FFTSize/2+1)
+	n := 0
+	eachSegment(frames, cfg, func(bins []complex128) {
+		for k, b := range bins {
+			p := real(b)*real(b) + imag(b)*imag(b)
+			sum[k] += p * scale * oneSided(k, cfg.FFTSize)
+		}
+		n++
+	})
+	for k := range sum {
+		sum[k] /= float64(n)
+	}
+	return Spectrum{
+		Frequencies: BinFrequencies(cfg.FFTSize, sr),
+		Values:      sum,
+		Density:     true,
+	}, nil
+}
+
+// Bartlett estimates the power spectral density by averaging the periodograms of
+// adjacent segments without windowing.
+func Bartlett(frames []wave.Frame, sr, segmentSize int) (Spectrum, error) {
+	return Welch(frames, sr, PSDConfig{
+		SegmentSize: segmentSize,
+		Window:      window.Rectangular(segmentSize),
+	})
+}
+
+// AveragedSpectrum estimates the amplitude of sinusoids in the signal by averaging the
+// power of each bin over the segments. The peak-hold spectrum holds the largest amplitude
+// any segment had in each bin.
+func AveragedSpectrum(frames []wave.Frame, sr int, cfg PSDConfig) (avg, peak Spectrum, err error) {
+	cfg, err = cfg.validate()
+	if err != nil {
+		return Spectrum{}, Spectrum{}, err
+	}
+	if sr <= 0 {
+		return Spectrum{}, Spectrum{}, errors.New("Need a positive sample rate")
+	}
+
+	gain := 0.0
+	for _, w := range cfg.Window {
+		gain += w
+	}
+
+	sum := make([]float64, cfg.FFTSize/2+1)
+	max := make([]float64, cfg.FFTSize/2+1)
+	n := 0
+	eachSegment(frames, cfg, func(bins []complex128) {
+		for k, b := range bins {
+			amp := cmplx.Abs(b) / gain * oneSided(k, cfg.FFTSize)
+			sum[k] += amp * amp
+			if amp > max[k] {
+				max[k] = amp
+			}
+		}
+		n++
+	})
+	for k := range sum {
+		sum[k] = math.Sqrt(sum[k] / float64(n))
+	}
+
+	freqs := BinFrequencies(cfg.FFTSize, sr)
+	return Spectrum{Frequencies: freqs, Values: sum},
+		Spectrum{Frequencies: freqs, Values: max}, nil
+}
+
+// WelchWave estimates the power spectral density of each channel of the wave
+func WelchWave(w wave.Wave, cfg PSDConfig) ([]Spectrum, error) {
+	channels := wave.SplitChannels(w.Frames, w.NumChannels)
+	out := make([]Spectrum, len(channels))
+	for c, frames := range channels {
+		s, err := Welch(frames, w.SampleRate, cfg)
+		if err != nil {
+			return nil, err
+		}
+		out[c] = s
+	}
+	return out, nil
+}
+
+// eachSegment calls f with the RFFT of each windowed segment.
+// A trailing partial segment is dropped, unless the signal is shorter than a segment.
+func eachSegment(frames []wave.Frame, cfg PSDConfig, f func([]complex128)) {
+	step := cfg.SegmentSize - cfg.Overlap
+	buf := make([]complex128, cfg.FFTSize)
+	for start := 0; start == 0 || start+cfg.SegmentSize <= len(frames); start += step {
+		for i := range buf {
+			buf[i] = 0
+		}
+		for i := 0; i < cfg.SegmentSize && start+i < len(frames); i++ {
+			buf[i] = complex(float64(frames[start+i])*cfg.Window[i], 0)
+		}
+		f(ComplexFFT(buf)[:cfg.FFTSize/2+1])
+	}
+}
+
+// oneSided is the factor to fold the negative frequencies of bin k onto the positive ones.
+// DC and Nyquist have no negative counterpart.
+func oneSided(k, n int) float64 {
+	if k == 0 || (n%2 == 0 && k == n/2) {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,100 @@
+package math
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+func noise(n int, stddev float64) []wave.Frame {
+	rng := rand.New(rand.NewSource(42))
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(rng.NormFloat64() * stddev)
+	}
+	return out
+}
+
+// TestWelchWhiteNoise checks the level of white noise, and that the PSD integrates to its power
+func TestWelchWhiteNoise(t *testing.T) {
+	sr, stddev := 48000, 0.1
+	frames := noise(sr*4, stddev)
+	estimators := map[string]func() (Spectrum, error){
+		"welch":    func() (Spectrum, error) { return Welch(frames, sr, PSDConfig{SegmentSize: 1024, Overlap: 512}) },
+		"bartlett": func() (Spectrum, error) { return Bartlett(frames, sr, 1024) },
+	}
+	for name, estimate := range estimators {
+		t.Run(name, func(t *testing.T) {
+			psd, err := estimate()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(psd.Frequencies) != 513 || psd.Frequencies[512] != 24000 {
+				t.Fatalf("Unexpected frequencies, %v bins up to %v", len(psd.Frequencies), psd.Frequencies[len(psd.Frequencies)-1])
+			}
+			// one-sided white noise density is 2*variance/sr
+			expected := 10 * math.Log10(2*stddev*stddev/float64(sr))
+			db := psd.DB()
+			for k := 10; k < len(db)-10; k += 50 {
+				if math.Abs(db[k]-expected) > 1.5 {
+					t.Fatalf("Bin %v: expected %v dB/Hz, got %v", k, expected, db[k])
+				}
+			}
+			power := 0.0
+			df := psd.Frequencies[1] - psd.Frequencies[0]
+			for _, v := range psd.Values {
+				power += v * df
+			}
+			if math.Abs(power-stddev*stddev)/(stddev*stddev) > 0.05 {
+				t.Fatalf("Expected total power %v, got %v", stddev*stddev, power)
+			}
+		})
+	}
+}
+
+// TestAveragedSpectrum measures the level of a sine on top of noise
+func TestAveragedSpectrum(t *testing.T) {
+	sr := 8000
+	frames := noise(sr*2, 0.001)
+	for i := range frames {
+		frames[i] += wave.Frame(0.5 * math.Sin(tau*1000*float64(i)/float64(sr)))
+	}
+	// a burst in the second half only shows up in the peak-hold spectrum at full level
+	for i := 16 * 512; i < 17*512; i++ {
+		frames[i] += wave.Frame(0.25 * math.Sin(tau*2000*float64(i)/float64(sr)))
+	}
+
+	avg, peak, err := AveragedSpectrum(frames, sr, PSDConfig{SegmentSize: 512, Window: ones(512)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bin1k, bin2k := 64, 128
+	if avg.Frequencies[bin1k] != 1000 {
+		t.Fatalf("Expected bin %v to be 1000 Hz, got %v", bin1k, avg.Frequencies[bin1k])
+	}
+	if db := avg.DB()[bin1k]; math.Abs(db-20*math.Log10(0.5)) > 0.1 {
+		t.Fatalf("Expected the sine at -6 dBFS, got %v", db)
+	}
+	if db := peak.DB()[bin2k]; math.Abs(db-20*math.Log10(0.25)) > 0.1 {
+		t.Fatalf("Expected the burst to peak at -12 dBFS, got %v", db)
+	}
+	if avg.Values[bin2k] >= peak.Values[bin2k] {
+		t.Fatal("Expected the average to be below the peak")
+	}
+}
+
+func TestWelchWave(t *testing.T) {
+	w := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, 44100, 16, nil),
+		WaveData: wave.WaveData{Frames: noise(2*4096, 0.1)},
+	}
+	spectra, err := WelchWave(w, PSDConfig{SegmentSize: 256})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(spectra) != 2 || spectra[1].Frequencies[128] != 22050 {
+		t.Fatalf("Unexpected spectra for %v channels", len(spectra))
+	}
+}
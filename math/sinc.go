@@ -0,0 +1,12 @@
+package math
+
+import "math"
+
+// Sinc returns the normalised sinc function sin(πx) / (πx), which is 1 at 0.
+// It is the impulse response of an ideal lowpass filter.
+func Sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
@@ -0,0 +1,20 @@
+package math
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSinc(t *testing.T) {
+	if Sinc(0) != 1 {
+		t.Fatalf("Expected 1 at 0, got %v", Sinc(0))
+	}
+	for _, x := range []float64{1, -2, 3} {
+		if math.Abs(Sinc(x)) > 1e-15 {
+			t.Fatalf("Expected 0 at %v, got %v", x, Sinc(x))
+		}
+	}
+	if s := Sinc(0.5); math.Abs(s-2/math.Pi) > 1e-15 {
+		t.Fatalf("Expected 2/π at 0.5, got %v", s)
+	}
+}
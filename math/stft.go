@@ -0,0 +1,161 @@
+package math
+
+import (
+	"errors"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// STFTConfig describes how a signal is cut into frames for the short-time Fourier transform
+type STFTConfig struct {
+	FrameSize int       // number of samples per frame
+	HopSize   int       // number of samples between the start of two frames
+	Window    []float64 // analysis window of FrameSize samples, periodic Hann if nil
+	FFTSize   int       // frames are zero padded up to FFTSize, FrameSize if 0
+}
+
+// validate checks the config and fills in the defaults
+func (c STFTConfig) validate() (STFTConfig, error) {
+	if c.FrameSize <= 0 {
+		return c, errors.New("FrameSize should be positive")
+	}
+	if c.HopSize <= 0 || c.HopSize > c.FrameSize {
+		return c, errors.New("HopSize should be between 1 and FrameSize")
+	}
+	if c.FFTSize == 0 {
+		c.FFTSize = c.FrameSize
+	}
+	if c.FFTSize < c.FrameSize {
+		return c, errors.New("FFTSize can't be smaller than FrameSize")
+	}
+	if c.Window == nil {
+		c.Window = window.Hann(c.FrameSize, window.PERIODIC)
+	}
+	if len(c.Window) != c.FrameSize {
+		return c, errors.New("Window should be FrameSize samples long")
+	}
+	return c, nil
+}
+
+// Bins returns the number of frequency bins in each frame of the STFT
+func (c STFTConfig) Bins() int {
+	if c.FFTSize == 0 {
+		return c.FrameSize/2 + 1
+	}
+	return c.FFTSize/2 + 1
+}
+
+// STFT computes the short-time Fourier transform of a single channel.
+// The result is a time x frequency matrix: one row per frame holding FFTSize/2+1 bins.
+// The signal is padded by FrameSize/2 on either side, so frame m is centred on
+// sample m*HopSize.
+func STFT(input []wave.Frame, cfg STFTConfig) ([][]complex128, error) {
+	cfg, err := cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	pad := cfg.FrameSize / 2
+	nframes := stftFrames(len(input), cfg)
+	out := make([][]complex128, nframes)
+	buf := make([]complex128, cfg.FFTSize)
+	for m := range out {
+		start := m*cfg.HopSize - pad
+		for i := range buf {
+			buf[i] = 0
+		}
+		for i := 0; i < cfg.FrameSize; i++ {
+			j := start + i
+			if j >= 0 && j < len(input) {
+				buf[i] = complex(float64(input[j])*cfg.Window[i], 0)
+			}
+		}
+		out[m] = ComplexFFT(buf)[:cfg.Bins()]
+	}
+	return out, nil
+}
+
+// ISTFT inverts the STFT by weighted overlap-add, returning length samples.
+// Without modifications to the spectrum the original signal is reconstructed exactly
+// (up to rounding), for any window that doesn't leave gaps between frames.
+func ISTFT(spectrum [][]complex128, cfg STFTConfig, length int) ([]wave.Frame, error) {
+	cfg, err := cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	pad := cfg.FrameSize / 2
+	total := (len(spectrum)-1)*cfg.HopSize + cfg.FrameSize
+	if total < 0 {
+		total = 0
+	}
+	acc := make([]float64, total)
+	norm := make([]float64, total)
+	for m, bins := range spectrum {
+		if len(bins) != cfg.Bins() {
+			return nil, errors.New("Every frame should have FFTSize/2+1 bins")
+		}
+		frame := IRFFT(bins, cfg.FFTSize)
+		start := m * cfg.HopSize
+		for i := 0; i < cfg.FrameSize; i++ {
+			w := cfg.Window[i]
+			acc[start+i] += frame[i] * w
+			norm[start+i] += w * w
+		}
+	}
+
+	out := make([]wave.Frame, length)
+	for i := range out {
+		j := i + pad
+		if j >= total || norm[j] < 1e-10 {
+			continue
+		}
+		out[i] = wave.Frame(acc[j] / norm[j])
+	}
+	return out, nil
+}
+
+// STFTWave computes the STFT of each channel of the wave
+func STFTWave(w wave.Wave, cfg STFTConfig) ([][][]complex128, error) {
+	channels := wave.SplitChannels(w.Frames, w.NumChannels)
+	out := make([][][]complex128, len(channels))
+	for c, frames := range channels {
+		s, err := STFT(frames, cfg)
+		if err != nil {
+			return nil, err
+		}
+		out[c] = s
+	}
+	return out, nil
+}
+
+// ISTFTWave turns the per-channel spectra back into a wave with the given fmt
+// and number of samples per channel.
+func ISTFTWave(spectra [][][]complex128, cfg STFTConfig, wfmt wave.WaveFmt, length int) (wave.Wave, error) {
+	channels := make([][]wave.Frame, len(spectra))
+	for c, s := range spectra {
+		frames, err := ISTFT(s, cfg, length)
+		if err != nil {
+			return wave.Wave{}, err
+		}
+		channels[c] = frames
+	}
+	wfmt.SetChannels(uint(len(spectra)))
+	return wave.Wave{
+		WaveFmt:  wfmt,
+		WaveData: wave.WaveData{Frames: wave.JoinChannels(channels)},
+	}, nil
+}
+
+// stftFrames returns how many frames are needed to cover n samples, with a frame centred
+// on or after the last sample.
+func stftFrames(n int, cfg STFTConfig) int {
+	centred := n/cfg.HopSize + 1
+	rest := n + cfg.FrameSize/2 - cfg.FrameSize
+	covering := (rest+cfg.HopSize-1)/cfg.HopSize + 1
+	if covering > centred {
+		return covering
+	}
+	return centred
+}
@@ -0,0 +1,119 @@
+package math
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	stftReconstructionTests = []STFTConfig{
+		{FrameSize: 256, HopSize: 64},
+		{FrameSize: 256, HopSize: 128, FFTSize: 1024},
+		{FrameSize: 100, HopSize: 30},
+		{FrameSize: 64, HopSize: 64, Window: ones(64)},
+	}
+)
+
+func ones(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+// TestSTFTReconstruction makes sure the ISTFT undoes the STFT
+func TestSTFTReconstruction(t *testing.T) {
+	for _, cfg := range stftReconstructionTests {
+		t.Run("", func(t *testing.T) {
+			input := randomFrames(1000)
+			spectrum, err := STFT(input, cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, bins := range spectrum {
+				if len(bins) != cfg.Bins() {
+					t.Fatalf("Expected %v bins, got %v", cfg.Bins(), len(bins))
+				}
+			}
+			out, err := ISTFT(spectrum, cfg, len(input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i := range input {
+				if math.Abs(float64(out[i]-input[i])) > 1e-9 {
+					t.Fatalf("Sample %v: expected %v, got %v", i, input[i], out[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSTFTSine checks that every frame of a sine peaks in the same bin
+func TestSTFTSine(t *testing.T) {
+	sr, freq := 8000, 1000.0
+	input := make([]wave.Frame, sr)
+	for i := range input {
+		input[i] = wave.Frame(math.Sin(tau * freq * float64(i) / float64(sr)))
+	}
+	cfg := STFTConfig{FrameSize: 256, HopSize: 128, FFTSize: 512}
+	spectrum, err := STFT(input, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := int(freq * float64(cfg.FFTSize) / float64(sr))
+	// skip the padded frames at the edges
+	for m := 2; m < len(spectrum)-2; m++ {
+		peak := 0
+		for k := range spectrum[m] {
+			if cmplx.Abs(spectrum[m][k]) > cmplx.Abs(spectrum[m][peak]) {
+				peak = k
+			}
+		}
+		if peak != expected {
+			t.Fatalf("Frame %v: expected peak in bin %v, got %v", m, expected, peak)
+		}
+	}
+}
+
+func TestSTFTWave(t *testing.T) {
+	w := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, 44100, 16, nil),
+		WaveData: wave.WaveData{Frames: randomFrames(2 * 500)},
+	}
+	cfg := STFTConfig{FrameSize: 128, HopSize: 32}
+	spectra, err := STFTWave(w, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	back, err := ISTFTWave(spectra, cfg, w.WaveFmt, 500)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if back.NumChannels != 2 || len(back.Frames) != len(w.Frames) {
+		t.Fatalf("Unexpected shape: %v channels, %v frames", back.NumChannels, len(back.Frames))
+	}
+	for i := range w.Frames {
+		if math.Abs(float64(back.Frames[i]-w.Frames[i])) > 1e-9 {
+			t.Fatalf("Sample %v: expected %v, got %v", i, w.Frames[i], back.Frames[i])
+		}
+	}
+}
+
+func TestSTFTInvalidConfig(t *testing.T) {
+	configs := []STFTConfig{
+		{FrameSize: 0, HopSize: 1},
+		{FrameSize: 64, HopSize: 0},
+		{FrameSize: 64, HopSize: 65},
+		{FrameSize: 64, HopSize: 16, FFTSize: 32},
+		{FrameSize: 64, HopSize: 16, Window: ones(32)},
+	}
+	for _, cfg := range configs {
+		if _, err := STFT(randomFrames(100), cfg); err == nil {
+			t.Fatalf("Expected an error for %+v", cfg)
+		}
+	}
+}
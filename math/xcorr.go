@@ -0,0 +1,93 @@
+package math
+
+import (
+	"errors"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// CrossCorrelate returns c[lag] = sum(a[n] * b[n+lag]) for every lag where the signals
+// overlap, computed with the FFT.
+// The result has len(a)+len(b)-1 values, index i holds lag i-(len(a)-1). So a peak at
+// lag d means b is a copy of a delayed by d samples.
+func CrossCorrelate(a, b []wave.Frame) []float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return []float64{}
+	}
+	size := len(a) + len(b) - 1
+	n := NextPowerOfTwo(size)
+	fa := ComplexFFT(framesToComplex(a, n))
+	fb := ComplexFFT(framesToComplex(b, n))
+	for k := range fa {
+		fa[k] = cmplx.Conj(fa[k]) * fb[k]
+	}
+	circular := IFFT(fa)
+
+	// negative lags wrapped around to the end of the circular correlation
+	out := make([]float64, size)
+	for i := range out {
+		lag := i - (len(a) - 1)
+		out[i] = real(circular[(lag+n)%n])
+	}
+	return out
+}
+
+// AutoCorrelate returns r[k] = sum(a[n] * a[n+k]) for the non-negative lags 0..len(a)-1
+func AutoCorrelate(a []wave.Frame) []float64 {
+	if len(a) == 0 {
+		return []float64{}
+	}
+	return CrossCorrelate(a, a)[len(a)-1:]
+}
+
+// EstimateLag estimates by how many samples (per channel) wave b lags behind wave a.
+// Channels are mixed to mono first. The peak of the cross-correlation is refined with
+// parabolic interpolation for sub-sample accuracy.
+// Only lags up to maxLag samples are considered, unless maxLag <= 0.
+func EstimateLag(a, b wave.Wave, maxLag int) (float64, error) {
+	if a.SampleRate != b.SampleRate {
+		return 0, errors.New("Waves should have the same sample rate")
+	}
+	ma, mb := wave.MixToMono(a.Frames, a.NumChannels), wave.MixToMono(b.Frames, b.NumChannels)
+	if len(ma) == 0 || len(mb) == 0 {
+		return 0, errors.New("Waves should not be empty")
+	}
+
+	corr := CrossCorrelate(ma, mb)
+	zero := len(ma) - 1
+	best := -1
+	for i, c := range corr {
+		lag := i - zero
+		if maxLag > 0 && (lag > maxLag || lag < -maxLag) {
+			continue
+		}
+		if best < 0 || c > corr[best] {
+			best = i
+		}
+	}
+	return float64(best-zero) + ParabolicOffset(corr, best), nil
+}
+
+// ParabolicOffset returns the offset of the true peak (or valley) from index i, by fitting
+// a parabola through i and its neighbours. It is between -0.5 and 0.5 when i is a local
+// extremum, and 0 at the edges.
+func ParabolicOffset(xs []float64, i int) float64 {
+	if i <= 0 || i >= len(xs)-1 {
+		return 0
+	}
+	l, c, r := xs[i-1], xs[i], xs[i+1]
+	denom := l - 2*c + r
+	if denom == 0 {
+		return 0
+	}
+	return 0.5 * (l - r) / denom
+}
+
+func framesToComplex(frames []wave.Frame, n int) []complex128 {
+	out := make([]complex128, n)
+	for i, f := range frames {
+		out[i] = complex(float64(f), 0)
+	}
+	return out
+}
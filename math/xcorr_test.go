@@ -0,0 +1,100 @@
+package math
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// directCorrelate is the textbook cross-correlation, c[lag] = sum(a[n] * b[n+lag])
+func directCorrelate(a, b []wave.Frame, lag int) float64 {
+	sum := 0.0
+	for n := range a {
+		if n+lag >= 0 && n+lag < len(b) {
+			sum += float64(a[n] * b[n+lag])
+		}
+	}
+	return sum
+}
+
+func TestCrossCorrelate(t *testing.T) {
+	a, b := randomFrames(37), randomFrames(100)
+	corr := CrossCorrelate(a, b)
+	if len(corr) != len(a)+len(b)-1 {
+		t.Fatalf("Expected %v values, got %v", len(a)+len(b)-1, len(corr))
+	}
+	for i, c := range corr {
+		expected := directCorrelate(a, b, i-(len(a)-1))
+		if math.Abs(c-expected) > 1e-9 {
+			t.Fatalf("Lag %v: expected %v, got %v", i-(len(a)-1), expected, c)
+		}
+	}
+}
+
+func TestAutoCorrelate(t *testing.T) {
+	a := randomFrames(64)
+	auto := AutoCorrelate(a)
+	for k, r := range auto {
+		if expected := directCorrelate(a, a, k); math.Abs(r-expected) > 1e-9 {
+			t.Fatalf("Lag %v: expected %v, got %v", k, expected, r)
+		}
+	}
+}
+
+// delayedNoise returns band-limited noise, and a copy delayed by a fractional number of samples
+func delayedNoise(n int, delay float64) ([]wave.Frame, []wave.Frame) {
+	freqs := []float64{0.013, 0.031, 0.047, 0.071, 0.11, 0.17}
+	a, b := make([]wave.Frame, n), make([]wave.Frame, n)
+	for i := range a {
+		for j, f := range freqs {
+			a[i] += wave.Frame(math.Sin(tau*f*float64(i) + float64(j)))
+			b[i] += wave.Frame(math.Sin(tau*f*(float64(i)-delay) + float64(j)))
+		}
+	}
+	return a, b
+}
+
+func TestEstimateLag(t *testing.T) {
+	delays := []float64{0, 12, -7, 30.5, -3.25}
+	for _, delay := range delays {
+		t.Run("", func(t *testing.T) {
+			a, b := delayedNoise(2000, delay)
+			wa := wave.Wave{WaveFmt: wave.NewWaveFmt(1, 1, 8000, 16, nil), WaveData: wave.WaveData{Frames: a}}
+			wb := wave.Wave{WaveFmt: wave.NewWaveFmt(1, 1, 8000, 16, nil), WaveData: wave.WaveData{Frames: b}}
+			lag, err := EstimateLag(wa, wb, 100)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(lag-delay) > 0.1 {
+				t.Fatalf("Expected lag %v, got %v", delay, lag)
+			}
+		})
+	}
+}
+
+func TestEstimateLagSampleRate(t *testing.T) {
+	a := wave.Wave{WaveFmt: wave.NewWaveFmt(1, 1, 8000, 16, nil), WaveData: wave.WaveData{Frames: randomFrames(10)}}
+	b := wave.Wave{WaveFmt: wave.NewWaveFmt(1, 1, 44100, 16, nil), WaveData: wave.WaveData{Frames: randomFrames(10)}}
+	if _, err := EstimateLag(a, b, 0); err == nil {
+		t.Fatal("Expected an error for different sample rates")
+	}
+}
+
+func TestParabolicOffset(t *testing.T) {
+	// samples of a parabola with its peak at 2.3, and a valley at 1.8
+	peak, valley := make([]float64, 5), make([]float64, 5)
+	for i := range peak {
+		peak[i] = -(float64(i) - 2.3) * (float64(i) - 2.3)
+		valley[i] = (float64(i) - 1.8) * (float64(i) - 1.8)
+	}
+	if o := ParabolicOffset(peak, 2); math.Abs(o-0.3) > 1e-12 {
+		t.Fatalf("Expected an offset of 0.3, got %v", o)
+	}
+	if o := ParabolicOffset(valley, 2); math.Abs(o+0.2) > 1e-12 {
+		t.Fatalf("Expected an offset of -0.2, got %v", o)
+	}
+	if o := ParabolicOffset(peak, 0); o != 0 {
+		t.Fatalf("Expected no offset at the edge, got %v", o)
+	}
+}
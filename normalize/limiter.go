@@ -0,0 +1,103 @@
+package normalize
+
+import (
+	"errors"
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// LimiterOptions configure the look-ahead limiter.
+// Zero values for the times are replaced by the defaults.
+type LimiterOptions struct {
+	Ceiling   float64 // highest sample peak in dBFS
+	LookAhead float64 // seconds over which the gain ramps down ahead of a peak, default 5 ms
+	Release   float64 // time constant in seconds of the gain recovering, default 50 ms
+}
+
+// Limit keeps the sample peaks of interleaved frames at or below the ceiling.
+// The gain is the same for all channels, so the stereo image doesn't shift. It starts to
+// drop LookAhead before a peak and recovers with the Release time constant, which avoids
+// the distortion of clipping.
+func Limit(frames []wave.Frame, channels, sr int, opts LimiterOptions) ([]wave.Frame, error) {
+	if channels <= 0 || sr <= 0 {
+		return nil, errors.New("Channels and sample rate should be positive")
+	}
+	if opts.LookAhead == 0 {
+		opts.LookAhead = 0.005
+	}
+	if opts.Release == 0 {
+		opts.Release = 0.05
+	}
+	if opts.LookAhead < 0 || opts.Release < 0 {
+		return nil, errors.New("Look-ahead and release should be positive")
+	}
+	ceiling := math.Pow(10, opts.Ceiling/20)
+	n := len(frames) / channels
+	window := int(opts.LookAhead * float64(sr))
+	if window < 1 {
+		window = 1
+	}
+
+	// the gain each frame needs by itself
+	required := make([]float64, n)
+	for i := range required {
+		required[i] = 1
+		for _, f := range frames[i*channels : (i+1)*channels] {
+			if a := math.Abs(float64(f)); a*required[i] > ceiling {
+				required[i] = ceiling / a
+			}
+		}
+	}
+
+	// the lowest gain required within the look-ahead window, held until the window
+	// passed and then released
+	hold := slidingMin(required, window)
+	release := math.Exp(-1 / (opts.Release * float64(sr)))
+	prev := 1.0
+	for i, g := range hold {
+		if g > prev {
+			g = prev + (g-prev)*(1-release)
+		}
+		hold[i], prev = g, g
+	}
+
+	// averaging over the window turns the steps into ramps that still reach the
+	// required gain at the peak, as every value in the window is at or below it
+	out := make([]wave.Frame, len(frames))
+	// before the start the gain is 1
+	sum := float64(window)
+	for i := range hold {
+		sum += hold[i]
+		if i >= window {
+			sum -= hold[i-window]
+		} else {
+			sum--
+		}
+		gain := sum / float64(window)
+		for c := 0; c < channels; c++ {
+			out[i*channels+c] = frames[i*channels+c] * wave.Frame(gain)
+		}
+	}
+	// a trailing partial frame is passed as is
+	copy(out[n*channels:], frames[n*channels:])
+	return out, nil
+}
+
+// slidingMin returns the minimum of xs[i:i+window] for every i
+func slidingMin(xs []float64, window int) []float64 {
+	out := make([]float64, len(xs))
+	// indices of increasing values, the front is the minimum of the window
+	deque := []int{}
+	for i := len(xs) - 1; i >= 0; i-- {
+		for len(deque) > 0 && xs[deque[len(deque)-1]] >= xs[i] {
+			deque = deque[:len(deque)-1]
+		}
+		deque = append(deque, i)
+		if deque[0] >= i+window {
+			deque = deque[1:]
+		}
+		out[i] = xs[deque[0]]
+	}
+	return out
+}
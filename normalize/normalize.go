@@ -0,0 +1,189 @@
+// Package normalize changes the gain of audio so it reaches a target loudness or peak level.
+package normalize
+
+import (
+	"errors"
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/loudness"
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Mode is the measurement used to normalize
+type Mode int
+
+const (
+	// LUFS normalizes the integrated loudness (ITU-R BS.1770)
+	LUFS Mode = iota
+	// RMS normalizes the root mean square level over all channels, in dBFS
+	RMS
+	// PEAK normalizes the highest sample, in dBFS
+	PEAK
+	// TRUEPEAK normalizes the highest (oversampled) true peak, in dBTP
+	TRUEPEAK
+)
+
+// Modes maps names to the normalization modes
+var Modes = map[string]Mode{
+	"lufs":     LUFS,
+	"rms":      RMS,
+	"peak":     PEAK,
+	"truepeak": TRUEPEAK,
+}
+
+// Options describe the normalization
+type Options struct {
+	Mode    Mode
+	Target  float64         // level to reach, in the unit of the mode
+	Limiter *LimiterOptions // keeps the peaks below a ceiling after applying the gain, if not nil
+}
+
+// Level measures the wave in the unit of the mode
+func Level(w wave.Wave, mode Mode) (float64, error) {
+	switch mode {
+	case LUFS, TRUEPEAK:
+		m, err := loudness.NewMeter(w.NumChannels, w.SampleRate, nil)
+		if err != nil {
+			return 0, err
+		}
+		m.Process(w.Frames)
+		if mode == LUFS {
+			return m.Integrated(), nil
+		}
+		return audiomath.Max(m.TruePeak()), nil
+	case RMS:
+		return rms(w.Frames), nil
+	case PEAK:
+		return peak(w.Frames), nil
+	}
+	return 0, errors.New("Unknown normalization mode")
+}
+
+// Gain returns the gain in dB that brings the wave to the target level
+func Gain(w wave.Wave, opts Options) (float64, error) {
+	level, err := Level(w, opts.Mode)
+	if err != nil {
+		return 0, err
+	}
+	return gainFor(level, opts.Target)
+}
+
+// AlbumGain returns a single gain in dB for a set of waves, so that the album as a whole
+// reaches the target. Their relative levels stay the same.
+func AlbumGain(ws []wave.Wave, opts Options) (float64, error) {
+	if len(ws) == 0 {
+		return 0, errors.New("Need at least one wave")
+	}
+	var level float64
+	switch opts.Mode {
+	case LUFS:
+		meters := make([]*loudness.Meter, len(ws))
+		for i, w := range ws {
+			m, err := loudness.NewMeter(w.NumChannels, w.SampleRate, nil)
+			if err != nil {
+				return 0, err
+			}
+			m.Process(w.Frames)
+			meters[i] = m
+		}
+		level = loudness.IntegratedAll(meters...)
+	case RMS:
+		sum, n := 0.0, 0
+		for _, w := range ws {
+			for _, f := range w.Frames {
+				sum += float64(f) * float64(f)
+			}
+			n += len(w.Frames)
+		}
+		level = audiomath.AmplitudeToDB(math.Sqrt(sum / float64(n)))
+	case PEAK, TRUEPEAK:
+		level = math.Inf(-1)
+		for _, w := range ws {
+			l, err := Level(w, opts.Mode)
+			if err != nil {
+				return 0, err
+			}
+			level = math.Max(level, l)
+		}
+	default:
+		return 0, errors.New("Unknown normalization mode")
+	}
+	return gainFor(level, opts.Target)
+}
+
+// Normalize returns a copy of the wave at the target level.
+// Samples are not clipped, so without a limiter they can exceed full scale.
+func Normalize(w wave.Wave, opts Options) (wave.Wave, float64, error) {
+	gain, err := Gain(w, opts)
+	if err != nil {
+		return wave.Wave{}, 0, err
+	}
+	out, err := apply(w, gain, opts)
+	return out, gain, err
+}
+
+// NormalizeAlbum applies the AlbumGain to every wave
+func NormalizeAlbum(ws []wave.Wave, opts Options) ([]wave.Wave, float64, error) {
+	gain, err := AlbumGain(ws, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]wave.Wave, len(ws))
+	for i, w := range ws {
+		if out[i], err = apply(w, gain, opts); err != nil {
+			return nil, 0, err
+		}
+	}
+	return out, gain, nil
+}
+
+// ApplyGain returns the frames multiplied by a gain in dB
+func ApplyGain(frames []wave.Frame, gain float64) []wave.Frame {
+	factor := wave.Frame(math.Pow(10, gain/20))
+	out := make([]wave.Frame, len(frames))
+	for i, f := range frames {
+		out[i] = f * factor
+	}
+	return out
+}
+
+func apply(w wave.Wave, gain float64, opts Options) (wave.Wave, error) {
+	frames := ApplyGain(w.Frames, gain)
+	if opts.Limiter != nil {
+		var err error
+		frames, err = Limit(frames, w.NumChannels, w.SampleRate, *opts.Limiter)
+		if err != nil {
+			return wave.Wave{}, err
+		}
+	}
+	out := w
+	out.Frames = frames
+	return out, nil
+}
+
+func gainFor(level, target float64) (float64, error) {
+	if math.IsInf(level, -1) {
+		return 0, errors.New("Can't normalize silence")
+	}
+	return target - level, nil
+}
+
+func rms(frames []wave.Frame) float64 {
+	if len(frames) == 0 {
+		return math.Inf(-1)
+	}
+	sum := 0.0
+	for _, f := range frames {
+		sum += float64(f) * float64(f)
+	}
+	return audiomath.AmplitudeToDB(math.Sqrt(sum / float64(len(frames))))
+}
+
+func peak(frames []wave.Frame) float64 {
+	max := 0.0
+	for _, f := range frames {
+		max = math.Max(max, math.Abs(float64(f)))
+	}
+	return audiomath.AmplitudeToDB(max)
+}
@@ -0,0 +1,127 @@
+package normalize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 48000
+
+// sine renders a stereo sine with peak amplitude amp
+func sine(freq, amp, seconds float64) wave.Wave {
+	n := int(seconds * sr)
+	frames := make([]wave.Frame, 0, 2*n)
+	for i := 0; i < n; i++ {
+		x := wave.Frame(amp * math.Sin(2*math.Pi*freq*float64(i)/sr))
+		frames = append(frames, x, x)
+	}
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		mode   Mode
+		target float64
+	}{
+		{LUFS, -23},
+		{LUFS, -14},
+		{RMS, -20},
+		{PEAK, -1},
+		{TRUEPEAK, -2},
+	}
+	in := sine(1000, 0.1, 3)
+	for _, test := range tests {
+		out, _, err := Normalize(in, Options{Mode: test.mode, Target: test.target})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		level, _ := Level(out, test.mode)
+		if math.Abs(level-test.target) > 0.05 {
+			t.Fatalf("Mode %v: expected a level of %v, got %v", test.mode, test.target, level)
+		}
+	}
+
+	// the levels of a sine are related: RMS is 3 dB below the peak (-20 dBFS)
+	_, gain, _ := Normalize(in, Options{Mode: RMS, Target: -30})
+	if expected := -30 - (-20 - 3.01); math.Abs(gain-expected) > 0.01 {
+		t.Fatalf("Expected a gain of %v dB, got %v", expected, gain)
+	}
+
+	silence := sine(1000, 0, 1)
+	if _, _, err := Normalize(silence, Options{Mode: PEAK}); err == nil {
+		t.Fatalf("Expected an error when normalizing silence")
+	}
+}
+
+// TestAlbum makes sure one gain is used and the level differences are kept
+func TestAlbum(t *testing.T) {
+	album := []wave.Wave{sine(1000, 0.1, 3), sine(440, 0.05, 3), sine(2000, 0.2, 2)}
+	for _, mode := range Modes {
+		out, gain, err := NormalizeAlbum(album, Options{Mode: mode, Target: -18})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for i := range album {
+			before, _ := Level(album[i], PEAK)
+			after, _ := Level(out[i], PEAK)
+			if math.Abs(after-before-gain) > 1e-6 {
+				t.Fatalf("Mode %v: expected every wave to get %v dB, got %v", mode, gain, after-before)
+			}
+		}
+	}
+
+	// the loudest track reaches the peak target
+	out, _, _ := NormalizeAlbum(album, Options{Mode: PEAK, Target: -1})
+	if level, _ := Level(out[2], PEAK); math.Abs(level-(-1)) > 1e-6 {
+		t.Fatalf("Expected the loudest track at -1 dBFS, got %v", level)
+	}
+	if _, err := AlbumGain(nil, Options{}); err == nil {
+		t.Fatalf("Expected an error for an empty album")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	// quiet sine with a few loud bursts
+	in := sine(200, 0.1, 1)
+	for _, start := range []int{10000, 30000, 30300} {
+		for i := start; i < start+400; i++ {
+			in.Frames[i] *= 9
+		}
+	}
+	out, err := Limit(in.Frames, 2, sr, LimiterOptions{Ceiling: -6})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ceiling := math.Pow(10, -6./20)
+	for i, f := range out {
+		if math.Abs(float64(f)) > ceiling+1e-9 {
+			t.Fatalf("Sample %v at %v exceeds the ceiling of %v", i, f, ceiling)
+		}
+	}
+	// away from the bursts the signal is untouched
+	for i := 0; i < 9000; i++ {
+		if out[i] != in.Frames[i] {
+			t.Fatalf("Expected sample %v to be unchanged", i)
+		}
+	}
+	// both channels get the same gain
+	for i := 0; i < len(out); i += 2 {
+		if out[i] != out[i+1] {
+			t.Fatalf("Expected the channels to stay linked at frame %v", i/2)
+		}
+	}
+
+	// normalizing with a limiter keeps the peaks under the ceiling
+	loud, _, err := Normalize(in, Options{Mode: LUFS, Target: -10, Limiter: &LimiterOptions{Ceiling: -1}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if level, _ := Level(loud, PEAK); level > -1+1e-6 {
+		t.Fatalf("Expected peaks below -1 dBFS, got %v", level)
+	}
+}
@@ -0,0 +1,82 @@
+package pitch
+
+import (
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// mpm implements "A smarter way to find pitch" (McLeod & Wyvill, 2005).
+// The normalised square difference function (NSDF) is searched for key maxima, and the
+// first one within Threshold of the highest is the period. Its NSDF value (the clarity)
+// is the confidence.
+func mpm(frame []wave.Frame, sr int, opts Options) (float64, float64) {
+	n := len(frame)
+	minTau, maxTau := opts.minLag(sr), opts.maxLag(sr)
+	if maxTau > n-1 {
+		maxTau = n - 1
+	}
+
+	energy := make([]float64, n+1)
+	for i, f := range frame {
+		energy[i+1] = energy[i] + float64(f)*float64(f)
+	}
+	if energy[n] == 0 {
+		return 0, 0
+	}
+	corr := audiomath.AutoCorrelate(frame)
+
+	nsdf := make([]float64, maxTau+1)
+	for tau := range nsdf {
+		// m(tau) = sum(x[j]^2 + x[j+tau]^2) over the overlapping part
+		m := energy[n-tau] + energy[n] - energy[tau]
+		if m > 0 {
+			nsdf[tau] = 2 * corr[tau] / m
+		}
+	}
+
+	// key maxima: the highest point of every positive region after the first
+	// negative-going zero crossing
+	keys := []int{}
+	tau := 1
+	for tau <= maxTau && nsdf[tau] > 0 {
+		tau++
+	}
+	for tau <= maxTau {
+		for tau <= maxTau && nsdf[tau] <= 0 {
+			tau++
+		}
+		best := -1
+		for ; tau <= maxTau && nsdf[tau] > 0; tau++ {
+			if tau >= minTau && (best < 0 || nsdf[tau] > nsdf[best]) {
+				best = tau
+			}
+		}
+		if best >= 0 {
+			keys = append(keys, best)
+		}
+	}
+	if len(keys) == 0 {
+		return 0, 0
+	}
+
+	highest := nsdf[keys[0]]
+	for _, k := range keys {
+		if nsdf[k] > highest {
+			highest = nsdf[k]
+		}
+	}
+	chosen := keys[0]
+	for _, k := range keys {
+		if nsdf[k] >= opts.Threshold*highest {
+			chosen = k
+			break
+		}
+	}
+
+	period := float64(chosen) + audiomath.ParabolicOffset(nsdf, chosen)
+	clarity := nsdf[chosen]
+	if clarity > 1 {
+		clarity = 1
+	}
+	return float64(sr) / period, clarity
+}
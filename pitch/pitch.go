@@ -0,0 +1,158 @@
+// Package pitch estimates the fundamental frequency of (monophonic) audio.
+package pitch
+
+import (
+	"errors"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Method is a pitch detection algorithm
+type Method int
+
+const (
+	// YIN is the algorithm by de Cheveigné and Kawahara
+	YIN Method = iota
+	// MPM is the McLeod Pitch Method
+	MPM
+)
+
+// Methods maps names to the available detection methods
+var Methods = map[string]Method{
+	"yin": YIN,
+	"mpm": MPM,
+}
+
+// Options to configure the pitch detection.
+// Zero values are replaced by the defaults.
+type Options struct {
+	Method    Method
+	FrameSize int     // samples per analysis frame, default 2048
+	HopSize   int     // samples between frames, default FrameSize/4
+	MinFreq   float64 // lowest detectable frequency, default 50 Hz
+	MaxFreq   float64 // highest detectable frequency, default 2000 Hz
+	// Threshold is the YIN absolute threshold (default 0.15) or the MPM key maximum
+	// threshold (default 0.93)
+	Threshold float64
+	// MinConfidence below which a frame is considered unvoiced, default 0.5
+	MinConfidence float64
+}
+
+// Estimate is the detected pitch of a single frame
+type Estimate struct {
+	Time       float64 // centre of the frame in seconds
+	Frequency  float64 // in Hz, 0 when no pitch was found
+	Confidence float64 // 0 .. 1
+	Voiced     bool    // confidence reached MinConfidence
+}
+
+func (o Options) withDefaults() Options {
+	if o.FrameSize == 0 {
+		o.FrameSize = 2048
+	}
+	if o.HopSize == 0 {
+		o.HopSize = o.FrameSize / 4
+	}
+	if o.MinFreq == 0 {
+		o.MinFreq = 50
+	}
+	if o.MaxFreq == 0 {
+		o.MaxFreq = 2000
+	}
+	if o.Threshold == 0 {
+		if o.Method == MPM {
+			o.Threshold = 0.93
+		} else {
+			o.Threshold = 0.15
+		}
+	}
+	if o.MinConfidence == 0 {
+		o.MinConfidence = 0.5
+	}
+	return o
+}
+
+func (o Options) validate(sr int) (Options, error) {
+	o = o.withDefaults()
+	if sr <= 0 {
+		return o, errors.New("Sample rate should be positive")
+	}
+	if o.FrameSize < 0 || o.HopSize <= 0 {
+		return o, errors.New("Frame and hop size should be positive")
+	}
+	if o.MinFreq < 0 || o.MaxFreq <= o.MinFreq || o.MaxFreq > float64(sr)/2 {
+		return o, errors.New("Frequency range should be within 0 .. sample rate / 2")
+	}
+	if o.Method != YIN && o.Method != MPM {
+		return o, errors.New("Unknown pitch detection method")
+	}
+	// YIN compares the first half of the frame with lagged copies, so the longest
+	// period has to fit twice.
+	if 2*o.maxLag(sr) > o.FrameSize {
+		return o, errors.New("Frame size too small for the minimum frequency")
+	}
+	return o, nil
+}
+
+func (o Options) minLag(sr int) int {
+	lag := int(float64(sr) / o.MaxFreq)
+	if lag < 2 {
+		lag = 2
+	}
+	return lag
+}
+
+func (o Options) maxLag(sr int) int {
+	return int(float64(sr)/o.MinFreq) + 1
+}
+
+// Detect estimates the pitch of a single mono frame. The options decide which method is
+// used and the range of frequencies that is searched.
+func Detect(frame []wave.Frame, sr int, opts Options) (Estimate, error) {
+	opts, err := opts.validate(sr)
+	if err != nil {
+		return Estimate{}, err
+	}
+	if len(frame) < 2*opts.maxLag(sr) {
+		return Estimate{}, errors.New("Frame too short for the minimum frequency")
+	}
+	return detect(frame, sr, opts), nil
+}
+
+func detect(frame []wave.Frame, sr int, opts Options) Estimate {
+	var freq, conf float64
+	switch opts.Method {
+	case MPM:
+		freq, conf = mpm(frame, sr, opts)
+	default:
+		freq, conf = yin(frame, sr, opts)
+	}
+	return Estimate{
+		Time:       float64(len(frame)) / 2 / float64(sr),
+		Frequency:  freq,
+		Confidence: conf,
+		Voiced:     freq > 0 && conf >= opts.MinConfidence,
+	}
+}
+
+// Track estimates the pitch of the wave every HopSize samples.
+// Channels are mixed to mono first. The last frames are zero-padded.
+func Track(w wave.Wave, opts Options) ([]Estimate, error) {
+	opts, err := opts.validate(w.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	mono := wave.MixToMono(w.Frames, w.NumChannels)
+	out := []Estimate{}
+	frame := make([]wave.Frame, opts.FrameSize)
+	for start := 0; start < len(mono); start += opts.HopSize {
+		n := copy(frame, mono[start:])
+		for i := n; i < len(frame); i++ {
+			frame[i] = 0
+		}
+		e := detect(frame, w.SampleRate, opts)
+		e.Time += float64(start) / float64(w.SampleRate)
+		out = append(out, e)
+	}
+	return out, nil
+}
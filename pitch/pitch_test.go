@@ -0,0 +1,159 @@
+package pitch
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	detectTests = []struct {
+		signal []wave.Frame
+		sr     int
+		freq   float64
+	}{
+		{sine(220, 44100, 2048), 44100, 220},
+		{sine(440, 44100, 2048), 44100, 440},
+		{sine(1000, 44100, 2048), 44100, 1000},
+		{sine(82.41, 44100, 4096), 44100, 82.41},
+		{sine(261.63, 16000, 1024), 16000, 261.63},
+		// harmonic-rich signals should not give octave errors
+		{sawtooth(110, 44100, 2048), 44100, 110},
+		{sawtooth(146.83, 48000, 2048), 48000, 146.83},
+		// a missing fundamental is still heard (and detected) as the fundamental
+		{harmonics(200, 44100, 2048, 2, 3, 4), 44100, 200},
+	}
+)
+
+func sine(freq float64, sr, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(sr)))
+	}
+	return out
+}
+
+func sawtooth(freq float64, sr, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		phase := math.Mod(freq*float64(i)/float64(sr), 1)
+		out[i] = wave.Frame(phase - 0.5)
+	}
+	return out
+}
+
+func harmonics(freq float64, sr, n int, hs ...int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for _, h := range hs {
+		for i := range out {
+			out[i] += wave.Frame(0.2 * math.Sin(2*math.Pi*freq*float64(h)*float64(i)/float64(sr)))
+		}
+	}
+	return out
+}
+
+// cents between two frequencies
+func cents(a, b float64) float64 {
+	return 1200 * math.Log2(a/b)
+}
+
+func TestDetect(t *testing.T) {
+	for _, method := range Methods {
+		for _, test := range detectTests {
+			t.Run("", func(t *testing.T) {
+				opts := Options{Method: method, FrameSize: len(test.signal)}
+				e, err := Detect(test.signal, test.sr, opts)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if math.Abs(cents(e.Frequency, test.freq)) > 5 {
+					t.Fatalf("Method %v: expected %v Hz but got %v Hz", method, test.freq, e.Frequency)
+				}
+				if !e.Voiced || e.Confidence < 0.9 {
+					t.Fatalf("Method %v: expected a confident estimate, got %v", method, e.Confidence)
+				}
+			})
+		}
+	}
+}
+
+// TestUnvoiced makes sure silence and noise are not reported as pitched
+func TestUnvoiced(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	noise := make([]wave.Frame, 2048)
+	for i := range noise {
+		noise[i] = wave.Frame(r.Float64() - 0.5)
+	}
+	for _, method := range Methods {
+		for _, signal := range [][]wave.Frame{make([]wave.Frame, 2048), noise} {
+			e, err := Detect(signal, 44100, Options{Method: method})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if e.Voiced {
+				t.Fatalf("Method %v: expected unvoiced, got %v Hz with confidence %v",
+					method, e.Frequency, e.Confidence)
+			}
+		}
+	}
+}
+
+// TestTrack follows a signal that jumps from A3 to E4 halfway
+func TestTrack(t *testing.T) {
+	sr := 44100
+	frames := append(sine(220, sr, sr/2), sine(329.63, sr, sr/2)...)
+	// stereo, so we also test the mixing
+	w := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: wave.JoinChannels([][]wave.Frame{frames, frames})},
+	}
+
+	for _, method := range Methods {
+		track, err := Track(w, Options{Method: method})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := (len(frames) + 511) / 512
+		if len(track) != expected {
+			t.Fatalf("Expected %v estimates, got %v", expected, len(track))
+		}
+		for i, e := range track {
+			if i > 0 && e.Time <= track[i-1].Time {
+				t.Fatalf("Estimates should be ordered in time")
+			}
+			// skip the frames that contain the transition or the end of the signal
+			if math.Abs(e.Time-0.5) < 0.05 || e.Time > 0.95 {
+				continue
+			}
+			want := 220.
+			if e.Time > 0.5 {
+				want = 329.63
+			}
+			if !e.Voiced || math.Abs(cents(e.Frequency, want)) > 5 {
+				t.Fatalf("Method %v: expected %v Hz at %v s, got %v Hz (confidence %v)",
+					method, want, e.Time, e.Frequency, e.Confidence)
+			}
+		}
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	invalid := []Options{
+		{MinFreq: 1000, MaxFreq: 500},
+		{MaxFreq: 30000},
+		{MinFreq: 10, FrameSize: 2048},
+		{Method: Method(5)},
+		{HopSize: -1},
+	}
+	for _, opts := range invalid {
+		w := wave.Wave{WaveFmt: wave.NewWaveFmt(1, 1, 44100, 16, nil)}
+		if _, err := Track(w, opts); err == nil {
+			t.Fatalf("Expected an error for %+v", opts)
+		}
+	}
+	if _, err := Detect(make([]wave.Frame, 100), 44100, Options{}); err == nil {
+		t.Fatalf("Expected an error for a frame that is too short")
+	}
+}
@@ -0,0 +1,75 @@
+package pitch
+
+import (
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// yin implements "YIN, a fundamental frequency estimator for speech and music"
+// (de Cheveigné & Kawahara, 2002). The first half of the frame is compared with copies
+// delayed by every candidate period. The confidence is 1 - the normalised difference
+// at the chosen period.
+func yin(frame []wave.Frame, sr int, opts Options) (float64, float64) {
+	w := len(frame) / 2
+	minTau, maxTau := opts.minLag(sr), opts.maxLag(sr)
+	if maxTau > len(frame)-w {
+		maxTau = len(frame) - w
+	}
+
+	// prefix sums of the energy, so the difference function can be written as
+	// d(tau) = e(0, w) + e(tau, tau+w) - 2 * r(tau)
+	energy := make([]float64, w+maxTau+1)
+	for i, f := range frame[:w+maxTau] {
+		energy[i+1] = energy[i] + float64(f)*float64(f)
+	}
+	if energy[w] == 0 {
+		return 0, 0
+	}
+	corr := audiomath.CrossCorrelate(frame[:w], frame[:w+maxTau])[w-1:]
+
+	// cumulative mean normalised difference
+	cmnd := make([]float64, maxTau+1)
+	cmnd[0] = 1
+	sum := 0.0
+	for tau := 1; tau <= maxTau; tau++ {
+		d := energy[w] + energy[tau+w] - energy[tau] - 2*corr[tau]
+		if d < 0 {
+			// rounding errors of the FFT
+			d = 0
+		}
+		sum += d
+		if sum == 0 {
+			cmnd[tau] = 1
+		} else {
+			cmnd[tau] = d * float64(tau) / sum
+		}
+	}
+
+	// the first dip below the threshold, followed down to its minimum.
+	// If there is none we use the global minimum, which gets a low confidence.
+	best := -1
+	for tau := minTau; tau <= maxTau; tau++ {
+		if cmnd[tau] < opts.Threshold {
+			for tau+1 <= maxTau && cmnd[tau+1] < cmnd[tau] {
+				tau++
+			}
+			best = tau
+			break
+		}
+	}
+	if best < 0 {
+		best = minTau
+		for tau := minTau; tau <= maxTau; tau++ {
+			if cmnd[tau] < cmnd[best] {
+				best = tau
+			}
+		}
+	}
+
+	period := float64(best) + audiomath.ParabolicOffset(cmnd, best)
+	confidence := 1 - cmnd[best]
+	if confidence < 0 {
+		confidence = 0
+	}
+	return float64(sr) / period, confidence
+}
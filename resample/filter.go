@@ -0,0 +1,84 @@
+package resample
+
+import (
+	"math"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// Quality selects the trade-off between speed and accuracy of the conversion
+type Quality int
+
+// Quality presets for the resampler. Higher presets use longer filters, giving a wider
+// passband and a deeper stopband at the cost of more work per output sample.
+const (
+	LOW Quality = iota
+	MEDIUM
+	HIGH
+	BEST
+)
+
+// qualityParams describes the windowed-sinc filter for a quality preset
+type qualityParams struct {
+	attenuation   float64 // stopband attenuation in dB
+	zeroCrossings int     // zero crossings of the sinc on either side of the centre
+}
+
+var (
+	qualities = map[Quality]qualityParams{
+		LOW:    {60, 8},
+		MEDIUM: {90, 24},
+		HIGH:   {120, 64},
+		BEST:   {140, 128},
+	}
+)
+
+// maxTablePhases is the largest number of phases for which we precompute the filter.
+// Ratios with more phases (e.g 44100 -> 44101) calculate their taps on the fly instead.
+const maxTablePhases = 4096
+
+// kernel is a Kaiser-windowed sinc lowpass evaluated at the interpolated sample rate
+type kernel struct {
+	cutoff float64 // normalised to the Nyquist frequency of the interpolated rate
+	half   float64 // half-length of the filter, in interpolated samples
+	window func(x float64) float64
+}
+
+// newKernel designs the anti-aliasing filter for upsampling by l and downsampling by m.
+// The stopband starts at the lower of the input and output Nyquist frequencies.
+func newKernel(l, m int, q qualityParams) kernel {
+	a := q.attenuation
+	// Kaiser's transition width, as a fraction of the cutoff frequency
+	transition := (a - 7.95) / (14.36 * float64(q.zeroCrossings))
+	stopband := 1.0 / float64(max(l, m))
+	cutoff := stopband / (1 + transition/2)
+
+	return kernel{
+		cutoff: cutoff,
+		half:   float64(q.zeroCrossings) / cutoff,
+		window: window.KaiserFunc(window.KaiserBeta(a)),
+	}
+}
+
+// at evaluates the filter at offset x (in interpolated samples) from the centre
+func (k kernel) at(x float64) float64 {
+	if math.Abs(x) >= k.half {
+		return 0
+	}
+	return k.cutoff * audiomath.Sinc(k.cutoff*x) * k.window(x/k.half)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
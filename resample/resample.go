@@ -0,0 +1,210 @@
+// Package resample converts audio between sample rates using band-limited
+// (Kaiser windowed-sinc) polyphase interpolation.
+//
+// Any rational ratio between two integer sample rates is supported. Audio is passed as
+// interleaved wave.Frame slices, just like wave.Wave stores it.
+package resample
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// Resampler converts a stream of interleaved frames from one sample rate to another.
+// Input can be passed in blocks of any size, the output is the same as resampling
+// the whole signal at once.
+type Resampler struct {
+	channels int
+	l, m     int // upsampling and downsampling factor
+	k        kernel
+	lo, hi   int         // range of filter taps, relative to the current input sample
+	table    [][]float64 // precomputed taps per phase, nil if calculated on the fly
+
+	history  [][]float64 // input per channel, starting at absolute index 'offset'
+	offset   int
+	consumed int          // number of input samples per channel
+	pending  []wave.Frame // incomplete multi-channel frame from the previous block
+	n        int          // index of the next output sample per channel
+	flushed  bool
+}
+
+// New creates a Resampler for interleaved audio with the given number of channels,
+// converting from one sample rate to another.
+func New(channels, from, to int, q Quality) (*Resampler, error) {
+	if channels <= 0 {
+		return nil, errors.New("Need at least one channel to resample")
+	}
+	if from <= 0 || to <= 0 {
+		return nil, fmt.Errorf("Invalid sample rates %v -> %v", from, to)
+	}
+	params, ok := qualities[q]
+	if !ok {
+		return nil, fmt.Errorf("Quality %v not supported", q)
+	}
+
+	g := gcd(from, to)
+	r := &Resampler{
+		channels: channels,
+		l:        to / g,
+		m:        from / g,
+	}
+	r.k = newKernel(r.l, r.m, params)
+	reach := int(r.k.half)/r.l + 1
+	r.lo, r.hi = -reach, reach
+
+	if r.l <= maxTablePhases {
+		r.table = make([][]float64, r.l)
+		for p := range r.table {
+			r.table[p] = r.taps(p)
+		}
+	}
+	r.Reset()
+	return r, nil
+}
+
+// Resample converts the interleaved frames from one sample rate to another in one go.
+// The output contains ceil(len(frames) * to / from) samples per channel.
+func Resample(frames []wave.Frame, channels, from, to int, q Quality) ([]wave.Frame, error) {
+	r, err := New(channels, from, to, q)
+	if err != nil {
+		return nil, err
+	}
+	out := r.Process(frames)
+	return append(out, r.Flush()...), nil
+}
+
+// Ratio returns the reduced upsampling and downsampling factors of the conversion
+func (r *Resampler) Ratio() (up, down int) {
+	return r.l, r.m
+}
+
+// Latency returns how many input samples per channel need to be buffered before
+// the output for a given input sample can be produced.
+func (r *Resampler) Latency() int {
+	return -r.lo
+}
+
+// Reset clears the state of the resampler so it can be used for a new stream
+func (r *Resampler) Reset() {
+	r.history = make([][]float64, r.channels)
+	r.offset = 0
+	r.consumed = 0
+	r.pending = nil
+	r.n = 0
+	r.flushed = false
+}
+
+// Process consumes a block of interleaved frames and returns all output that can be
+// produced so far.
+func (r *Resampler) Process(in []wave.Frame) []wave.Frame {
+	if r.flushed {
+		return nil
+	}
+	in = append(r.pending, in...)
+	whole := len(in) - len(in)%r.channels
+	for i := 0; i < whole; i++ {
+		c := i % r.channels
+		r.history[c] = append(r.history[c], float64(in[i]))
+	}
+	r.consumed += whole / r.channels
+	r.pending = append([]wave.Frame{}, in[whole:]...)
+
+	return r.produce(false)
+}
+
+// Flush returns the remaining output, treating the end of the input as silence.
+// The resampler has to be Reset before it can be used again.
+func (r *Resampler) Flush() []wave.Frame {
+	if r.flushed {
+		return nil
+	}
+	out := r.produce(true)
+	r.flushed = true
+	return out
+}
+
+// produce calculates output samples until we run out of input.
+// When final is set, missing input past the end of the stream is treated as silence.
+func (r *Resampler) produce(final bool) []wave.Frame {
+	out := []wave.Frame{}
+	for {
+		pos := r.n * r.m
+		i, p := pos/r.l, pos%r.l
+		if final && pos >= r.consumed*r.l {
+			break
+		}
+		if !final && i-r.lo >= r.consumed {
+			break
+		}
+
+		taps := r.phase(p)
+		for c := 0; c < r.channels; c++ {
+			hist := r.history[c]
+			sum := 0.0
+			for j, tap := range taps {
+				idx := i - (r.lo + j) - r.offset
+				if idx < 0 || idx >= len(hist) {
+					continue
+				}
+				sum += hist[idx] * tap
+			}
+			out = append(out, wave.Frame(sum))
+		}
+		r.n++
+	}
+	r.trim()
+	return out
+}
+
+// trim drops input samples that no output sample depends on anymore
+func (r *Resampler) trim() {
+	next := (r.n * r.m) / r.l
+	drop := next - r.hi - r.offset
+	if drop <= 0 {
+		return
+	}
+	for c := range r.history {
+		if drop > len(r.history[c]) {
+			drop = len(r.history[c])
+		}
+	}
+	for c := range r.history {
+		r.history[c] = append([]float64{}, r.history[c][drop:]...)
+	}
+	r.offset += drop
+}
+
+// phase returns the filter taps for the given phase
+func (r *Resampler) phase(p int) []float64 {
+	if r.table != nil {
+		return r.table[p]
+	}
+	return r.taps(p)
+}
+
+// taps calculates the filter taps for a phase, tap j is applied to input sample i-(lo+j).
+// Each phase is normalised to unity gain at DC.
+func (r *Resampler) taps(p int) []float64 {
+	taps := make([]float64, r.hi-r.lo+1)
+	sum := 0.0
+	for j := range taps {
+		taps[j] = r.k.at(float64(p + (r.lo+j)*r.l))
+		sum += taps[j]
+	}
+	if sum != 0 {
+		for j := range taps {
+			taps[j] /= sum
+		}
+	}
+	return taps
+}
+
+// Func returns a wave.ResampleFunc with the given quality, so the resampler can be used
+// by wave.Convert
+func Func(q Quality) wave.ResampleFunc {
+	return func(frames []wave.Frame, channels, from, to int) ([]wave.Frame, error) {
+		return Resample(frames, channels, from, to, q)
+	}
+}
@@ -0,0 +1,173 @@
+package resample
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	// passbandTests resample a sine well below the cutoff and expect it to come out unchanged
+	passbandTests = []struct {
+		from, to int
+		freq     float64
+		q        Quality
+		maxDB    float64 // maximum deviation of the amplitude
+	}{
+		{44100, 48000, 1000, MEDIUM, 0.01},
+		{48000, 44100, 15000, HIGH, 0.01},
+		{96000, 44100, 19000, BEST, 0.01},
+		{44100, 96000, 10000, LOW, 0.05},
+		{8000, 11025, 440, HIGH, 0.01},
+	}
+
+	// stopbandTests resample a sine above the output Nyquist frequency and expect it to be removed
+	stopbandTests = []struct {
+		from, to int
+		freq     float64
+		q        Quality
+		minDB    float64 // minimum attenuation
+	}{
+		{48000, 44100, 23000, LOW, 55},
+		{48000, 44100, 23000, MEDIUM, 85},
+		{48000, 44100, 23000, HIGH, 110},
+		{96000, 48000, 30000, HIGH, 110},
+	}
+)
+
+func sine(freq float64, sr, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(sr)))
+	}
+	return out
+}
+
+// rms of the middle of the signal, ignoring the edges where the filter rings
+func rms(fs []wave.Frame) float64 {
+	edge := len(fs) / 5
+	sum := 0.0
+	for _, f := range fs[edge : len(fs)-edge] {
+		sum += float64(f) * float64(f)
+	}
+	return math.Sqrt(sum / float64(len(fs)-2*edge))
+}
+
+func dB(ratio float64) float64 {
+	return 20 * math.Log10(ratio)
+}
+
+func TestPassband(t *testing.T) {
+	for _, test := range passbandTests {
+		t.Run("", func(t *testing.T) {
+			in := sine(test.freq, test.from, test.from/4)
+			out, err := Resample(in, 1, test.from, test.to, test.q)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := sine(test.freq, test.to, len(out))
+			if diff := math.Abs(dB(rms(out) / rms(expected))); diff > test.maxDB {
+				t.Fatalf("Passband deviation of %v dB at %v Hz", diff, test.freq)
+			}
+			// the output should also line up with the expected sine
+			residual := make([]wave.Frame, len(out))
+			for i := range out {
+				residual[i] = out[i] - expected[i]
+			}
+			if r := dB(rms(residual) / rms(expected)); r > -40 {
+				t.Fatalf("Output does not match the expected sine, residual at %v dB", r)
+			}
+		})
+	}
+}
+
+func TestStopband(t *testing.T) {
+	for _, test := range stopbandTests {
+		t.Run("", func(t *testing.T) {
+			in := sine(test.freq, test.from, test.from/4)
+			out, err := Resample(in, 1, test.from, test.to, test.q)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if att := -dB(rms(out) / rms(in)); att < test.minDB {
+				t.Fatalf("Expected at least %v dB attenuation, got %v dB", test.minDB, att)
+			}
+		})
+	}
+}
+
+func TestOutputLength(t *testing.T) {
+	out, err := Resample(make([]wave.Frame, 2*1001), 2, 44100, 48000, LOW)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// ceil(1001 * 160 / 147) = 1090 samples per channel
+	if len(out) != 2*1090 {
+		t.Fatalf("Expected %v samples, got %v", 2*1090, len(out))
+	}
+}
+
+// TestStreaming makes sure processing in blocks gives the same result as processing at once
+func TestStreaming(t *testing.T) {
+	left := sine(440, 44100, 4000)
+	right := sine(3000, 44100, 4000)
+	in := make([]wave.Frame, 0, 8000)
+	for i := range left {
+		in = append(in, left[i], right[i])
+	}
+
+	whole, err := Resample(in, 2, 44100, 32000, MEDIUM)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r, _ := New(2, 44100, 32000, MEDIUM)
+	blocks := []wave.Frame{}
+	// odd block size, so frames get split between blocks
+	for start := 0; start < len(in); start += 333 {
+		end := start + 333
+		if end > len(in) {
+			end = len(in)
+		}
+		blocks = append(blocks, r.Process(in[start:end])...)
+	}
+	blocks = append(blocks, r.Flush()...)
+
+	if len(blocks) != len(whole) {
+		t.Fatalf("Expected %v samples, got %v", len(whole), len(blocks))
+	}
+	for i := range whole {
+		if math.Abs(float64(whole[i]-blocks[i])) > 1e-12 {
+			t.Fatalf("Mismatch at %v: %v != %v", i, whole[i], blocks[i])
+		}
+	}
+}
+
+// TestOnTheFlyTaps covers ratios with too many phases to precompute
+func TestOnTheFlyTaps(t *testing.T) {
+	r, err := New(1, 44100, 44101, LOW)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.table != nil {
+		t.Fatalf("Expected taps to be computed on the fly")
+	}
+	out := append(r.Process(sine(1000, 44100, 2000)), r.Flush()...)
+	expected := sine(1000, 44101, len(out))
+	if diff := math.Abs(dB(rms(out) / rms(expected))); diff > 0.05 {
+		t.Fatalf("Passband deviation of %v dB", diff)
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	if _, err := New(0, 44100, 48000, HIGH); err == nil {
+		t.Fatal("Expected error for zero channels")
+	}
+	if _, err := New(1, 0, 48000, HIGH); err == nil {
+		t.Fatal("Expected error for zero sample rate")
+	}
+	if _, err := New(1, 44100, 48000, Quality(42)); err == nil {
+		t.Fatal("Expected error for unknown quality")
+	}
+}
@@ -0,0 +1,147 @@
+package rhythm
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// BeatOptions configure the beat tracker.
+// Zero values are replaced by the defaults.
+type BeatOptions struct {
+	Tempo TempoOptions // used to estimate the tempo when BPM is 0
+	BPM   float64      // fixed tempo
+	// Tightness is how strictly the beats have to follow the tempo, default 100
+	Tightness float64
+}
+
+// TrackBeats finds the beats in an onset envelope with dynamic programming (Ellis, 2007).
+// Every beat is placed on a strong onset while the time between beats stays close to the
+// tempo. It returns the beat times in seconds and the tempo that was used.
+func TrackBeats(env Envelope, opts BeatOptions) ([]float64, float64, error) {
+	if opts.Tightness == 0 {
+		opts.Tightness = 100
+	}
+	bpm := opts.BPM
+	if bpm == 0 {
+		var err error
+		if bpm, err = EstimateTempo(env, opts.Tempo); err != nil {
+			return nil, 0, err
+		}
+	}
+	if bpm < 0 || env.Rate <= 0 {
+		return nil, 0, errors.New("Tempo and envelope rate should be positive")
+	}
+	period := 60 * env.Rate / bpm
+	if period < 2 {
+		return nil, 0, errors.New("Tempo too fast for the envelope rate")
+	}
+
+	local := localScore(env.Values, period)
+	score := make([]float64, len(local))
+	backlink := make([]int, len(local))
+	lo, hi := int(math.Round(period/2)), int(math.Round(2*period))
+	for t := range local {
+		backlink[t] = -1
+		best := math.Inf(-1)
+		for prev := t - hi; prev <= t-lo; prev++ {
+			if prev < 0 {
+				continue
+			}
+			// penalise beat intervals that differ from the period
+			dev := math.Log(float64(t-prev) / period)
+			s := score[prev] - opts.Tightness*dev*dev
+			if s > best {
+				best = s
+				backlink[t] = prev
+			}
+		}
+		score[t] = local[t]
+		if backlink[t] >= 0 {
+			score[t] += best
+		}
+	}
+
+	last := lastBeat(score)
+	if last < 0 {
+		return []float64{}, bpm, nil
+	}
+	beats := []float64{}
+	for t := last; t >= 0; t = backlink[t] {
+		beats = append(beats, env.Time(t))
+	}
+	// backtracking gives them in reverse order
+	for i, j := 0, len(beats)-1; i < j; i, j = i+1, j-1 {
+		beats[i], beats[j] = beats[j], beats[i]
+	}
+	return beats, bpm, nil
+}
+
+// Beats returns the beat times (in seconds) and tempo of the wave
+func Beats(w wave.Wave, onset OnsetOptions, opts BeatOptions) ([]float64, float64, error) {
+	env, err := OnsetEnvelope(w, onset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return TrackBeats(env, opts)
+}
+
+// localScore normalises the envelope and smooths it with a Gaussian of period/32 so
+// beats can snap to onsets that are slightly off
+func localScore(env []float64, period float64) []float64 {
+	mean, sq := 0.0, 0.0
+	for _, v := range env {
+		mean += v
+		sq += v * v
+	}
+	mean /= float64(len(env))
+	std := math.Sqrt(sq/float64(len(env)) - mean*mean)
+	if std == 0 {
+		std = 1
+	}
+
+	sigma := period / 32
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	for i := range kernel {
+		x := float64(i-radius) / sigma
+		kernel[i] = math.Exp(-0.5 * x * x)
+	}
+	out := make([]float64, len(env))
+	for i := range out {
+		for j, k := range kernel {
+			if idx := i + j - radius; idx >= 0 && idx < len(env) {
+				out[i] += k * env[idx] / std
+			}
+		}
+	}
+	return out
+}
+
+// lastBeat picks the last local maximum of the cumulative score that is at least half the
+// median of all local maxima, so a weak tail doesn't end the beat sequence
+func lastBeat(score []float64) int {
+	maxima := []int{}
+	for i := 1; i < len(score)-1; i++ {
+		if score[i] > score[i-1] && score[i] >= score[i+1] {
+			maxima = append(maxima, i)
+		}
+	}
+	if len(maxima) == 0 {
+		return len(score) - 1
+	}
+	values := make([]float64, len(maxima))
+	for i, m := range maxima {
+		values[i] = score[m]
+	}
+	sort.Float64s(values)
+	median := values[len(values)/2]
+	for i := len(maxima) - 1; i >= 0; i-- {
+		if score[maxima[i]] > median/2 {
+			return maxima[i]
+		}
+	}
+	return maxima[len(maxima)-1]
+}
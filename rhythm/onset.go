@@ -0,0 +1,142 @@
+// Package rhythm finds onsets, the tempo and the beats of a recording.
+package rhythm
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// OnsetMethod is an onset detection function
+type OnsetMethod int
+
+const (
+	// SPECTRALFLUX sums the increase in (log) magnitude of every bin
+	SPECTRALFLUX OnsetMethod = iota
+	// COMPLEXDOMAIN measures how far every bin is from the magnitude and phase predicted
+	// from the previous frames, so it also picks up soft (pitched) onsets
+	COMPLEXDOMAIN
+)
+
+// OnsetMethods maps names to the onset detection functions
+var OnsetMethods = map[string]OnsetMethod{
+	"flux":    SPECTRALFLUX,
+	"complex": COMPLEXDOMAIN,
+}
+
+// OnsetOptions configure the onset detection.
+// Zero values are replaced by the defaults.
+type OnsetOptions struct {
+	Method    OnsetMethod
+	FrameSize int // STFT frame size, default 2048
+	HopSize   int // samples between envelope values, default 512
+	Peaks     PeakOptions
+}
+
+// Envelope is an onset detection function, sampled every HopSize samples
+type Envelope struct {
+	Values []float64
+	Rate   float64 // values per second
+}
+
+// Time returns the time in seconds of the i-th value
+func (e Envelope) Time(i int) float64 {
+	return float64(i) / e.Rate
+}
+
+func (o OnsetOptions) withDefaults() OnsetOptions {
+	if o.FrameSize == 0 {
+		o.FrameSize = 2048
+	}
+	if o.HopSize == 0 {
+		o.HopSize = 512
+	}
+	return o
+}
+
+// OnsetEnvelope computes the onset detection function of the wave, mixed to mono.
+// Value i describes the change at sample i*HopSize.
+func OnsetEnvelope(w wave.Wave, opts OnsetOptions) (Envelope, error) {
+	opts = opts.withDefaults()
+	if w.SampleRate <= 0 {
+		return Envelope{}, errors.New("Sample rate should be positive")
+	}
+	spectrum, err := audiomath.STFT(wave.MixToMono(w.Frames, w.NumChannels), audiomath.STFTConfig{
+		FrameSize: opts.FrameSize,
+		HopSize:   opts.HopSize,
+	})
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	var values []float64
+	switch opts.Method {
+	case SPECTRALFLUX:
+		values = spectralFlux(spectrum)
+	case COMPLEXDOMAIN:
+		values = complexDomain(spectrum)
+	default:
+		return Envelope{}, errors.New("Unknown onset method")
+	}
+	return Envelope{
+		Values: values,
+		Rate:   float64(w.SampleRate) / float64(opts.HopSize),
+	}, nil
+}
+
+// Onsets returns the times (in seconds) where notes start
+func Onsets(w wave.Wave, opts OnsetOptions) ([]float64, error) {
+	opts = opts.withDefaults()
+	env, err := OnsetEnvelope(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	peaks := PickPeaks(env.Values, opts.Peaks)
+	times := make([]float64, len(peaks))
+	for i, p := range peaks {
+		times[i] = env.Time(p)
+	}
+	return times, nil
+}
+
+// spectralFlux is the half-wave rectified difference of the log-compressed magnitudes
+func spectralFlux(spectrum [][]complex128) []float64 {
+	out := make([]float64, len(spectrum))
+	for m := 1; m < len(spectrum); m++ {
+		for k := range spectrum[m] {
+			diff := compress(spectrum[m][k]) - compress(spectrum[m-1][k])
+			if diff > 0 {
+				out[m] += diff
+			}
+		}
+	}
+	return out
+}
+
+// compress returns the log-compressed magnitude of a bin
+func compress(c complex128) float64 {
+	return math.Log1p(100 * cmplx.Abs(c))
+}
+
+// complexDomain is the rectified complex-domain detection function (Duxbury et al.).
+// Every bin is predicted to keep its magnitude and to advance its phase as much as it did
+// the frame before. Only bins that grow in magnitude contribute.
+func complexDomain(spectrum [][]complex128) []float64 {
+	out := make([]float64, len(spectrum))
+	for m := 2; m < len(spectrum); m++ {
+		for k := range spectrum[m] {
+			prev := cmplx.Abs(spectrum[m-1][k])
+			cur := cmplx.Abs(spectrum[m][k])
+			if cur < prev {
+				continue
+			}
+			phase := 2*cmplx.Phase(spectrum[m-1][k]) - cmplx.Phase(spectrum[m-2][k])
+			target := cmplx.Rect(prev, phase)
+			out[m] += cmplx.Abs(spectrum[m][k] - target)
+		}
+	}
+	return out
+}
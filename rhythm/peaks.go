@@ -0,0 +1,90 @@
+package rhythm
+
+// PeakOptions configure the peak picking of an onset envelope, all sizes are in envelope
+// values. Zero values are replaced by the defaults.
+type PeakOptions struct {
+	PreMax, PostMax int     // a peak is the maximum of this window, default 3 and 1
+	PreAvg, PostAvg int     // window for the local mean, default 10 and 7
+	Delta           float64 // how far a peak has to be above the local mean, default 0.05
+	Wait            int     // minimum distance between peaks, default 3
+}
+
+func (o PeakOptions) withDefaults() PeakOptions {
+	if o.PreMax == 0 {
+		o.PreMax = 3
+	}
+	if o.PostMax == 0 {
+		o.PostMax = 1
+	}
+	if o.PreAvg == 0 {
+		o.PreAvg = 10
+	}
+	if o.PostAvg == 0 {
+		o.PostAvg = 7
+	}
+	if o.Delta == 0 {
+		o.Delta = 0.05
+	}
+	if o.Wait == 0 {
+		o.Wait = 3
+	}
+	return o
+}
+
+// PickPeaks returns the indices of the onsets in an envelope (Böck et al., 2012).
+// A value is an onset when it is the maximum of its neighbourhood, it exceeds the local
+// mean by Delta and there was no onset in the previous Wait values.
+// The envelope is normalised to a maximum of 1 first, so Delta is relative.
+func PickPeaks(env []float64, opts PeakOptions) []int {
+	opts = opts.withDefaults()
+	highest := 0.0
+	for _, v := range env {
+		if v > highest {
+			highest = v
+		}
+	}
+	peaks := []int{}
+	if highest == 0 {
+		return peaks
+	}
+
+	last := -opts.Wait - 1
+	for i, v := range env {
+		v /= highest
+		if v < opts.Delta || i-last <= opts.Wait {
+			continue
+		}
+		lo, hi := clamp(i-opts.PreMax, len(env)), clamp(i+opts.PostMax+1, len(env))
+		isMax := true
+		for _, x := range env[lo:hi] {
+			if x/highest > v {
+				isMax = false
+				break
+			}
+		}
+		if !isMax {
+			continue
+		}
+		lo, hi = clamp(i-opts.PreAvg, len(env)), clamp(i+opts.PostAvg+1, len(env))
+		mean := 0.0
+		for _, x := range env[lo:hi] {
+			mean += x / highest
+		}
+		mean /= float64(hi - lo)
+		if v >= mean+opts.Delta {
+			peaks = append(peaks, i)
+			last = i
+		}
+	}
+	return peaks
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
@@ -0,0 +1,154 @@
+package rhythm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 22050
+
+// clicks renders decaying noise bursts at the given times
+func clicks(times []float64, length float64) wave.Wave {
+	r := rand.New(rand.NewSource(1))
+	frames := make([]wave.Frame, int(length*sr))
+	for _, t := range times {
+		start := int(t * sr)
+		for i := 0; i < sr/20 && start+i < len(frames); i++ {
+			frames[start+i] += wave.Frame((r.Float64() - 0.5) * math.Exp(-float64(i)/200))
+		}
+	}
+	return mono(frames)
+}
+
+// notes renders a legato sequence of sines without gaps between them, so only the change
+// in pitch marks the onsets
+func notes(freqs []float64, duration float64) wave.Wave {
+	frames := []wave.Frame{}
+	phase := 0.0
+	for _, f := range freqs {
+		for i := 0; i < int(duration*sr); i++ {
+			frames = append(frames, wave.Frame(0.5*math.Sin(phase)))
+			phase += 2 * math.Pi * f / sr
+		}
+	}
+	return mono(frames)
+}
+
+func mono(frames []wave.Frame) wave.Wave {
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 1, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: frames},
+	}
+}
+
+// metronome returns the times of n beats at the given tempo, starting at offset
+func metronome(bpm, offset float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = offset + float64(i)*60/bpm
+	}
+	return out
+}
+
+// matches counts how many of the expected times have a detected time within tolerance
+func matches(expected, detected []float64, tolerance float64) int {
+	count := 0
+	for _, e := range expected {
+		for _, d := range detected {
+			if math.Abs(e-d) <= tolerance {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+func TestOnsets(t *testing.T) {
+	// irregular onsets, so they can't be guessed from a tempo
+	times := []float64{0.3, 0.75, 1.0, 1.6, 1.85, 2.5, 3.1, 3.3}
+	w := clicks(times, 4)
+	for name, method := range OnsetMethods {
+		onsets, err := Onsets(w, OnsetOptions{Method: method})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// within 1.5 hop sizes
+		if len(onsets) != len(times) || matches(times, onsets, 0.035) != len(times) {
+			t.Fatalf("%v: expected onsets at %v, got %v", name, times, onsets)
+		}
+	}
+}
+
+func TestPitchedOnsets(t *testing.T) {
+	w := notes([]float64{220, 330, 262, 392, 440}, 0.5)
+	expected := []float64{0.5, 1, 1.5, 2}
+	onsets, err := Onsets(w, OnsetOptions{Method: COMPLEXDOMAIN})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if matches(expected, onsets, 0.035) != len(expected) {
+		t.Fatalf("Expected onsets at %v, got %v", expected, onsets)
+	}
+}
+
+func TestPickPeaks(t *testing.T) {
+	env := []float64{0, 0, 1, 0.2, 0, 0, 0, 0, 0, 0.5, 0.45, 0.1, 0, 0, 0.01, 0, 0, 0.8, 0}
+	peaks := PickPeaks(env, PeakOptions{})
+	expected := []int{2, 9, 17}
+	if len(peaks) != len(expected) {
+		t.Fatalf("Expected peaks %v, got %v", expected, peaks)
+	}
+	for i := range peaks {
+		if peaks[i] != expected[i] {
+			t.Fatalf("Expected peaks %v, got %v", expected, peaks)
+		}
+	}
+	if len(PickPeaks(make([]float64, 10), PeakOptions{})) != 0 {
+		t.Fatalf("Expected no peaks in silence")
+	}
+}
+
+func TestTempo(t *testing.T) {
+	for _, bpm := range []float64{90, 120, 128, 150} {
+		w := clicks(metronome(bpm, 0.2, int(bpm/6)), 10.5)
+		tempo, err := Tempo(w, OnsetOptions{}, TempoOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if math.Abs(tempo-bpm) > 2 {
+			t.Fatalf("Expected %v BPM, got %v", bpm, tempo)
+		}
+	}
+	if _, err := EstimateTempo(Envelope{Values: make([]float64, 10), Rate: 43}, TempoOptions{}); err == nil {
+		t.Fatalf("Expected an error for a short envelope")
+	}
+}
+
+func TestBeats(t *testing.T) {
+	expected := metronome(120, 0.25, 20)
+	// add some off-beat clicks that should not become beats
+	w := clicks(append(append([]float64{}, expected...), 1.6, 3.4, 6.1), 10.5)
+	beats, bpm, err := Beats(w, OnsetOptions{}, BeatOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(bpm-120) > 2 {
+		t.Fatalf("Expected 120 BPM, got %v", bpm)
+	}
+	if matches(expected, beats, 0.035) < len(expected)-1 {
+		t.Fatalf("Expected beats at %v, got %v", expected, beats)
+	}
+	if len(beats) > len(expected)+1 {
+		t.Fatalf("Expected %v beats, got %v", len(expected), len(beats))
+	}
+
+	// a fixed tempo should be followed
+	_, bpm, err = Beats(w, OnsetOptions{}, BeatOptions{BPM: 60})
+	if err != nil || bpm != 60 {
+		t.Fatalf("Expected a fixed tempo of 60 BPM, got %v (%v)", bpm, err)
+	}
+}
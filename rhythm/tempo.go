@@ -0,0 +1,90 @@
+package rhythm
+
+import (
+	"errors"
+	"math"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// TempoOptions configure the tempo estimation.
+// Zero values are replaced by the defaults.
+type TempoOptions struct {
+	MinBPM, MaxBPM float64 // range of tempi to consider, default 60 - 200
+	// PreferredBPM is the centre of a log-Gaussian weighting (one octave wide) that
+	// resolves the ambiguity between a tempo and its double or half, default 120
+	PreferredBPM float64
+}
+
+func (o TempoOptions) withDefaults() TempoOptions {
+	if o.MinBPM == 0 {
+		o.MinBPM = 60
+	}
+	if o.MaxBPM == 0 {
+		o.MaxBPM = 200
+	}
+	if o.PreferredBPM == 0 {
+		o.PreferredBPM = 120
+	}
+	return o
+}
+
+// EstimateTempo estimates the tempo (in beats per minute) from the autocorrelation of an
+// onset envelope
+func EstimateTempo(env Envelope, opts TempoOptions) (float64, error) {
+	opts = opts.withDefaults()
+	if opts.MinBPM <= 0 || opts.MaxBPM <= opts.MinBPM {
+		return 0, errors.New("Tempo range should be positive and MinBPM < MaxBPM")
+	}
+	if env.Rate <= 0 {
+		return 0, errors.New("Envelope rate should be positive")
+	}
+
+	minLag := int(math.Floor(60 * env.Rate / opts.MaxBPM))
+	maxLag := int(math.Ceil(60 * env.Rate / opts.MinBPM))
+	if minLag < 1 {
+		minLag = 1
+	}
+	if maxLag+1 >= len(env.Values) {
+		return 0, errors.New("Envelope too short for the minimum tempo")
+	}
+
+	mean := 0.0
+	for _, v := range env.Values {
+		mean += v
+	}
+	mean /= float64(len(env.Values))
+	centred := make([]wave.Frame, len(env.Values))
+	for i, v := range env.Values {
+		centred[i] = wave.Frame(v - mean)
+	}
+	corr := audiomath.AutoCorrelate(centred)
+
+	weighted := make([]float64, maxLag+2)
+	for lag := 1; lag < len(weighted); lag++ {
+		bpm := 60 * env.Rate / float64(lag)
+		octaves := math.Log2(bpm / opts.PreferredBPM)
+		weighted[lag] = corr[lag] * math.Exp(-0.5*octaves*octaves)
+	}
+	best := minLag
+	for lag := minLag; lag <= maxLag; lag++ {
+		if weighted[lag] > weighted[best] {
+			best = lag
+		}
+	}
+	if weighted[best] <= 0 {
+		return 0, errors.New("No periodicity found in the envelope")
+	}
+	lag := float64(best) + audiomath.ParabolicOffset(weighted, best)
+	return 60 * env.Rate / lag, nil
+}
+
+// Tempo estimates the tempo of the wave in beats per minute
+func Tempo(w wave.Wave, onset OnsetOptions, opts TempoOptions) (float64, error) {
+	env, err := OnsetEnvelope(w, onset)
+	if err != nil {
+		return 0, err
+	}
+	return EstimateTempo(env, opts)
+}
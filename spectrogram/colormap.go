@@ -0,0 +1,72 @@
+package spectrogram
+
+import (
+	"image/color"
+	"math"
+)
+
+// Colormap turns a normalised intensity in [0, 1] into a color
+type Colormap func(v float64) color.RGBA
+
+// colorStop is a control point of an interpolated colormap
+type colorStop struct {
+	pos     float64
+	r, g, b float64
+}
+
+var (
+	viridisStops = []colorStop{
+		{0, 68, 1, 84}, {0.25, 59, 82, 139}, {0.5, 33, 145, 140}, {0.75, 94, 201, 98}, {1, 253, 231, 37},
+	}
+	magmaStops = []colorStop{
+		{0, 0, 0, 4}, {0.25, 81, 18, 124}, {0.5, 183, 55, 121}, {0.75, 252, 137, 97}, {1, 252, 253, 191},
+	}
+	infernoStops = []colorStop{
+		{0, 0, 0, 4}, {0.25, 87, 16, 110}, {0.5, 188, 55, 84}, {0.75, 249, 142, 9}, {1, 252, 255, 164},
+	}
+	jetStops = []colorStop{
+		{0, 0, 0, 128}, {0.125, 0, 0, 255}, {0.375, 0, 255, 255}, {0.625, 255, 255, 0}, {0.875, 255, 0, 0}, {1, 128, 0, 0},
+	}
+	grayStops = []colorStop{
+		{0, 0, 0, 0}, {1, 255, 255, 255},
+	}
+)
+
+// Colormaps that can be used for rendering
+var (
+	Grayscale = interpolated(grayStops)
+	Viridis   = interpolated(viridisStops)
+	Magma     = interpolated(magmaStops)
+	Inferno   = interpolated(infernoStops)
+	Jet       = interpolated(jetStops)
+
+	// Colormaps by name, for use in command line tools
+	Colormaps = map[string]Colormap{
+		"gray":    Grayscale,
+		"viridis": Viridis,
+		"magma":   Magma,
+		"inferno": Inferno,
+		"jet":     Jet,
+	}
+)
+
+// interpolated creates a colormap by linear interpolation between the stops
+func interpolated(stops []colorStop) Colormap {
+	return func(v float64) color.RGBA {
+		if math.IsNaN(v) || v < 0 {
+			v = 0
+		} else if v > 1 {
+			v = 1
+		}
+		i := 1
+		for i < len(stops)-1 && stops[i].pos < v {
+			i++
+		}
+		lo, hi := stops[i-1], stops[i]
+		frac := (v - lo.pos) / (hi.pos - lo.pos)
+		mix := func(a, b float64) uint8 {
+			return uint8(math.Round(a + (b-a)*frac))
+		}
+		return color.RGBA{mix(lo.r, hi.r), mix(lo.g, hi.g), mix(lo.b, hi.b), 255}
+	}
+}
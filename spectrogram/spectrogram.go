@@ -0,0 +1,186 @@
+// Package spectrogram renders the spectrum of audio over time into an image.
+package spectrogram
+
+import (
+	"errors"
+	"image"
+	"math"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/features"
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// Scale of the frequency (vertical) axis
+type Scale int
+
+// Scales for the frequency axis
+const (
+	LINEAR Scale = iota
+	LOG
+	MEL
+)
+
+var (
+	// Scales by name, for use in command line tools
+	Scales = map[string]Scale{
+		"linear": LINEAR,
+		"log":    LOG,
+		"mel":    MEL,
+	}
+)
+
+// Options for rendering a spectrogram, the zero value of each field picks a default
+type Options struct {
+	FrameSize int       // samples per STFT frame, 2048 by default
+	HopSize   int       // samples between frames (one column each), FrameSize/4 by default
+	Window    []float64 // analysis window, periodic Hann by default
+	Scale     Scale     // frequency axis
+	MinFreq   float64   // lowest frequency shown, 0 Hz (20 Hz on a log scale) by default
+	MaxFreq   float64   // highest frequency shown, Nyquist by default
+	Floor     float64   // level in dBFS that maps to the bottom of the colormap, -100 by default
+	Height    int       // image height in pixels, FrameSize/2+1 by default
+	Colormap  Colormap  // Viridis by default
+}
+
+// withDefaults fills in the defaults for the given sample rate
+func (o Options) withDefaults(sr int) (Options, error) {
+	if o.FrameSize == 0 {
+		o.FrameSize = 2048
+	}
+	if o.HopSize == 0 {
+		o.HopSize = o.FrameSize / 4
+	}
+	if o.Window == nil {
+		o.Window = window.Hann(o.FrameSize, window.PERIODIC)
+	}
+	if o.MinFreq == 0 && o.Scale == LOG {
+		o.MinFreq = 20
+	}
+	if o.MaxFreq == 0 {
+		o.MaxFreq = float64(sr) / 2
+	}
+	if o.Floor == 0 {
+		o.Floor = -100
+	}
+	if o.Height == 0 {
+		o.Height = o.FrameSize/2 + 1
+	}
+	if o.Colormap == nil {
+		o.Colormap = Viridis
+	}
+
+	if sr <= 0 {
+		return o, errors.New("Need a positive sample rate")
+	}
+	if o.MinFreq < 0 || o.MaxFreq <= o.MinFreq {
+		return o, errors.New("Invalid frequency range")
+	}
+	if o.Scale == LOG && o.MinFreq <= 0 {
+		return o, errors.New("Log scale needs a positive minimum frequency")
+	}
+	if o.Height < 2 {
+		return o, errors.New("Height should be at least 2 pixels")
+	}
+	if o.Floor >= 0 {
+		return o, errors.New("Floor should be below 0 dBFS")
+	}
+	return o, nil
+}
+
+// Render draws a spectrogram of each channel of the wave
+func Render(w wave.Wave, opts Options) ([]*image.RGBA, error) {
+	channels := wave.SplitChannels(w.Frames, w.NumChannels)
+	out := make([]*image.RGBA, len(channels))
+	for c, frames := range channels {
+		img, err := RenderChannel(frames, w.SampleRate, opts)
+		if err != nil {
+			return nil, err
+		}
+		out[c] = img
+	}
+	return out, nil
+}
+
+// RenderChannel draws a spectrogram of a single channel.
+// Time runs from left to right with one column per STFT frame, frequency from bottom to top.
+func RenderChannel(frames []wave.Frame, sr int, opts Options) (*image.RGBA, error) {
+	opts, err := opts.withDefaults(sr)
+	if err != nil {
+		return nil, err
+	}
+	cfg := audiomath.STFTConfig{
+		FrameSize: opts.FrameSize,
+		HopSize:   opts.HopSize,
+		Window:    opts.Window,
+	}
+	spectrum, err := audiomath.STFT(frames, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// scale magnitudes so a full scale sine reads 0 dBFS
+	norm := 2 / (window.CoherentGain(opts.Window) * float64(opts.FrameSize))
+	binWidth := float64(sr) / float64(opts.FrameSize)
+
+	// the range of bins each row covers
+	rows := make([][2]float64, opts.Height)
+	for y := range rows {
+		lo := axisFrequency(opts, (float64(y)-0.5)/float64(opts.Height-1))
+		hi := axisFrequency(opts, (float64(y)+0.5)/float64(opts.Height-1))
+		rows[y] = [2]float64{lo / binWidth, hi / binWidth}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, len(spectrum), opts.Height))
+	levels := make([]float64, cfg.Bins())
+	for x, bins := range spectrum {
+		for k, b := range bins {
+			levels[k] = cmplx.Abs(b) * norm
+		}
+		for y, r := range rows {
+			db := 20 * math.Log10(peak(levels, r[0], r[1]))
+			v := 1 - db/opts.Floor
+			// row 0 is the top of the image, which holds the highest frequency
+			img.SetRGBA(x, opts.Height-1-y, opts.Colormap(v))
+		}
+	}
+	return img, nil
+}
+
+// axisFrequency returns the frequency at position t in [0, 1] along the vertical axis
+func axisFrequency(opts Options, t float64) float64 {
+	switch opts.Scale {
+	case LOG:
+		return opts.MinFreq * math.Pow(opts.MaxFreq/opts.MinFreq, t)
+	case MEL:
+		lo, hi := features.HzToMel(opts.MinFreq, features.HTK), features.HzToMel(opts.MaxFreq, features.HTK)
+		return features.MelToHz(lo+(hi-lo)*t, features.HTK)
+	}
+	return opts.MinFreq + (opts.MaxFreq-opts.MinFreq)*t
+}
+
+// peak returns the largest level between two fractional bins, interpolating when the
+// range falls between two bins
+func peak(levels []float64, lo, hi float64) float64 {
+	max := interpolate(levels, (lo+hi)/2)
+	for k := int(math.Ceil(lo)); k <= int(math.Floor(hi)) && k < len(levels); k++ {
+		if k >= 0 && levels[k] > max {
+			max = levels[k]
+		}
+	}
+	return max
+}
+
+func interpolate(levels []float64, pos float64) float64 {
+	if pos <= 0 {
+		return levels[0]
+	}
+	if pos >= float64(len(levels)-1) {
+		return levels[len(levels)-1]
+	}
+	i := int(pos)
+	frac := pos - float64(i)
+	return levels[i] + (levels[i+1]-levels[i])*frac
+}
@@ -0,0 +1,108 @@
+package spectrogram
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/features"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	// the row holding a 1 kHz sine at 8 kHz, for each scale
+	sineRowTests = []struct {
+		opts Options
+		row  float64 // fraction of the height, from the bottom
+	}{
+		{Options{FrameSize: 256, Scale: LINEAR}, 0.25},
+		{Options{FrameSize: 256, Scale: LOG, MinFreq: 100, MaxFreq: 4000, Height: 200}, math.Log(10) / math.Log(40)},
+		{Options{FrameSize: 256, Scale: MEL, Height: 100}, features.HzToMel(1000, features.HTK) / features.HzToMel(4000, features.HTK)},
+	}
+)
+
+func sine(freq float64, sr, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(math.Sin(2 * math.Pi * freq * float64(i) / float64(sr)))
+	}
+	return out
+}
+
+func brightness(c color.RGBA) int {
+	return int(c.R) + int(c.G) + int(c.B)
+}
+
+func TestSineRow(t *testing.T) {
+	frames := sine(1000, 8000, 8000)
+	for _, test := range sineRowTests {
+		t.Run("", func(t *testing.T) {
+			img, err := RenderChannel(frames, 8000, test.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			bounds := img.Bounds()
+			x := bounds.Dx() / 2
+			brightest := 0
+			for y := 0; y < bounds.Dy(); y++ {
+				if brightness(img.RGBAAt(x, y)) > brightness(img.RGBAAt(x, brightest)) {
+					brightest = y
+				}
+			}
+			expected := int(math.Round(float64(bounds.Dy()-1) * (1 - test.row)))
+			if brightest < expected-1 || brightest > expected+1 {
+				t.Fatalf("Expected brightest row around %v, got %v", expected, brightest)
+			}
+			// a full scale sine should hit the top of the colormap
+			if c := img.RGBAAt(x, brightest); c != Viridis(1) {
+				t.Fatalf("Expected the peak to be %v, got %v", Viridis(1), c)
+			}
+		})
+	}
+}
+
+func TestRenderChannels(t *testing.T) {
+	left, right := sine(500, 8000, 4000), sine(2000, 8000, 4000)
+	w := wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, 8000, 16, nil),
+		WaveData: wave.WaveData{Frames: wave.JoinChannels([][]wave.Frame{left, right})},
+	}
+	imgs, err := Render(w, Options{FrameSize: 512, HopSize: 256, Height: 64})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(imgs) != 2 {
+		t.Fatalf("Expected an image per channel, got %v", len(imgs))
+	}
+	for _, img := range imgs {
+		if img.Bounds().Dy() != 64 || img.Bounds().Dx() != 4000/256+1 {
+			t.Fatalf("Unexpected image size %v", img.Bounds())
+		}
+	}
+}
+
+func TestColormaps(t *testing.T) {
+	if c := Grayscale(0); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Fatalf("Expected black, got %v", c)
+	}
+	if c := Grayscale(2); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatalf("Expected white, got %v", c)
+	}
+	if c := Jet(0.5); c != (color.RGBA{128, 255, 128, 255}) {
+		t.Fatalf("Expected halfway between cyan and yellow, got %v", c)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	frames := sine(1000, 8000, 1000)
+	invalid := []Options{
+		{Floor: 10},
+		{MinFreq: 3000, MaxFreq: 1000},
+		{Height: 1},
+	}
+	for _, opts := range invalid {
+		if _, err := RenderChannel(frames, 8000, opts); err == nil {
+			t.Fatalf("Expected an error for %+v", opts)
+		}
+	}
+}
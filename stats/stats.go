@@ -0,0 +1,175 @@
+// Package stats describes the signal in a wave: levels, DC offset, clipping, silence and
+// the correlation between channels.
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// DB is a level in decibels. Silence gives -Inf, which is encoded as null in JSON.
+type DB float64
+
+// MarshalJSON encodes infinite and NaN levels as null
+func (d DB) MarshalJSON() ([]byte, error) {
+	f := float64(d)
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Options configure the analysis.
+// Zero values are replaced by the defaults.
+type Options struct {
+	// ClipLevel is the absolute sample value from which a sample counts as clipped,
+	// default 1 (full scale)
+	ClipLevel float64
+	// SilenceThreshold is the level in dBFS below which a window is silent, default -60
+	SilenceThreshold float64
+	// SilenceWindow is the length in seconds of the windows checked for silence,
+	// default 10 ms
+	SilenceWindow float64
+}
+
+// Channel holds the statistics of a single channel
+type Channel struct {
+	Peak          DB      `json:"peak_dbfs"`
+	RMS           DB      `json:"rms_dbfs"`
+	CrestFactor   DB      `json:"crest_factor_db"`
+	DCOffset      float64 `json:"dc_offset"`
+	Clipped       int     `json:"clipped_samples"`
+	Silence       float64 `json:"silence_percent"`
+	ZeroCrossings float64 `json:"zero_crossing_rate"` // per second
+}
+
+// Stats holds the statistics of every channel.
+// Correlation is only set for stereo waves.
+type Stats struct {
+	Channels    []Channel `json:"channels"`
+	Correlation *float64  `json:"correlation,omitempty"`
+}
+
+func (o Options) withDefaults() Options {
+	if o.ClipLevel == 0 {
+		o.ClipLevel = 1
+	}
+	if o.SilenceThreshold == 0 {
+		o.SilenceThreshold = -60
+	}
+	if o.SilenceWindow == 0 {
+		o.SilenceWindow = 0.01
+	}
+	return o
+}
+
+// Analyze computes the statistics of every channel of the wave
+func Analyze(w wave.Wave, opts Options) (Stats, error) {
+	opts = opts.withDefaults()
+	if w.NumChannels <= 0 || w.SampleRate <= 0 {
+		return Stats{}, errors.New("Channels and sample rate should be positive")
+	}
+	channels := wave.SplitChannels(w.Frames, w.NumChannels)
+	out := Stats{Channels: make([]Channel, len(channels))}
+	for i, ch := range channels {
+		out.Channels[i] = analyzeChannel(ch, w.SampleRate, opts)
+	}
+	if len(channels) == 2 {
+		c := Correlation(channels[0], channels[1])
+		out.Correlation = &c
+	}
+	return out, nil
+}
+
+func analyzeChannel(frames []wave.Frame, sr int, opts Options) Channel {
+	if len(frames) == 0 {
+		inf := DB(math.Inf(-1))
+		return Channel{Peak: inf, RMS: inf, CrestFactor: DB(math.NaN())}
+	}
+	var peak, sum, sumSq float64
+	clipped, crossings := 0, 0
+	for i, f := range frames {
+		x := float64(f)
+		a := math.Abs(x)
+		peak = math.Max(peak, a)
+		sum += x
+		sumSq += x * x
+		if a >= opts.ClipLevel {
+			clipped++
+		}
+		if i > 0 && (x >= 0) != (frames[i-1] >= 0) {
+			crossings++
+		}
+	}
+	n := float64(len(frames))
+	rms := math.Sqrt(sumSq / n)
+	return Channel{
+		Peak:          DB(audiomath.AmplitudeToDB(peak)),
+		RMS:           DB(audiomath.AmplitudeToDB(rms)),
+		CrestFactor:   DB(audiomath.AmplitudeToDB(peak / rms)),
+		DCOffset:      sum / n,
+		Clipped:       clipped,
+		Silence:       silence(frames, sr, opts),
+		ZeroCrossings: float64(crossings) * float64(sr) / n,
+	}
+}
+
+// silence returns the percentage of windows with an RMS below the threshold
+func silence(frames []wave.Frame, sr int, opts Options) float64 {
+	size := int(opts.SilenceWindow * float64(sr))
+	if size < 1 {
+		size = 1
+	}
+	threshold := math.Pow(10, opts.SilenceThreshold/20)
+	windows, silent := 0, 0
+	for start := 0; start < len(frames); start += size {
+		end := start + size
+		if end > len(frames) {
+			end = len(frames)
+		}
+		sumSq := 0.0
+		for _, f := range frames[start:end] {
+			sumSq += float64(f) * float64(f)
+		}
+		if math.Sqrt(sumSq/float64(end-start)) < threshold {
+			silent++
+		}
+		windows++
+	}
+	return 100 * float64(silent) / float64(windows)
+}
+
+// Correlation returns the Pearson correlation of two channels, between -1 (out of phase)
+// and 1 (identical up to the gain). Silent channels have a correlation of 0.
+func Correlation(a, b []wave.Frame) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 0
+	}
+	var meanA, meanB float64
+	for i := 0; i < n; i++ {
+		meanA += float64(a[i])
+		meanB += float64(b[i])
+	}
+	meanA /= float64(n)
+	meanB /= float64(n)
+
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		da, db := float64(a[i])-meanA, float64(b[i])-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 1000
+
+func sine(freq, amp float64, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(amp * math.Sin(2*math.Pi*freq*(float64(i)+0.5)/sr))
+	}
+	return out
+}
+
+func stereo(left, right []wave.Frame) wave.Wave {
+	return wave.Wave{
+		WaveFmt:  wave.NewWaveFmt(1, 2, sr, 16, nil),
+		WaveData: wave.WaveData{Frames: wave.JoinChannels([][]wave.Frame{left, right})},
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestAnalyze(t *testing.T) {
+	left := sine(50, 0.5, 1000)
+	// the right channel is clipped, has a DC offset and is silent for the second half
+	right := sine(10, 2, 1000)
+	for i := range right {
+		right[i] = wave.Frame(math.Max(-1, math.Min(1, float64(right[i]))))/2 + 0.1
+		if i >= 500 {
+			right[i] = 0
+		}
+	}
+
+	s, err := Analyze(stereo(left, right), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	l, r := s.Channels[0], s.Channels[1]
+	// the samples straddle the peaks of the sine, so they're a bit lower than -6 dBFS
+	if math.Abs(float64(l.Peak)+6.02) > 0.2 || !near(float64(l.RMS), -9.03) || !near(float64(l.CrestFactor), float64(l.Peak-l.RMS)) {
+		t.Fatalf("Unexpected levels for a sine at -6 dBFS: %+v", l)
+	}
+	if !near(l.DCOffset, 0) || l.Clipped != 0 || l.Silence != 0 {
+		t.Fatalf("Unexpected statistics for a clean sine: %+v", l)
+	}
+	// 50 Hz crosses zero 100 times per second, the start doesn't count as one
+	if math.Abs(l.ZeroCrossings-100) > 1 {
+		t.Fatalf("Expected 100 zero crossings per second, got %v", l.ZeroCrossings)
+	}
+
+	if !near(float64(r.Peak), 20*math.Log10(0.6)) {
+		t.Fatalf("Expected a peak of %v, got %v", 20*math.Log10(0.6), r.Peak)
+	}
+	if !near(r.Silence, 50) {
+		t.Fatalf("Expected 50%% silence, got %v", r.Silence)
+	}
+	if r.DCOffset < 0.04 {
+		t.Fatalf("Expected a DC offset, got %v", r.DCOffset)
+	}
+
+	// the clipping is counted at the clip level
+	s, _ = Analyze(stereo(left, right), Options{ClipLevel: 0.6})
+	if s.Channels[1].Clipped == 0 || s.Channels[0].Clipped != 0 {
+		t.Fatalf("Expected clipped samples on the right only, got %+v", s.Channels)
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	a := sine(25, 0.5, 1000)
+	inverted := make([]wave.Frame, len(a))
+	for i := range a {
+		inverted[i] = -a[i] / 2
+	}
+	tests := []struct {
+		a, b     []wave.Frame
+		expected float64
+	}{
+		{a, a, 1},
+		{a, inverted, -1},
+		// sine and cosine are uncorrelated, 10 samples is a quarter period
+		{a[:200], a[10:210], 0},
+		{a, make([]wave.Frame, 1000), 0},
+	}
+	for _, test := range tests {
+		if c := Correlation(test.a, test.b); !near(c, test.expected) {
+			t.Fatalf("Expected a correlation of %v, got %v", test.expected, c)
+		}
+	}
+
+	s, _ := Analyze(stereo(a, inverted), Options{})
+	if s.Correlation == nil || !near(*s.Correlation, -1) {
+		t.Fatalf("Expected a stereo correlation of -1")
+	}
+	mono := wave.Wave{WaveFmt: wave.NewWaveFmt(1, 1, sr, 16, nil), WaveData: wave.WaveData{Frames: a}}
+	if s, _ := Analyze(mono, Options{}); s.Correlation != nil {
+		t.Fatalf("Expected no correlation for mono")
+	}
+}
+
+// TestJSON makes sure silence (-Inf dBFS) can be encoded
+func TestJSON(t *testing.T) {
+	silent := make([]wave.Frame, 100)
+	s, _ := Analyze(stereo(silent, silent), Options{})
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(b), `"peak_dbfs":null`) || !strings.Contains(string(b), `"silence_percent":100`) {
+		t.Fatalf("Unexpected JSON: %s", b)
+	}
+}
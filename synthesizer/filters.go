@@ -6,6 +6,7 @@ import (
 
 // Lowpass applies a low-pass filter to the frames
 // Does not modify the input signal
+// The state is lost between calls, filter.Biquad keeps it when filtering in blocks.
 func Lowpass(fs []float64, freq, delay, sr float64) []float64 {
 	output := make([]float64, len(fs))
 	copy(output, fs)
@@ -23,6 +24,7 @@ func Lowpass(fs []float64, freq, delay, sr float64) []float64 {
 
 // Highpass applies a high-pass filter to the frames.
 // Does not modify the input signal
+// The state is lost between calls, filter.Biquad keeps it when filtering in blocks.
 func Highpass(fs []float64, freq, delay, sr float64) []float64 {
 	output := make([]float64, len(fs))
 	copy(output, fs)
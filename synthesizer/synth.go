@@ -116,3 +116,36 @@ func ParseNoteToFrequency(note string) (float64, error) {
 	}
 	return NoteToFrequency(nt, oct), nil
 }
+
+// NoteName returns the name of the note at the given index of the 12-tone scale, counting
+// from A like NoteToFrequency does (0 = A, 3 = C, ..). Sharps are preferred over flats.
+func NoteName(index int) string {
+	index = ((index % 12) + 12) % 12
+	for name, i := range noteIndex {
+		if i == index && (len(name) == 1 || strings.HasSuffix(name, "#")) {
+			return strings.ToUpper(name)
+		}
+	}
+	return ""
+}
+
+// FrequencyToNote finds the nearest note to a frequency using Equal-Tempered tuning with
+// reference pitch = A440. It returns the note, its octave and how many cents the frequency
+// deviates from it (-50 to 50).
+func FrequencyToNote(freq float64) (string, int, float64) {
+	if freq <= 0 {
+		return "", 0, 0
+	}
+	semitones := 12 * math.Log2(freq/440.)
+	nearest := math.Round(semitones)
+	cents := 100 * (semitones - nearest)
+
+	n := int(nearest)
+	index := ((n % 12) + 12) % 12
+	octave := 4 + int(math.Floor(float64(n)/12))
+	if index >= 3 {
+		// octaves start at C, not A.
+		octave++
+	}
+	return NoteName(index), octave, cents
+}
@@ -1,6 +1,8 @@
 package synthesizer_test
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
 	synth "github.com/DylanMeeus/GoAudio/synthesizer"
@@ -23,6 +25,22 @@ var (
 		{"a", 2, 110.},
 	}
 
+	frequencyToNoteTests = []struct {
+		freq   float64
+		note   string
+		octave int
+		cents  float64
+	}{
+		{440., "A", 4, 0},
+		{261.63, "C", 4, 0},
+		{466.16, "A#", 4, 0},
+		{27.5, "A", 0, 0},
+		{4186.01, "C", 8, 0},
+		{123.47, "B", 2, 0},
+		{445., "A", 4, 19.56},
+		{435., "A", 4, -19.79},
+	}
+
 	parseNoteFrequencyTests = []struct {
 		in  string
 		out float64
@@ -55,6 +73,27 @@ func TestNoteToFrequency(t *testing.T) {
 	}
 }
 
+// TestFrequencyToNote makes sure frequencies are mapped to the nearest note and cents offset
+func TestFrequencyToNote(t *testing.T) {
+	for _, test := range frequencyToNoteTests {
+		t.Run("", func(t *testing.T) {
+			note, octave, cents := synth.FrequencyToNote(test.freq)
+			if note != test.note || octave != test.octave || !floatFuzzyEquals(cents, test.cents) {
+				t.Fatalf("Expected %v%v (%v cents) but got %v%v (%v cents) for %v",
+					test.note, test.octave, test.cents, note, octave, cents, test.freq)
+			}
+			// the note should parse back to (almost) the same frequency
+			freq, err := synth.ParseNoteToFrequency(fmt.Sprintf("%v%v", note, octave))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(1200*math.Log2(test.freq/freq)-cents) > 0.1 {
+				t.Fatalf("%v%v parsed to %v, which is not %v cents from %v", note, octave, freq, cents, test.freq)
+			}
+		})
+	}
+}
+
 // TestParseNoteFrequency tests a selection of strings containing note+octave and ensures that the
 // output is the expected frequency
 func TestParseNoteFrequency(t *testing.T) {
@@ -0,0 +1,347 @@
+// Package vocoder changes the duration of audio without changing its pitch, and the pitch
+// without changing the duration, with a phase vocoder.
+//
+// Frames are analysed with the STFT and resynthesized with a fixed hop. Reading the input
+// faster or slower than the output is written stretches time, the phases of every bin are
+// advanced according to their measured frequency so the pitch stays the same.
+package vocoder
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	"github.com/DylanMeeus/GoAudio/breakpoint"
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/resample"
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// Options configure the phase vocoder.
+// Zero values for the sizes are replaced by the defaults.
+type Options struct {
+	FrameSize int // analysis and synthesis frame size, default 2048
+	HopSize   int // synthesis hop, default FrameSize/4
+	// PhaseLocking keeps the bins around every spectral peak in phase with the peak
+	// (identity phase locking, Laroche & Dolson), which reduces the "phasiness"
+	PhaseLocking bool
+	// TransientThreshold is the fraction of bins that have to rise by 6 dB for a frame to
+	// count as a transient. The phases are reset on transients and the frames around them
+	// are copied without stretching, so they stay sharp. The time this costs is made up
+	// in the frames after the transient.
+	// 0 disables transient preservation, 0.3 is a reasonable value.
+	TransientThreshold float64
+}
+
+func (o Options) validate() (Options, error) {
+	if o.FrameSize == 0 {
+		o.FrameSize = 2048
+	}
+	if o.HopSize == 0 {
+		o.HopSize = o.FrameSize / 4
+	}
+	if o.FrameSize < 4 || o.HopSize <= 0 || o.HopSize > o.FrameSize/2 {
+		return o, errors.New("HopSize should be between 1 and FrameSize/2")
+	}
+	if o.TransientThreshold < 0 || o.TransientThreshold > 1 {
+		return o, errors.New("TransientThreshold should be between 0 and 1")
+	}
+	return o, nil
+}
+
+// Stretch changes the duration of interleaved frames by factor without changing the pitch.
+// A factor of 2 makes the audio twice as long (half speed).
+func Stretch(frames []wave.Frame, channels int, factor float64, opts Options) ([]wave.Frame, error) {
+	if factor <= 0 {
+		return nil, errors.New("Stretch factor should be positive")
+	}
+	return stretch(frames, channels, func(int) float64 { return factor }, opts)
+}
+
+// StretchStream stretches with a factor that changes over time. The stream is advanced by
+// one tick per input sample (per channel), so it should be created with the sample rate
+// of the frames and its times are positions in the input.
+func StretchStream(frames []wave.Frame, channels int, stream *breakpoint.BreakpointStream, opts Options) ([]wave.Frame, error) {
+	if stream == nil {
+		return nil, errors.New("Need a breakpoint stream")
+	}
+	ticked := 0
+	factor := stream.Tick()
+	return stretch(frames, channels, func(pos int) float64 {
+		for ; ticked < pos; ticked++ {
+			factor = stream.Tick()
+		}
+		return factor
+	}, opts)
+}
+
+// PitchShift changes the pitch of interleaved frames by a number of semitones without
+// changing the duration: the audio is stretched by the frequency ratio and resampled back
+// to the original length.
+func PitchShift(frames []wave.Frame, channels, sr int, semitones float64, opts Options) ([]wave.Frame, error) {
+	if channels <= 0 || sr <= 0 {
+		return nil, errors.New("Channels and sample rate should be positive")
+	}
+	ratio := math.Pow(2, semitones/12)
+	stretched, err := Stretch(frames, channels, ratio, opts)
+	if err != nil {
+		return nil, err
+	}
+	// playing the stretched audio ratio times faster restores the duration
+	from := int(math.Round(float64(sr) * ratio))
+	out, err := resample.Resample(stretched, channels, from, sr, resample.MEDIUM)
+	if err != nil {
+		return nil, err
+	}
+	// trim or pad rounding differences, so the length is exactly the same
+	length := len(frames) / channels * channels
+	if len(out) > length {
+		return out[:length], nil
+	}
+	return append(out, make([]wave.Frame, length-len(out))...), nil
+}
+
+// stretch runs the vocoder on every channel. factor returns the stretch factor at an
+// input position (in samples per channel).
+func stretch(frames []wave.Frame, channels int, factor func(int) float64, opts Options) ([]wave.Frame, error) {
+	opts, err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+	if channels <= 0 {
+		return nil, errors.New("Channels should be positive")
+	}
+	n := len(frames) / channels
+	if n == 0 {
+		return []wave.Frame{}, nil
+	}
+
+	// transients are detected on the mix of all channels, so every channel treats them the
+	// same
+	transients := []int{}
+	if opts.TransientThreshold > 0 {
+		transients = detectTransients(wave.MixToMono(frames[:n*channels], channels), opts)
+	}
+
+	// the input positions of the output frames are shared by all channels, frame m is
+	// centred on output sample m * HopSize
+	hop := float64(opts.HopSize)
+	positions := []float64{0}
+	resets := []bool{true}
+	ideal, length := 0.0, 0.0
+	t := 0
+	for {
+		pos := positions[len(positions)-1]
+		f := factor(int(pos))
+		if f <= 0 {
+			return nil, errors.New("Stretch factor should be positive")
+		}
+		step := hop / f
+		at := ideal
+		ideal += step
+
+		next := pos + step
+		if len(transients) > 0 {
+			// drift back to where we should have been, without changing the speed by
+			// more than a factor of 2
+			next = pos + math.Max(step/2, math.Min(2*step, step-(pos-ideal+step)/8))
+		}
+		// frames that contain a transient aren't stretched
+		for t < len(transients) && transients[t]+opts.FrameSize <= int(next) {
+			t++
+		}
+		entered := false
+		if t < len(transients) && int(next) >= transients[t] {
+			if int(pos) < transients[t] {
+				next, entered = float64(transients[t]), true
+			} else {
+				next = pos + hop
+			}
+		}
+
+		// one frame past the end too, so the end is covered by complete overlap
+		positions = append(positions, next)
+		resets = append(resets, entered)
+		if next >= float64(n) {
+			// the output ends where the input ends, had it been stretched evenly
+			length = float64(len(positions)-2)*hop + (float64(n)-at)*f
+			break
+		}
+	}
+
+	out := make([][]wave.Frame, channels)
+	for c, ch := range wave.SplitChannels(frames[:n*channels], channels) {
+		out[c] = process(ch, positions, resets, int(math.Round(length)), opts)
+	}
+	return wave.JoinChannels(out), nil
+}
+
+// process stretches a single channel, the phases are copied from the analysis on the frames
+// marked in resets
+func process(x []wave.Frame, positions []float64, resets []bool, length int, opts Options) []wave.Frame {
+	size, hop := opts.FrameSize, opts.HopSize
+	win := window.Hann(size, window.PERIODIC)
+	bins := size/2 + 1
+
+	total := (len(positions)-1)*hop + size
+	acc := make([]float64, total)
+	norm := make([]float64, total)
+	phase := make([]float64, bins)
+	prevCentre := 0
+
+	for m, pos := range positions {
+		centre := int(math.Round(pos))
+		cur := audiomath.RFFT(grain(x, centre, win))
+		mags := make([]float64, bins)
+		for k, c := range cur {
+			mags[k] = cmplx.Abs(c)
+		}
+
+		// while the window hangs over the start of the input the relation between the
+		// phases of neighbouring bins is off, propagating them would make later frames
+		// cancel each other. So we copy the phases until we can propagate from a frame
+		// with a complete window.
+		reset := resets[m] || prevCentre < size/2
+		prevCentre = centre
+		if !reset {
+			// the frame one synthesis hop earlier tells how far every phase advances
+			// during a hop
+			prev := audiomath.RFFT(grain(x, centre-hop, win))
+			advance(phase, cur, prev, hop, size)
+		}
+		if reset {
+			for k, c := range cur {
+				phase[k] = cmplx.Phase(c)
+			}
+		} else if opts.PhaseLocking {
+			lockPhases(phase, cur, mags)
+		}
+
+		spectrum := make([]complex128, bins)
+		for k := range spectrum {
+			spectrum[k] = cmplx.Rect(mags[k], phase[k])
+		}
+		y := audiomath.IRFFT(spectrum, size)
+		start := m * hop
+		for i, w := range win {
+			acc[start+i] += y[i] * w
+			norm[start+i] += w * w
+		}
+	}
+
+	// the first frame is centred on output sample 0
+	out := make([]wave.Frame, length)
+	for i := range out {
+		j := i + size/2
+		if j < total && norm[j] > 1e-10 {
+			out[i] = wave.Frame(acc[j] / norm[j])
+		}
+	}
+	return out
+}
+
+// grain returns the windowed frame centred on sample centre, zero outside the signal
+func grain(x []wave.Frame, centre int, win []float64) []wave.Frame {
+	out := make([]wave.Frame, len(win))
+	start := centre - len(win)/2
+	for i, w := range win {
+		if j := start + i; j >= 0 && j < len(x) {
+			out[i] = x[j] * wave.Frame(w)
+		}
+	}
+	return out
+}
+
+// advance adds the phase every bin advances in one hop, measured between prev and cur, to
+// the synthesis phases
+func advance(phase []float64, cur, prev []complex128, hop, size int) {
+	for k := range phase {
+		// the expected advance of the bin centre frequency, plus the deviation
+		expected := 2 * math.Pi * float64(k*hop) / float64(size)
+		delta := cmplx.Phase(cur[k]) - cmplx.Phase(prev[k]) - expected
+		phase[k] += expected + princarg(delta)
+	}
+}
+
+// lockPhases keeps the phase of every bin relative to the nearest spectral peak the same as
+// in the analysis frame
+func lockPhases(phase []float64, cur []complex128, mags []float64) {
+	peaks := []int{}
+	for k := range mags {
+		isPeak := mags[k] > 0
+		for d := -2; d <= 2 && isPeak; d++ {
+			if j := k + d; d != 0 && j >= 0 && j < len(mags) && mags[j] >= mags[k] {
+				isPeak = false
+			}
+		}
+		if isPeak {
+			peaks = append(peaks, k)
+		}
+	}
+	if len(peaks) == 0 {
+		return
+	}
+
+	p := 0
+	for k := range phase {
+		// move to the next peak once we're past the midpoint
+		for p+1 < len(peaks) && k > (peaks[p]+peaks[p+1])/2 {
+			p++
+		}
+		peak := peaks[p]
+		if k != peak {
+			phase[k] = phase[peak] + cmplx.Phase(cur[k]) - cmplx.Phase(cur[peak])
+		}
+	}
+}
+
+// detectTransients returns the positions at which a transient first shows up in a frame, on
+// a grid of HopSize samples. The transient lies within the FrameSize samples after it.
+func detectTransients(x []wave.Frame, opts Options) []int {
+	size, hop := opts.FrameSize, opts.HopSize
+	win := window.Hann(size, window.PERIODIC)
+	out := []int{}
+	prev := audiomath.RFFT(grain(x, 0, win))
+	for centre := hop; centre < len(x)+size/2; centre += hop {
+		cur := audiomath.RFFT(grain(x, centre, win))
+		mags := make([]float64, len(cur))
+		for k, c := range cur {
+			mags[k] = cmplx.Abs(c)
+		}
+		// a transient stays in the frames for a while, only the first one counts
+		fresh := len(out) == 0 || centre >= out[len(out)-1]+size
+		if fresh && isTransient(prev, mags, opts.TransientThreshold) {
+			out = append(out, centre)
+		}
+		prev = cur
+	}
+	return out
+}
+
+// isTransient returns true when at least threshold of the (audible) bins rose by 6 dB
+func isTransient(prev []complex128, mags []float64, threshold float64) bool {
+	max := 0.0
+	for _, m := range mags {
+		max = math.Max(max, m)
+	}
+	if max == 0 {
+		return false
+	}
+	count, rising := 0, 0
+	for k, m := range mags {
+		if m < max*1e-3 {
+			continue
+		}
+		count++
+		if m > 2*cmplx.Abs(prev[k]) {
+			rising++
+		}
+	}
+	return count > 0 && float64(rising) >= threshold*float64(count)
+}
+
+// princarg wraps a phase to -pi .. pi
+func princarg(phase float64) float64 {
+	return phase - 2*math.Pi*math.Round(phase/(2*math.Pi))
+}
@@ -0,0 +1,161 @@
+package vocoder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/breakpoint"
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 22050
+
+func sine(freq float64, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(0.5 * math.Sin(2*math.Pi*freq*float64(i)/sr))
+	}
+	return out
+}
+
+// frequency estimates the frequency of the middle half of a sine from its zero crossings
+func frequency(frames []wave.Frame) float64 {
+	part := frames[len(frames)/4 : 3*len(frames)/4]
+	first, last, crossings := -1, -1, 0
+	for i := 1; i < len(part); i++ {
+		if part[i-1] < 0 && part[i] >= 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			crossings++
+		}
+	}
+	return float64(crossings-1) * sr / float64(last-first)
+}
+
+// rms of the middle half
+func rms(frames []wave.Frame) float64 {
+	part := frames[len(frames)/4 : 3*len(frames)/4]
+	sum := 0.0
+	for _, f := range part {
+		sum += float64(f) * float64(f)
+	}
+	return math.Sqrt(sum / float64(len(part)))
+}
+
+func TestStretch(t *testing.T) {
+	in := sine(440, sr)
+	for _, opts := range []Options{{}, {PhaseLocking: true}, {PhaseLocking: true, TransientThreshold: 0.3}} {
+		for _, factor := range []float64{0.5, 0.75, 1.5, 2, 3.3} {
+			out, err := Stretch(in, 1, factor, opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// transients may leave a rounding difference
+			if expected := sr * factor; math.Abs(float64(len(out))-expected) > 1 {
+				t.Fatalf("Expected %v samples, got %v", expected, len(out))
+			}
+			if f := frequency(out); math.Abs(f-440) > 1 {
+				t.Fatalf("Factor %v: expected 440 Hz, got %v", factor, f)
+			}
+			if r := rms(out); math.Abs(r-rms(in)) > 0.05*rms(in) {
+				t.Fatalf("Factor %v: expected an RMS of %v, got %v", factor, rms(in), r)
+			}
+		}
+	}
+}
+
+// TestIdentity makes sure a factor of 1 gives back the input
+func TestIdentity(t *testing.T) {
+	in := append(sine(440, sr/2), sine(1234, sr/2)...)
+	out, err := Stretch(in, 1, 1, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := range in {
+		if math.Abs(float64(out[i]-in[i])) > 1e-6 {
+			t.Fatalf("Expected sample %v to be %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestPitchShift(t *testing.T) {
+	left, right := sine(440, sr), sine(330, sr)
+	in := wave.JoinChannels([][]wave.Frame{left, right})
+	for _, semitones := range []float64{12, 7, -5, -12} {
+		out, err := PitchShift(in, 2, sr, semitones, Options{PhaseLocking: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(out) != len(in) {
+			t.Fatalf("Expected %v samples, got %v", len(in), len(out))
+		}
+		ratio := math.Pow(2, semitones/12)
+		channels := wave.SplitChannels(out, 2)
+		for c, freq := range []float64{440, 330} {
+			if f := frequency(channels[c]); math.Abs(f-freq*ratio) > 1 {
+				t.Fatalf("%v semitones: expected %v Hz, got %v", semitones, freq*ratio, f)
+			}
+		}
+	}
+}
+
+// TestTransients stretches clicks and expects them to stay as sharp as the originals
+func TestTransients(t *testing.T) {
+	in := make([]wave.Frame, sr)
+	clicks := []int{5000, 12000, 19000}
+	for _, c := range clicks {
+		for i := 0; i < 64; i++ {
+			in[c+i] = wave.Frame(0.8 * math.Exp(-float64(i)/8) * math.Sin(float64(i)))
+		}
+	}
+	peak := func(frames []wave.Frame, centre int) float64 {
+		max := 0.0
+		for i := centre - 1000; i < centre+1000 && i < len(frames); i++ {
+			max = math.Max(max, math.Abs(float64(frames[i])))
+		}
+		return max
+	}
+
+	smeared, _ := Stretch(in, 1, 2, Options{})
+	sharp, _ := Stretch(in, 1, 2, Options{TransientThreshold: 0.3})
+	for _, c := range clicks {
+		if peak(sharp, 2*c) <= peak(smeared, 2*c) {
+			t.Fatalf("Expected a sharper click at %v: %v vs %v", 2*c, peak(sharp, 2*c), peak(smeared, 2*c))
+		}
+		if peak(sharp, 2*c) < 0.5*peak(in, c) {
+			t.Fatalf("Expected the click at %v to keep its peak of %v, got %v", 2*c, peak(in, c), peak(sharp, 2*c))
+		}
+	}
+}
+
+func TestStretchStream(t *testing.T) {
+	in := sine(440, 2*sr)
+	// speed up from a factor of 1 to 2 during the first second, then keep 2
+	stream, _ := breakpoint.NewBreakpointStream([]breakpoint.Breakpoint{{Time: 0, Value: 1}, {Time: 1, Value: 2}, {Time: 2, Value: 2}}, sr)
+	out, err := StretchStream(in, 1, stream, Options{PhaseLocking: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 1.5 seconds for the ramp and 2 for the rest
+	if expected := 3.5 * sr; math.Abs(float64(len(out))-expected) > 512 {
+		t.Fatalf("Expected about %v samples, got %v", expected, len(out))
+	}
+	if f := frequency(out); math.Abs(f-440) > 1 {
+		t.Fatalf("Expected 440 Hz, got %v", f)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	in := sine(440, 1000)
+	if _, err := Stretch(in, 1, 0, Options{}); err == nil {
+		t.Fatalf("Expected an error for a factor of 0")
+	}
+	if _, err := Stretch(in, 1, 1, Options{FrameSize: 1024, HopSize: 1000}); err == nil {
+		t.Fatalf("Expected an error for a hop size larger than half a frame")
+	}
+	if _, err := StretchStream(in, 1, nil, Options{}); err == nil {
+		t.Fatalf("Expected an error without a stream")
+	}
+}
@@ -0,0 +1,189 @@
+package w64
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// ReadW64File parses a .w64 file into a wave.Wave struct
+func ReadW64File(f string) (wave.Wave, error) {
+	file, err := os.Open(f)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+	defer file.Close()
+
+	return ReadW64FromReader(file)
+}
+
+// ReadW64FromReader parses an io.Reader containing .w64 data into a wave.Wave struct
+// Chunks other than fmt and data are skipped.
+func ReadW64FromReader(reader io.Reader) (wave.Wave, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+
+	if len(data) < fileHeaderSize || !bytes.Equal(data[0:16], RiffGUID) {
+		return wave.Wave{}, errors.New("Invalid file, expected riff GUID")
+	}
+	if !bytes.Equal(data[24:40], WaveGUID) {
+		return wave.Wave{}, errors.New("Format should be wave")
+	}
+
+	fileSize := binary.LittleEndian.Uint64(data[16:24])
+	hdr := wave.WaveHeader{
+		ChunkID:   data[0:16],
+		ChunkSize: int(fileSize),
+		Format:    Format,
+	}
+
+	var (
+		wfmt    wave.WaveFmt
+		wd      wave.WaveData
+		hasFmt  bool
+		hasData bool
+	)
+	for pos := fileHeaderSize; pos+chunkHeaderSize <= len(data); {
+		id := data[pos : pos+guidSize]
+		size64 := binary.LittleEndian.Uint64(data[pos+guidSize : pos+chunkHeaderSize])
+		if size64 < chunkHeaderSize {
+			return wave.Wave{}, fmt.Errorf("Invalid chunk size %v", size64)
+		}
+		// compare against what is left before adding, so huge sizes can't overflow
+		remaining := len(data) - pos
+		if size64 > uint64(remaining) {
+			// be lenient with a truncated data chunk, as long as it fits in the file size
+			// the header declares
+			if !bytes.Equal(id, DataGUID) || size64 > fileSize || uint64(pos) > fileSize-size64 {
+				return wave.Wave{}, fmt.Errorf("Invalid chunk size %v, only %v bytes left", size64, remaining)
+			}
+			size64 = uint64(remaining)
+		}
+		size := int(size64)
+		end := pos + size
+		body := data[pos+chunkHeaderSize : end]
+
+		switch {
+		case bytes.Equal(id, FmtGUID):
+			wfmt, err = readFmt(id, body)
+			if err != nil {
+				return wave.Wave{}, err
+			}
+			hasFmt = true
+		case bytes.Equal(id, DataGUID):
+			wd = wave.WaveData{
+				Subchunk2ID:   id,
+				Subchunk2Size: len(body),
+				RawData:       body,
+			}
+			hasData = true
+		}
+		if pad8(size) >= remaining {
+			break
+		}
+		pos += pad8(size)
+	}
+
+	if !hasFmt || !hasData {
+		return wave.Wave{}, errors.New("Invalid file, missing fmt or data chunk")
+	}
+
+	frames, err := parseRawData(wfmt, wd.RawData)
+	if err != nil {
+		return wave.Wave{}, err
+	}
+	wd.Frames = frames
+
+	return wave.Wave{
+		WaveHeader: hdr,
+		WaveFmt:    wfmt,
+		WaveData:   wd,
+	}, nil
+}
+
+// readFmt parses the body of the fmt chunk, which is laid out as in a .wav file
+func readFmt(id, b []byte) (wave.WaveFmt, error) {
+	if len(b) < 16 {
+		return wave.WaveFmt{}, errors.New("Invalid fmt chunk")
+	}
+	wfmt := wave.WaveFmt{
+		Subchunk1ID:   id,
+		Subchunk1Size: len(b),
+		AudioFormat:   int(binary.LittleEndian.Uint16(b[0:2])),
+		NumChannels:   int(binary.LittleEndian.Uint16(b[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(b[4:8])),
+		ByteRate:      int(binary.LittleEndian.Uint32(b[8:12])),
+		BlockAlign:    int(binary.LittleEndian.Uint16(b[12:14])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(b[14:16])),
+	}
+	if len(b) >= 18 {
+		extraSize := int(binary.LittleEndian.Uint16(b[16:18]))
+		if 18+extraSize <= len(b) {
+			wfmt.ExtraParamSize = extraSize
+			wfmt.ExtraParams = b[18 : 18+extraSize]
+		}
+	}
+	if wfmt.NumChannels == 0 {
+		return wave.WaveFmt{}, errors.New("Invalid file, no channels")
+	}
+	return wfmt, nil
+}
+
+// sampleFormat resolves WAVE_FORMAT_EXTENSIBLE to the format of its sub-format GUID
+func sampleFormat(wfmt wave.WaveFmt) int {
+	if wfmt.AudioFormat == FormatExtensible && len(wfmt.ExtraParams) >= 8 {
+		return int(binary.LittleEndian.Uint16(wfmt.ExtraParams[6:8]))
+	}
+	return wfmt.AudioFormat
+}
+
+// parseRawData turns the little-endian sample data into scaled frames
+func parseRawData(wfmt wave.WaveFmt, raw []byte) ([]wave.Frame, error) {
+	format := sampleFormat(wfmt)
+	bits := wfmt.BitsPerSample
+	size := bits / 8
+	if size == 0 {
+		return nil, fmt.Errorf("Unsupported bits per sample %v", bits)
+	}
+
+	n := len(raw) / size
+	frames := make([]wave.Frame, n)
+	for i := 0; i < n; i++ {
+		b := raw[i*size : i*size+size]
+		switch {
+		case format == FormatPCM && bits == 8:
+			// 8-bit samples are stored unsigned
+			frames[i] = scaleFrame(int(b[0])-128, 8)
+		case format == FormatPCM && bits == 16:
+			frames[i] = scaleFrame(int(int16(binary.LittleEndian.Uint16(b))), 16)
+		case format == FormatPCM && bits == 24:
+			v := int(b[2])<<16 | int(b[1])<<8 | int(b[0])
+			if v&0x800000 != 0 {
+				v -= 1 << 24
+			}
+			frames[i] = scaleFrame(v, 24)
+		case format == FormatPCM && bits == 32:
+			frames[i] = scaleFrame(int(int32(binary.LittleEndian.Uint32(b))), 32)
+		case format == FormatFloat && bits == 32:
+			frames[i] = wave.Frame(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		case format == FormatFloat && bits == 64:
+			frames[i] = wave.Frame(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		default:
+			return nil, fmt.Errorf("Format %v with %v bits per sample not supported", format, bits)
+		}
+	}
+	return frames, nil
+}
+
+func scaleFrame(unscaled, bits int) wave.Frame {
+	return wave.Frame(float64(unscaled) / float64(maxValues[bits]))
+}
@@ -0,0 +1,121 @@
+package w64
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+var (
+	roundTripTests = []struct {
+		format    int
+		bits      int
+		tolerance float64
+	}{
+		{FormatPCM, 8, 1. / 127},
+		{FormatPCM, 16, 1. / 32767},
+		{FormatPCM, 24, 1. / 8388607},
+		{FormatPCM, 32, 1. / 2147483647},
+		{FormatFloat, 32, 1e-7},
+		{FormatFloat, 64, 0},
+	}
+
+	// odd number of 8-bit frames forces padding of the data chunk
+	testFrames = []wave.Frame{0, 0.5, -0.5, 0.25, -0.999, 0.999, 0.001}
+)
+
+// TestRoundTrip writes frames in each supported format and reads them back
+func TestRoundTrip(t *testing.T) {
+	for _, test := range roundTripTests {
+		t.Run("", func(t *testing.T) {
+			wfmt := wave.NewWaveFmt(test.format, 1, 96000, test.bits, nil)
+			buf := bytes.Buffer{}
+			if err := WriteW64ToWriter(testFrames, wfmt, &buf); err != nil {
+				t.Fatalf("Should be able to write: %v", err)
+			}
+			if buf.Len()%8 != 0 {
+				t.Fatalf("Expected file to be 8 byte aligned, got %v bytes", buf.Len())
+			}
+			w, err := ReadW64FromReader(&buf)
+			if err != nil {
+				t.Fatalf("Should be able to read: %v", err)
+			}
+			if w.SampleRate != 96000 || w.NumChannels != 1 || w.BitsPerSample != test.bits {
+				t.Fatalf("Unexpected fmt: %+v", w.WaveFmt)
+			}
+			if len(w.Frames) != len(testFrames) {
+				t.Fatalf("Expected %v frames, got %v", len(testFrames), len(w.Frames))
+			}
+			for i := range testFrames {
+				if math.Abs(float64(w.Frames[i]-testFrames[i])) > test.tolerance {
+					t.Fatalf("Expected %v, got %v at %v", testFrames[i], w.Frames[i], i)
+				}
+			}
+		})
+	}
+}
+
+// TestSkipUnknownChunks makes sure chunks we don't know about are ignored
+func TestSkipUnknownChunks(t *testing.T) {
+	wfmt := wave.NewWaveFmt(FormatPCM, 1, 44100, 16, nil)
+	raw, _ := samplesToRawData(testFrames, wfmt)
+	junk := make([]byte, 16)
+	copy(junk, []byte("junk"))
+
+	b := make([]byte, fileHeaderSize)
+	copy(b, RiffGUID)
+	copy(b[24:], WaveGUID)
+	b = append(b, chunk(junk, []byte{1, 2, 3})...)
+	b = append(b, chunk(FmtGUID, fmtToBytes(wfmt))...)
+	b = append(b, chunk(DataGUID, raw)...)
+
+	w, err := ReadW64FromReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Should be able to read: %v", err)
+	}
+	if len(w.Frames) != len(testFrames) {
+		t.Fatalf("Expected %v frames, got %v", len(testFrames), len(w.Frames))
+	}
+}
+
+// TestCorruptChunkSize makes sure chunk sizes past the end of the file give an error, while
+// a truncated data chunk is still read
+func TestCorruptChunkSize(t *testing.T) {
+	wfmt := wave.NewWaveFmt(FormatPCM, 1, 44100, 16, nil)
+	raw, _ := samplesToRawData(testFrames, wfmt)
+	file := func(last []byte, size uint64) []byte {
+		b := make([]byte, fileHeaderSize)
+		copy(b, RiffGUID)
+		copy(b[24:], WaveGUID)
+		b = append(b, chunk(FmtGUID, fmtToBytes(wfmt))...)
+		pos := len(b)
+		b = append(b, chunk(last, raw)...)
+		binary.LittleEndian.PutUint64(b[pos+guidSize:], size)
+		binary.LittleEndian.PutUint64(b[16:24], uint64(pos+chunkHeaderSize+len(raw)))
+		return b
+	}
+	junk := make([]byte, 16)
+	copy(junk, []byte("junk"))
+
+	for _, size := range []uint64{0x7FFFFFFFFFFFFFF0, math.MaxUint64, 1 << 40} {
+		for _, id := range [][]byte{junk, DataGUID} {
+			if _, err := ReadW64FromReader(bytes.NewReader(file(id, size))); err == nil {
+				t.Fatalf("Expected an error for chunk size %x", size)
+			}
+		}
+	}
+
+	// the header promises the whole data chunk, but the file ends 2 frames early (and the
+	// 2 bytes of padding)
+	b := file(DataGUID, uint64(chunkHeaderSize+len(raw)))
+	w, err := ReadW64FromReader(bytes.NewReader(b[:len(b)-6]))
+	if err != nil {
+		t.Fatalf("Should be able to read a truncated file: %v", err)
+	}
+	if len(w.Frames) != len(testFrames)-2 {
+		t.Fatalf("Expected %v frames, got %v", len(testFrames)-2, len(w.Frames))
+	}
+}
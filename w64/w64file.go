@@ -0,0 +1,74 @@
+package w64
+
+import "github.com/DylanMeeus/GoAudio/audio"
+
+// representation of the Sony Wave64 (.w64) file, used by reader.go and writer.go
+//
+// Wave64 is RIFF/WAVE with every four character code replaced by a 16 byte GUID and
+// every size widened to 64 bits. A chunk's size includes its own 24 byte header and
+// each chunk starts on an 8 byte boundary.
+
+/*
+
+╔════════╤════════════════╤══════╤═══════════════════════════════════════════════════╗
+║ Offset │ Field          │ Size │ -- all fields are little-endian                   ║
+╠════════╪════════════════╪══════╪═══════════════════════════════════════════════════╣
+║ 0      │ RiffGUID       │ 16   │                                                   ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 16     │ RiffSize       │ 8    │ size of the entire file                           ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ 24     │ WaveGUID       │ 16   │                                                   ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ --     │ --             │ --   │ -- chunks, each padded to 8 bytes                 ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ *      │ ChunkGUID      │ 16   │ fmt, data, or anything we skip                    ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ *      │ ChunkSize      │ 8    │ includes the 24 byte chunk header                 ║
+╟────────┼────────────────┼──────┼───────────────────────────────────────────────────╢
+║ *      │ ChunkData      │ *    │ fmt is laid out as in a .wav file                 ║
+╚════════╧════════════════╧══════╧═══════════════════════════════════════════════════╝
+
+*/
+
+// GUIDs that appear in the .w64 file format
+var (
+	RiffGUID = []byte{0x72, 0x69, 0x66, 0x66, 0x2E, 0x91, 0xCF, 0x11, 0xA5, 0xD6, 0x28, 0xDB, 0x04, 0xC1, 0x00, 0x00}
+	WaveGUID = []byte{0x77, 0x61, 0x76, 0x65, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+	FmtGUID  = []byte{0x66, 0x6D, 0x74, 0x20, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+	DataGUID = []byte{0x64, 0x61, 0x74, 0x61, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+	// Format is stored in the WaveHeader to identify the origin of the data
+	Format = "W64"
+)
+
+// Audio formats as they are stored in wave.WaveFmt.AudioFormat
+const (
+	FormatPCM        = 1
+	FormatFloat      = 3
+	FormatExtensible = 0xFFFE
+)
+
+const (
+	guidSize        = 16
+	chunkHeaderSize = guidSize + 8
+	// riff header + wave guid
+	fileHeaderSize = chunkHeaderSize + guidSize
+)
+
+var (
+	// max value depending on the bit size
+	maxValues = map[int]int{
+		8:  127,
+		16: 32767,
+		24: 8388607,
+		32: 2147483647,
+	}
+)
+
+// pad8 rounds n up to the next multiple of 8
+func pad8(n int) int {
+	return (n + 7) &^ 7
+}
+
+func init() {
+	audio.RegisterFormat("w64", string(RiffGUID), ReadW64FromReader)
+}
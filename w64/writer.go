@@ -0,0 +1,114 @@
+package w64
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+// WriteW64File writes the samples to disk as a .w64 file
+// the WaveFmt metadata needs to be correct
+func WriteW64File(samples []wave.Frame, wfmt wave.WaveFmt, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return WriteW64ToWriter(samples, wfmt, f)
+}
+
+// WriteW64ToWriter writes the samples as .w64 data to the writer
+func WriteW64ToWriter(samples []wave.Frame, wfmt wave.WaveFmt, writer io.Writer) error {
+	raw, err := samplesToRawData(samples, wfmt)
+	if err != nil {
+		return err
+	}
+
+	fmtBody := fmtToBytes(wfmt)
+	fmtChunk := chunk(FmtGUID, fmtBody)
+	dataChunk := chunk(DataGUID, raw)
+
+	hdr := make([]byte, fileHeaderSize)
+	copy(hdr[0:16], RiffGUID)
+	binary.LittleEndian.PutUint64(hdr[16:24], uint64(len(hdr)+len(fmtChunk)+len(dataChunk)))
+	copy(hdr[24:40], WaveGUID)
+
+	for _, b := range [][]byte{hdr, fmtChunk, dataChunk} {
+		if _, err := writer.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunk prefixes the body with its GUID and size, and pads it to 8 bytes
+func chunk(id, body []byte) []byte {
+	size := chunkHeaderSize + len(body)
+	b := make([]byte, pad8(size))
+	copy(b[0:16], id)
+	binary.LittleEndian.PutUint64(b[16:24], uint64(size))
+	copy(b[chunkHeaderSize:], body)
+	return b
+}
+
+func fmtToBytes(wfmt wave.WaveFmt) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b[0:2], uint16(wfmt.AudioFormat))
+	binary.LittleEndian.PutUint16(b[2:4], uint16(wfmt.NumChannels))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(wfmt.SampleRate))
+	binary.LittleEndian.PutUint32(b[8:12], uint32(wfmt.ByteRate))
+	binary.LittleEndian.PutUint16(b[12:14], uint16(wfmt.BlockAlign))
+	binary.LittleEndian.PutUint16(b[14:16], uint16(wfmt.BitsPerSample))
+	if wfmt.ExtraParamSize > 0 {
+		extra := make([]byte, 2)
+		binary.LittleEndian.PutUint16(extra, uint16(len(wfmt.ExtraParams)))
+		b = append(b, extra...)
+		b = append(b, wfmt.ExtraParams...)
+	}
+	return b
+}
+
+// samplesToRawData turns the frames into little-endian sample data
+func samplesToRawData(samples []wave.Frame, wfmt wave.WaveFmt) ([]byte, error) {
+	format := sampleFormat(wfmt)
+	bits := wfmt.BitsPerSample
+	size := bits / 8
+	raw := make([]byte, len(samples)*size)
+	for i, s := range samples {
+		b := raw[i*size : i*size+size]
+		switch {
+		case format == FormatPCM && bits == 8:
+			b[0] = byte(rescaleFrame(s, 8) + 128)
+		case format == FormatPCM && bits == 16:
+			binary.LittleEndian.PutUint16(b, uint16(rescaleFrame(s, 16)))
+		case format == FormatPCM && bits == 24:
+			v := rescaleFrame(s, 24)
+			b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+		case format == FormatPCM && bits == 32:
+			binary.LittleEndian.PutUint32(b, uint32(rescaleFrame(s, 32)))
+		case format == FormatFloat && bits == 32:
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(s)))
+		case format == FormatFloat && bits == 64:
+			binary.LittleEndian.PutUint64(b, math.Float64bits(float64(s)))
+		default:
+			return nil, fmt.Errorf("Format %v with %v bits per sample not supported", format, bits)
+		}
+	}
+	return raw, nil
+}
+
+// rescale frames back to integer values, clipping anything outside of [-1, 1]
+func rescaleFrame(s wave.Frame, bits int) int {
+	f := float64(s)
+	if f > 1 {
+		f = 1
+	} else if f < -1 {
+		f = -1
+	}
+	return int(math.Round(f * float64(maxValues[bits])))
+}
@@ -0,0 +1,167 @@
+package wave
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ResampleFunc converts interleaved frames with the given number of channels from one
+// sample rate to another.
+type ResampleFunc func(frames []Frame, channels, from, to int) ([]Frame, error)
+
+// RemixFunc converts interleaved frames from one channel count to another.
+type RemixFunc func(frames []Frame, from, to int) ([]Frame, error)
+
+// ConvertOptions tweak how Convert changes the format of a Wave
+type ConvertOptions struct {
+	// Dither adds triangular (TPDF) dither when requantizing to a lower bit depth
+	Dither bool
+	// Resample is used when the sample rate changes, linear interpolation if nil.
+	// For better quality pass a band-limited resampler, such as resample.Func.
+	Resample ResampleFunc
+	// Remix is used when the channel count changes, DefaultRemix if nil.
+	Remix RemixFunc
+}
+
+// Convert changes the sample rate, channel count and bit depth of the wave to those of the
+// target format. The returned Wave has a header, fmt and data that are consistent with the
+// converted frames, so it can be written as-is.
+// Only PCM targets are supported.
+func Convert(w Wave, target WaveFmt, opts ConvertOptions) (Wave, error) {
+	if target.AudioFormat != 1 {
+		return Wave{}, fmt.Errorf("Can only convert to PCM, not format %v", target.AudioFormat)
+	}
+	if _, ok := intsToBytesFm[target.BitsPerSample]; !ok {
+		return Wave{}, fmt.Errorf("Can't convert to %v bits per sample", target.BitsPerSample)
+	}
+	if w.NumChannels <= 0 || target.NumChannels <= 0 {
+		return Wave{}, errors.New("Need at least one channel to convert")
+	}
+	if w.SampleRate <= 0 || target.SampleRate <= 0 {
+		return Wave{}, errors.New("Need a positive sample rate to convert")
+	}
+
+	remix := opts.Remix
+	if remix == nil {
+		remix = DefaultRemix
+	}
+	resample := opts.Resample
+	if resample == nil {
+		resample = LinearResample
+	}
+
+	frames := w.Frames
+	var err error
+	// remix before resampling when we lose channels, so there is less to resample
+	if target.NumChannels < w.NumChannels {
+		if frames, err = remix(frames, w.NumChannels, target.NumChannels); err != nil {
+			return Wave{}, err
+		}
+	}
+	if target.SampleRate != w.SampleRate {
+		channels := w.NumChannels
+		if target.NumChannels < channels {
+			channels = target.NumChannels
+		}
+		if frames, err = resample(frames, channels, w.SampleRate, target.SampleRate); err != nil {
+			return Wave{}, err
+		}
+	}
+	if target.NumChannels > w.NumChannels {
+		if frames, err = remix(frames, w.NumChannels, target.NumChannels); err != nil {
+			return Wave{}, err
+		}
+	}
+
+	frames = Requantize(frames, target.BitsPerSample, opts.Dither)
+
+	wfmt := NewWaveFmt(target.AudioFormat, target.NumChannels, target.SampleRate, target.BitsPerSample, nil)
+	wd, _ := framesToData(frames, wfmt)
+	return Wave{
+		WaveHeader: WaveHeader{
+			ChunkID:   ChunkID,
+			ChunkSize: 36 + wd.Subchunk2Size,
+			Format:    string(WaveID),
+		},
+		WaveFmt:  wfmt,
+		WaveData: wd,
+	}, nil
+}
+
+// Requantize rounds the frames to the values representable with the given bit depth,
+// clipping them to the [-1, 1] range. With dither, triangular noise of one step is added
+// before rounding to decorrelate the quantization error from the signal.
+func Requantize(frames []Frame, bits int, dither bool) []Frame {
+	steps := float64(maxValues[bits])
+	out := make([]Frame, len(frames))
+	for i, f := range frames {
+		v := float64(f) * steps
+		if dither {
+			v += rand.Float64() - rand.Float64()
+		}
+		v = math.Round(v)
+		if v > steps {
+			v = steps
+		} else if v < -steps {
+			v = -steps
+		}
+		out[i] = Frame(v / steps)
+	}
+	return out
+}
+
+// DefaultRemix converts between channel counts without knowing the speaker layout.
+// Mono is copied to every channel and everything is averaged when going to mono.
+// Otherwise channels are kept in order, dropping or silencing the ones that don't match up.
+func DefaultRemix(frames []Frame, from, to int) ([]Frame, error) {
+	if from <= 0 || to <= 0 {
+		return nil, errors.New("Need at least one channel to remix")
+	}
+	n := len(frames) / from
+	out := make([]Frame, n*to)
+	for i := 0; i < n; i++ {
+		in := frames[i*from : i*from+from]
+		for c := 0; c < to; c++ {
+			switch {
+			case to == 1:
+				sum := Frame(0)
+				for _, f := range in {
+					sum += f
+				}
+				out[i*to] = sum / Frame(from)
+			case from == 1:
+				out[i*to+c] = in[0]
+			case c < from:
+				out[i*to+c] = in[c]
+			}
+		}
+	}
+	return out, nil
+}
+
+// LinearResample converts the sample rate by linear interpolation between samples.
+// This is cheap but aliases, use a band-limited resampler when quality matters.
+func LinearResample(frames []Frame, channels, from, to int) ([]Frame, error) {
+	if channels <= 0 || from <= 0 || to <= 0 {
+		return nil, errors.New("Invalid arguments for resampling")
+	}
+	n := len(frames) / channels
+	outN := (n*to + from - 1) / from
+	out := make([]Frame, outN*channels)
+	for i := 0; i < outN; i++ {
+		pos := float64(i) * float64(from) / float64(to)
+		base := int(pos)
+		frac := Frame(pos - float64(base))
+		for c := 0; c < channels; c++ {
+			a := frames[base*channels+c]
+			b := a
+			if base+1 < n {
+				b = frames[(base+1)*channels+c]
+			}
+			out[i*channels+c] = a + frac*(b-a)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package wave
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+var (
+	convertTests = []struct {
+		from, to WaveFmt
+	}{
+		{NewWaveFmt(1, 2, 44100, 16, nil), NewWaveFmt(1, 1, 22050, 8, nil)},
+		{NewWaveFmt(1, 1, 22050, 16, nil), NewWaveFmt(1, 2, 48000, 24, nil)},
+		{NewWaveFmt(1, 2, 48000, 32, nil), NewWaveFmt(1, 2, 48000, 16, nil)},
+		{NewWaveFmt(1, 6, 8000, 16, nil), NewWaveFmt(1, 2, 8000, 32, nil)},
+	}
+
+	defaultRemixTests = []struct {
+		in       []Frame
+		from, to int
+		out      []Frame
+	}{
+		{makeSampleSlice(1, 0.5), 1, 2, makeSampleSlice(1, 1, 0.5, 0.5)},
+		{makeSampleSlice(1, 0.5, 0, -1), 2, 1, makeSampleSlice(0.75, -0.5)},
+		{makeSampleSlice(1, 0.5, 0.25), 3, 2, makeSampleSlice(1, 0.5)},
+		{makeSampleSlice(1, 0.5), 2, 3, makeSampleSlice(1, 0.5, 0)},
+	}
+)
+
+// TestConvert makes sure the converted wave is internally consistent and survives a round trip
+func TestConvert(t *testing.T) {
+	for _, test := range convertTests {
+		t.Run("", func(t *testing.T) {
+			n := test.from.SampleRate / 10
+			frames := make([]Frame, n*test.from.NumChannels)
+			for i := range frames {
+				frames[i] = Frame(0.5 * math.Sin(float64(i/test.from.NumChannels)*0.05))
+			}
+			w := Wave{WaveFmt: test.from, WaveData: WaveData{Frames: frames}}
+
+			out, err := Convert(w, test.to, ConvertOptions{Dither: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expectedN := (n*test.to.SampleRate + test.from.SampleRate - 1) / test.from.SampleRate
+			if len(out.Frames) != expectedN*test.to.NumChannels {
+				t.Fatalf("Expected %v frames, got %v", expectedN*test.to.NumChannels, len(out.Frames))
+			}
+			bytesPerSample := test.to.BitsPerSample / 8
+			if out.Subchunk2Size != len(out.Frames)*bytesPerSample || len(out.RawData) != out.Subchunk2Size {
+				t.Fatalf("Inconsistent data size %v for %v frames", out.Subchunk2Size, len(out.Frames))
+			}
+			if out.ByteRate != test.to.SampleRate*test.to.NumChannels*bytesPerSample {
+				t.Fatalf("Wrong byte rate %v", out.ByteRate)
+			}
+			if out.BlockAlign != test.to.NumChannels*bytesPerSample {
+				t.Fatalf("Wrong block align %v", out.BlockAlign)
+			}
+			if out.ChunkSize != 36+out.Subchunk2Size {
+				t.Fatalf("Wrong chunk size %v", out.ChunkSize)
+			}
+
+			buf := bytes.Buffer{}
+			if err := WriteWaveToWriter(out.Frames, out.WaveFmt, &buf); err != nil {
+				t.Fatalf("Should be able to write: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes()[44:], out.RawData) {
+				t.Fatal("Written data does not match the converted raw data")
+			}
+			back, err := ReadWaveFromReader(&buf)
+			if err != nil {
+				t.Fatalf("Should be able to read: %v", err)
+			}
+			if back.ChunkSize != out.ChunkSize || back.Subchunk2Size != out.Subchunk2Size {
+				t.Fatalf("Header mismatch after round trip")
+			}
+			if !framesEquals(back.Frames, out.Frames) {
+				t.Fatal("Frames changed after round trip")
+			}
+		})
+	}
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	w := Wave{WaveFmt: NewWaveFmt(1, 1, 8000, 16, nil)}
+	if _, err := Convert(w, NewWaveFmt(3, 1, 8000, 32, nil), ConvertOptions{}); err == nil {
+		t.Fatal("Expected error converting to float")
+	}
+	if _, err := Convert(w, NewWaveFmt(1, 1, 8000, 12, nil), ConvertOptions{}); err == nil {
+		t.Fatal("Expected error converting to 12 bits")
+	}
+}
+
+func TestRequantize(t *testing.T) {
+	out := Requantize(makeSampleSlice(0.5, 1.5, -2, 0.004), 8, false)
+	expected := makeSampleSlice(64./127, 1, -1, 1./127)
+	if !framesEquals(out, expected) {
+		t.Fatalf("expected %v, got %v", expected, out)
+	}
+}
+
+func TestDefaultRemix(t *testing.T) {
+	for _, test := range defaultRemixTests {
+		t.Run("", func(t *testing.T) {
+			out, err := DefaultRemix(test.in, test.from, test.to)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !framesEquals(out, test.out) {
+				t.Fatalf("expected %v, got %v", test.out, out)
+			}
+		})
+	}
+}
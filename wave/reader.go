@@ -19,6 +19,7 @@ type (
 var (
 	// figure out which 'to int' function to use..
 	byteSizeToIntFunc = map[int]bytesToIntF{
+		8:  bits8ToInt,
 		16: bits16ToInt,
 		24: bits24ToInt,
 		32: bits32ToInt,
@@ -34,6 +35,7 @@ var (
 	maxValues = map[int]int{
 		8:  math.MaxInt8,
 		16: math.MaxInt16,
+		24: 1<<23 - 1,
 		32: math.MaxInt32,
 		64: math.MaxInt64,
 	}
@@ -93,6 +95,14 @@ func bitsToFloat(b []byte) float64 {
 	return float
 }
 
+// 8-bit wave samples are stored unsigned, with silence at 128
+func bits8ToInt(b []byte) int {
+	if len(b) != 1 {
+		panic("Expected size 1!")
+	}
+	return int(b[0]) - 128
+}
+
 func bits16ToInt(b []byte) int {
 	if len(b) != 2 {
 		panic("Expected size 4!")
@@ -112,6 +122,7 @@ func bits24ToInt(b []byte) int {
 		panic("Expected size 3!")
 	}
 	// add some padding to turn a 24-bit integer into a 32-bit integer
+	// then shift it back down to sign-extend the value
 	b = append([]byte{0x00}, b...)
 	var payload int32
 	buf := bytes.NewReader(b)
@@ -120,7 +131,7 @@ func bits24ToInt(b []byte) int {
 		// TODO: make safe
 		panic(err)
 	}
-	return int(payload) // easier to work with ints
+	return int(payload >> 8) // easier to work with ints
 }
 
 // turn a 32-bit byte array into an int
@@ -45,3 +45,56 @@ func FloatsToFrames(fs []float64) []Frame {
 	}
 	return frames
 }
+
+// SplitChannels de-interleaves the frames into one slice per channel
+func SplitChannels(frames []Frame, channels int) [][]Frame {
+	if channels <= 0 {
+		return nil
+	}
+	n := len(frames) / channels
+	out := make([][]Frame, channels)
+	for c := range out {
+		out[c] = make([]Frame, n)
+		for i := 0; i < n; i++ {
+			out[c][i] = frames[i*channels+c]
+		}
+	}
+	return out
+}
+
+// JoinChannels interleaves one slice per channel into a single slice of frames
+// The output is as long as the shortest channel allows.
+func JoinChannels(channels [][]Frame) []Frame {
+	if len(channels) == 0 {
+		return []Frame{}
+	}
+	n := len(channels[0])
+	for _, c := range channels {
+		if len(c) < n {
+			n = len(c)
+		}
+	}
+	out := make([]Frame, n*len(channels))
+	for i := 0; i < n; i++ {
+		for c := range channels {
+			out[i*len(channels)+c] = channels[c][i]
+		}
+	}
+	return out
+}
+
+// MixToMono averages the channels of interleaved frames into a single channel
+// Mono input is returned as is.
+func MixToMono(frames []Frame, channels int) []Frame {
+	if channels <= 1 {
+		return frames
+	}
+	out := make([]Frame, len(frames)/channels)
+	for i := range out {
+		for c := 0; c < channels; c++ {
+			out[i] += frames[i*channels+c]
+		}
+		out[i] /= Frame(channels)
+	}
+	return out
+}
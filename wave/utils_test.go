@@ -100,6 +100,27 @@ func TestFloatsToFrames(t *testing.T) {
 	}
 }
 
+func TestSplitJoinChannels(t *testing.T) {
+	frames := makeSampleSlice(1, 2, 3, 4, 5, 6)
+	split := SplitChannels(frames, 3)
+	if !compareSampleSlices(split, [][]Frame{makeSampleSlice(1, 4), makeSampleSlice(2, 5), makeSampleSlice(3, 6)}) {
+		t.Fatalf("Unexpected split: %v", split)
+	}
+	if joined := JoinChannels(split); !framesEquals(joined, frames) {
+		t.Fatalf("expected %v, got %v", frames, joined)
+	}
+}
+
+func TestMixToMono(t *testing.T) {
+	frames := makeSampleSlice(1, 3, -2, 0, 0.5, 0.5)
+	if mono := MixToMono(frames, 2); !framesEquals(mono, makeSampleSlice(2, -1, 0.5)) {
+		t.Fatalf("Unexpected mix: %v", mono)
+	}
+	if mono := MixToMono(frames, 1); !framesEquals(mono, frames) {
+		t.Fatalf("Expected mono frames to be returned as is, got %v", mono)
+	}
+}
+
 func framesEquals(f1, f2 []Frame) bool {
 	if len(f1) != len(f2) {
 		return false
@@ -102,6 +102,20 @@ func NewWaveFmt(format, channels, samplerate, bitspersample int, extraparams []b
 	}
 }
 
+// PCMFormat returns the format to write frames of wfmt with as integer PCM, the only
+// samples WriteWaveFile can write. That is wfmt itself when it is 8, 16, 24 or 32-bit PCM
+// already, and 16-bit PCM with the same channels and sample rate otherwise, in which case
+// changed is true.
+func PCMFormat(wfmt WaveFmt) (pcm WaveFmt, changed bool) {
+	if wfmt.AudioFormat == 1 {
+		switch wfmt.BitsPerSample {
+		case 8, 16, 24, 32:
+			return wfmt, false
+		}
+	}
+	return NewWaveFmt(1, wfmt.NumChannels, wfmt.SampleRate, 16, nil), true
+}
+
 // SetChannels changes the FMT to adapt to a new amount of channels
 func (wfmt *WaveFmt) SetChannels(n uint) {
 	wfmt.NumChannels = int(n)
@@ -21,7 +21,9 @@ type intsToBytesFunc func(i int) []byte
 var (
 	// intsToBytesFm to map X-bit int to byte functions
 	intsToBytesFm = map[int]intsToBytesFunc{
+		8:  int8ToBytes,
 		16: int16ToBytes,
+		24: int24ToBytes,
 		32: int32ToBytes,
 	}
 )
@@ -64,6 +66,11 @@ func WriteWaveToWriter(samples []Frame, wfmt WaveFmt, writer io.Writer) error {
 	return nil
 }
 
+// 8-bit wave samples are stored unsigned, with silence at 128
+func int8ToBytes(i int) []byte {
+	return []byte{byte(i + 128)}
+}
+
 func int16ToBytes(i int) []byte {
 	b := make([]byte, 2)
 	in := uint16(i)
@@ -71,6 +78,13 @@ func int16ToBytes(i int) []byte {
 	return b
 }
 
+func int24ToBytes(i int) []byte {
+	b := make([]byte, 4)
+	in := uint32(i)
+	binary.LittleEndian.PutUint32(b, in)
+	return b[:3]
+}
+
 func int32ToBytes(i int) []byte {
 	b := make([]byte, 4)
 	in := uint32(i)
@@ -82,9 +96,8 @@ func framesToData(frames []Frame, wfmt WaveFmt) (WaveData, []byte) {
 	b := []byte{}
 	raw := samplesToRawData(frames, wfmt)
 
-	// We receive frames but have to store the size of the samples
-	// The size of the samples is frames / channels..
-	subchunksize := (len(frames) * wfmt.NumChannels * wfmt.BitsPerSample) / 8
+	// The frames are interleaved already, so each frame is a single sample
+	subchunksize := len(raw)
 	subBytes := int32ToBytes(subchunksize)
 
 	// construct the data part..
@@ -130,7 +143,7 @@ func samplesToRawData(samples []Frame, props WaveFmt) []byte {
 // rescale frames back to the original values..
 func rescaleFrame(s Frame, bits int) int {
 	rescaled := float64(s) * float64(maxValues[bits])
-	return int(rescaled)
+	return int(math.Round(rescaled))
 }
 
 func fmtToBytes(wfmt WaveFmt) []byte {
@@ -56,3 +56,28 @@ func TestWriteWave(t *testing.T) {
 		t.Fatalf("Should be able to write file: %v", err)
 	}
 }
+
+func TestPCMFormat(t *testing.T) {
+	for _, bits := range []int{8, 16, 24, 32} {
+		in := NewWaveFmt(1, 2, 44100, bits, nil)
+		out, changed := PCMFormat(in)
+		if changed || out.BitsPerSample != bits {
+			t.Fatalf("Expected %v-bit PCM to be kept, got %v bits (changed: %v)", bits, out.BitsPerSample, changed)
+		}
+	}
+	for _, in := range []WaveFmt{
+		NewWaveFmt(3, 2, 44100, 32, nil), // float
+		NewWaveFmt(3, 2, 44100, 64, nil),
+		NewWaveFmt(7, 2, 44100, 8, nil), // mu-law
+		NewWaveFmt(1, 2, 44100, 12, nil),
+	} {
+		out, changed := PCMFormat(in)
+		if !changed || out.AudioFormat != 1 || out.BitsPerSample != 16 {
+			t.Fatalf("Expected format %v with %v bits to become 16-bit PCM, got format %v with %v bits",
+				in.AudioFormat, in.BitsPerSample, out.AudioFormat, out.BitsPerSample)
+		}
+		if out.NumChannels != 2 || out.SampleRate != 44100 || out.BlockAlign != 4 {
+			t.Fatalf("Expected the channels and sample rate to be kept, got %+v", out)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package window provides window functions to taper frames of audio before spectral
+// analysis, together with the metadata needed to scale the resulting spectra.
+package window
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const tau = 2 * math.Pi
+
+// Variant selects between the two common definitions of a window
+type Variant int
+
+// Variants of each window.
+// SYMMETRIC windows are meant for filter design, PERIODIC windows for spectral analysis
+// (they are the first N samples of the N+1 point symmetric window).
+const (
+	SYMMETRIC Variant = iota
+	PERIODIC
+)
+
+// coefficients of the cosine-sum windows
+var (
+	hannCoefs           = []float64{0.5, 0.5}
+	hammingCoefs        = []float64{0.54, 0.46}
+	blackmanCoefs       = []float64{0.42, 0.5, 0.08}
+	blackmanHarrisCoefs = []float64{0.35875, 0.48829, 0.14128, 0.01168}
+	flatTopCoefs        = []float64{0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368}
+)
+
+// generate calls f with the position of each sample in the window.
+// f receives the sample index and the denominator M, so x = i/M runs from 0 to 1 for
+// a symmetric window.
+func generate(n int, v Variant, f func(i, m float64) float64) []float64 {
+	if n <= 0 {
+		return []float64{}
+	}
+	if n == 1 {
+		return []float64{1}
+	}
+	m := float64(n - 1)
+	if v == PERIODIC {
+		m = float64(n)
+	}
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = f(float64(i), m)
+	}
+	return w
+}
+
+// cosineSum generates w[i] = a0 - a1*cos(2πi/M) + a2*cos(4πi/M) - ..
+func cosineSum(n int, v Variant, a []float64) []float64 {
+	return generate(n, v, func(i, m float64) float64 {
+		sum, sign := 0.0, 1.0
+		for k, ak := range a {
+			sum += sign * ak * math.Cos(tau*float64(k)*i/m)
+			sign = -sign
+		}
+		return sum
+	})
+}
+
+// Rectangular returns a window of ones, which is the same as not windowing at all
+func Rectangular(n int) []float64 {
+	return generate(n, SYMMETRIC, func(i, m float64) float64 { return 1 })
+}
+
+// Hann returns a Hann (raised cosine) window
+func Hann(n int, v Variant) []float64 {
+	return cosineSum(n, v, hannCoefs)
+}
+
+// Hamming returns a Hamming window
+func Hamming(n int, v Variant) []float64 {
+	return cosineSum(n, v, hammingCoefs)
+}
+
+// Blackman returns a (conventional, three term) Blackman window
+func Blackman(n int, v Variant) []float64 {
+	return cosineSum(n, v, blackmanCoefs)
+}
+
+// BlackmanHarris returns a four term Blackman-Harris window (-92dB sidelobes)
+func BlackmanHarris(n int, v Variant) []float64 {
+	return cosineSum(n, v, blackmanHarrisCoefs)
+}
+
+// FlatTop returns a flat-top window, which has almost no scalloping loss and is used
+// to measure the amplitude of sinusoids accurately.
+func FlatTop(n int, v Variant) []float64 {
+	return cosineSum(n, v, flatTopCoefs)
+}
+
+// Kaiser returns a Kaiser window, beta trades main lobe width for sidelobe level.
+// Beta = 0 is rectangular, beta ~ 8.6 resembles a Blackman window.
+func Kaiser(n int, beta float64, v Variant) []float64 {
+	kaiser := KaiserFunc(beta)
+	return generate(n, v, func(i, m float64) float64 {
+		return kaiser(2*i/m - 1)
+	})
+}
+
+// KaiserFunc returns the Kaiser window as a function of x, which runs from -1 to 1 over
+// the window. It is meant for filters that evaluate the window between samples.
+func KaiserFunc(beta float64) func(x float64) float64 {
+	i0beta := Bessel0(beta)
+	return func(x float64) float64 {
+		if x < -1 || x > 1 {
+			return 0
+		}
+		return Bessel0(beta*math.Sqrt(1-x*x)) / i0beta
+	}
+}
+
+// KaiserBeta returns the beta of a Kaiser window for a windowed-sinc filter with the given
+// stop band attenuation in dB, following Kaiser's empirical formula
+func KaiserBeta(attenuation float64) float64 {
+	switch {
+	case attenuation > 50:
+		return 0.1102 * (attenuation - 8.7)
+	case attenuation >= 21:
+		return 0.5842*math.Pow(attenuation-21, 0.4) + 0.07886*(attenuation-21)
+	}
+	return 0
+}
+
+// Tukey returns a tapered cosine window, alpha is the fraction of the window inside the
+// cosine tapers. Alpha = 0 is rectangular, alpha = 1 is a Hann window.
+func Tukey(n int, alpha float64, v Variant) []float64 {
+	if alpha <= 0 {
+		return generate(n, v, func(i, m float64) float64 { return 1 })
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+	return generate(n, v, func(i, m float64) float64 {
+		x := i / m
+		switch {
+		case x < alpha/2:
+			return 0.5 * (1 + math.Cos(tau/alpha*(x-alpha/2)))
+		case x > 1-alpha/2:
+			return 0.5 * (1 + math.Cos(tau/alpha*(x-1+alpha/2)))
+		}
+		return 1
+	})
+}
+
+// Gaussian returns a Gaussian window with a standard deviation of sigma samples
+func Gaussian(n int, sigma float64, v Variant) []float64 {
+	return generate(n, v, func(i, m float64) float64 {
+		x := (i - m/2) / sigma
+		return math.Exp(-0.5 * x * x)
+	})
+}
+
+// CoherentGain returns the gain of the window for a sinusoid in the centre of a bin, as a
+// fraction of the rectangular window's gain.
+// Divide spectral magnitudes by this to get the amplitude of sinusoids.
+func CoherentGain(w []float64) float64 {
+	if len(w) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range w {
+		sum += x
+	}
+	return sum / float64(len(w))
+}
+
+// ENBW returns the equivalent noise bandwidth of the window in bins.
+// Divide power spectra by this (and the bin width) to get the power spectral density of noise.
+func ENBW(w []float64) float64 {
+	sum, squares := 0.0, 0.0
+	for _, x := range w {
+		sum += x
+		squares += x * x
+	}
+	if sum == 0 {
+		return 0
+	}
+	return float64(len(w)) * squares / (sum * sum)
+}
+
+// Apply multiplies the frames with the window, the window has to be as long as the frames
+func Apply(frames []wave.Frame, w []float64) ([]wave.Frame, error) {
+	if len(frames) != len(w) {
+		return nil, fmt.Errorf("Window of %v samples can't be applied to %v frames", len(w), len(frames))
+	}
+	out := make([]wave.Frame, len(frames))
+	for i, f := range frames {
+		out[i] = f * wave.Frame(w[i])
+	}
+	return out, nil
+}
+
+// Bessel0 is the zeroth order modified Bessel function of the first kind
+func Bessel0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	halfx := x / 2
+	for k := 1; k < 500; k++ {
+		term *= halfx / float64(k)
+		sum += term * term
+		if term*term < sum*1e-21 {
+			break
+		}
+	}
+	return sum
+}
@@ -0,0 +1,126 @@
+package window
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	windowValueTests = []struct {
+		name string
+		w    []float64
+		out  []float64
+	}{
+		{"hann symmetric", Hann(5, SYMMETRIC), []float64{0, 0.5, 1, 0.5, 0}},
+		{"hann periodic", Hann(4, PERIODIC), []float64{0, 0.5, 1, 0.5}},
+		{"hamming", Hamming(3, SYMMETRIC), []float64{0.08, 1, 0.08}},
+		{"blackman", Blackman(3, SYMMETRIC), []float64{0, 1, 0}},
+		{"kaiser beta 0", Kaiser(4, 0, SYMMETRIC), []float64{1, 1, 1, 1}},
+		{"tukey 0", Tukey(4, 0, SYMMETRIC), []float64{1, 1, 1, 1}},
+		{"tukey 1", Tukey(5, 1, SYMMETRIC), []float64{0, 0.5, 1, 0.5, 0}},
+		{"tukey 0.5", Tukey(9, 0.5, SYMMETRIC), []float64{0, 0.5, 1, 1, 1, 1, 1, 0.5, 0}},
+		{"gaussian", Gaussian(3, 1, SYMMETRIC), []float64{math.Exp(-0.5), 1, math.Exp(-0.5)}},
+		{"single sample", Hann(1, SYMMETRIC), []float64{1}},
+		{"empty", Hann(0, SYMMETRIC), []float64{}},
+	}
+
+	// reference values for large windows
+	metadataTests = []struct {
+		name string
+		w    []float64
+		cg   float64
+		enbw float64
+	}{
+		{"rectangular", Rectangular(4096), 1, 1},
+		{"hann", Hann(4096, PERIODIC), 0.5, 1.5},
+		{"hamming", Hamming(4096, PERIODIC), 0.54, 1.3628},
+		{"blackman", Blackman(4096, PERIODIC), 0.42, 1.7268},
+		{"blackman-harris", BlackmanHarris(4096, PERIODIC), 0.35875, 2.0044},
+		{"flat-top", FlatTop(4096, PERIODIC), 0.21557895, 3.7702},
+	}
+)
+
+func floatsEqual(a, b []float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWindowValues(t *testing.T) {
+	for _, test := range windowValueTests {
+		t.Run(test.name, func(t *testing.T) {
+			if !floatsEqual(test.w, test.out, 1e-12) {
+				t.Fatalf("expected %v, got %v", test.out, test.w)
+			}
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	for _, test := range metadataTests {
+		t.Run(test.name, func(t *testing.T) {
+			if cg := CoherentGain(test.w); math.Abs(cg-test.cg) > 1e-4 {
+				t.Fatalf("expected coherent gain %v, got %v", test.cg, cg)
+			}
+			if enbw := ENBW(test.w); math.Abs(enbw-test.enbw) > 1e-3 {
+				t.Fatalf("expected ENBW %v, got %v", test.enbw, enbw)
+			}
+		})
+	}
+}
+
+// TestPeriodic makes sure the periodic window is the symmetric window one sample longer,
+// without the last sample
+func TestPeriodic(t *testing.T) {
+	windows := map[string]func(int, Variant) []float64{
+		"hann":     Hann,
+		"flattop":  FlatTop,
+		"kaiser":   func(n int, v Variant) []float64 { return Kaiser(n, 6, v) },
+		"tukey":    func(n int, v Variant) []float64 { return Tukey(n, 0.3, v) },
+		"gaussian": func(n int, v Variant) []float64 { return Gaussian(n, 5, v) },
+	}
+	for name, f := range windows {
+		t.Run(name, func(t *testing.T) {
+			periodic := f(32, PERIODIC)
+			symmetric := f(33, SYMMETRIC)
+			if !floatsEqual(periodic, symmetric[:32], 1e-12) {
+				t.Fatalf("periodic window does not match symmetric window")
+			}
+			for i := range symmetric {
+				if math.Abs(symmetric[i]-symmetric[len(symmetric)-1-i]) > 1e-12 {
+					t.Fatalf("symmetric window is not symmetric at %v", i)
+				}
+			}
+		})
+	}
+}
+
+func TestKaiser(t *testing.T) {
+	kaiser := KaiserFunc(8)
+	w := Kaiser(9, 8, SYMMETRIC)
+	for i := range w {
+		if x := float64(i)/4 - 1; math.Abs(kaiser(x)-w[i]) > 1e-12 {
+			t.Fatalf("expected %v at %v, got %v", w[i], x, kaiser(x))
+		}
+	}
+	if kaiser(0) != 1 || kaiser(1.5) != 0 {
+		t.Fatalf("expected 1 in the middle and 0 outside, got %v and %v", kaiser(0), kaiser(1.5))
+	}
+
+	// Kaiser's formula in its three ranges
+	for _, test := range []struct{ attenuation, beta float64 }{
+		{20, 0},
+		{40, 0.5842*math.Pow(19, 0.4) + 0.07886*19},
+		{60, 0.1102 * 51.3},
+	} {
+		if b := KaiserBeta(test.attenuation); math.Abs(b-test.beta) > 1e-12 {
+			t.Fatalf("expected beta %v for %v dB, got %v", test.beta, test.attenuation, b)
+		}
+	}
+}
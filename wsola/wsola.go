@@ -0,0 +1,349 @@
+// Package wsola changes the speed of audio without changing its pitch with WSOLA
+// (waveform similarity overlap-add, Verhelst & Roelands).
+//
+// Unlike a phase vocoder WSOLA works in the time domain: short windowed segments of the
+// input are overlap-added at a fixed hop, and every segment is taken from close to where
+// it should be, at the offset where it best continues the previous one. This keeps the
+// waveform of speech intact, so it doesn't sound smeared.
+package wsola
+
+import (
+	"errors"
+	"math"
+
+	audiomath "github.com/DylanMeeus/GoAudio/math"
+	"github.com/DylanMeeus/GoAudio/wave"
+	"github.com/DylanMeeus/GoAudio/window"
+)
+
+// The range of speeds we support
+const (
+	MinSpeed = 0.5
+	MaxSpeed = 3
+)
+
+// Options configure the time-scaler.
+// Zero values are replaced by the defaults, which suit speech.
+type Options struct {
+	FrameSize float64 // length of the segments in seconds, default 0.03
+	Tolerance float64 // how far a segment may move to line up, in seconds, default 0.01
+}
+
+func (o Options) validate() (Options, error) {
+	if o.FrameSize == 0 {
+		o.FrameSize = 0.03
+	}
+	if o.Tolerance == 0 {
+		o.Tolerance = 0.01
+	}
+	if o.FrameSize < 0 || o.Tolerance < 0 {
+		return o, errors.New("FrameSize and Tolerance can't be negative")
+	}
+	return o, nil
+}
+
+// Scaler changes the speed of a stream of interleaved frames.
+// Input can be passed in blocks of any size, the output is the same as scaling the whole
+// signal at once.
+type Scaler struct {
+	channels  int
+	size, hop int // segment length and synthesis hop in samples
+	tolerance int
+	speed     float64
+	win       []float64
+
+	// the input per channel, starting at absolute index offset. The input is preceded by
+	// hop samples of silence, so the first segment fades in the start.
+	history  [][]float64
+	offset   int
+	consumed int          // number of input samples per channel
+	pending  []wave.Frame // incomplete multi-channel frame from the previous block
+
+	acc       [][]float64 // output per channel that is still being overlap-added
+	k         int         // index of the next segment
+	ideal     float64     // where the next segment would start without alignment
+	prev      int         // where the previous segment started
+	last      float64     // ideal start of the previous segment
+	lastSpeed float64     // speed of the previous segment
+	emitted   int         // output samples per channel
+	flushed   bool
+}
+
+// New creates a Scaler for interleaved audio with the given number of channels and sample
+// rate. A speed of 2 plays the audio twice as fast (in half the time).
+func New(channels, sr int, speed float64, opts Options) (*Scaler, error) {
+	if channels <= 0 || sr <= 0 {
+		return nil, errors.New("Channels and sample rate should be positive")
+	}
+	opts, err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+	// an even size, so the periodic Hann windows add up to 1 at half overlap
+	size := 2 * int(math.Round(opts.FrameSize*float64(sr)/2))
+	if size < 4 {
+		return nil, errors.New("FrameSize is too short for the sample rate")
+	}
+	s := &Scaler{
+		channels:  channels,
+		size:      size,
+		hop:       size / 2,
+		tolerance: int(math.Round(opts.Tolerance * float64(sr))),
+		win:       window.Hann(size, window.PERIODIC),
+	}
+	if err := s.SetSpeed(speed); err != nil {
+		return nil, err
+	}
+	s.Reset()
+	return s, nil
+}
+
+// Scale changes the speed of interleaved frames in one go.
+// The output contains round(len(frames) / speed) samples per channel.
+func Scale(frames []wave.Frame, channels, sr int, speed float64, opts Options) ([]wave.Frame, error) {
+	s, err := New(channels, sr, speed, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := s.Process(frames)
+	return append(out, s.Flush()...), nil
+}
+
+// SetSpeed changes the speed, from the next segment onwards
+func (s *Scaler) SetSpeed(speed float64) error {
+	if speed < MinSpeed || speed > MaxSpeed {
+		return errors.New("Speed should be between 0.5 and 3")
+	}
+	s.speed = speed
+	return nil
+}
+
+// Speed returns the current speed
+func (s *Scaler) Speed() float64 {
+	return s.speed
+}
+
+// Reset clears the state of the scaler so it can be used for a new stream
+func (s *Scaler) Reset() {
+	s.history = make([][]float64, s.channels)
+	s.acc = make([][]float64, s.channels)
+	for c := range s.history {
+		s.history[c] = make([]float64, s.hop)
+		s.acc[c] = make([]float64, s.size)
+	}
+	s.offset = 0
+	s.consumed = 0
+	s.pending = nil
+	s.k = 0
+	s.ideal = 0
+	s.prev = 0
+	s.last = 0
+	s.lastSpeed = s.speed
+	s.emitted = 0
+	s.flushed = false
+}
+
+// Process consumes a block of interleaved frames and returns all output that can be
+// produced so far.
+func (s *Scaler) Process(in []wave.Frame) []wave.Frame {
+	if s.flushed {
+		return nil
+	}
+	in = append(s.pending, in...)
+	whole := len(in) - len(in)%s.channels
+	for i := 0; i < whole; i++ {
+		c := i % s.channels
+		s.history[c] = append(s.history[c], float64(in[i]))
+	}
+	s.consumed += whole / s.channels
+	s.pending = append([]wave.Frame{}, in[whole:]...)
+
+	out := []wave.Frame{}
+	for {
+		start, ok := s.next(false)
+		if !ok {
+			break
+		}
+		out = append(out, s.add(start)...)
+	}
+	return out
+}
+
+// Flush returns the remaining output, treating the end of the input as silence.
+// The scaler has to be Reset before it can be used again.
+func (s *Scaler) Flush() []wave.Frame {
+	if s.flushed {
+		return nil
+	}
+	s.flushed = true
+
+	if s.consumed == 0 {
+		return nil
+	}
+	before := s.emitted
+	out := []wave.Frame{}
+	for s.k == 0 || s.ideal-float64(s.hop) < float64(s.consumed) {
+		start, _ := s.next(true)
+		out = append(out, s.add(start)...)
+	}
+	// output sample (k-1)*hop lines up with input sample ideal-hop of segment k, the
+	// output ends where the input ends
+	left := (float64(s.consumed) - s.last + float64(s.hop)) / s.lastSpeed
+	target := (s.k-2)*s.hop + int(math.Round(left))
+
+	// the end can be before the last complete output, or still be waiting in the
+	// overlap-add buffer
+	extra := (target - before) * s.channels
+	if extra < 0 {
+		extra = 0
+	}
+	if extra < len(out) {
+		out = out[:extra]
+	}
+	for i := 0; len(out) < extra; i++ {
+		for c := 0; c < s.channels; c++ {
+			if i < len(s.acc[c]) {
+				out = append(out, wave.Frame(s.acc[c][i]))
+			} else {
+				out = append(out, 0)
+			}
+		}
+	}
+	s.emitted = before + len(out)/s.channels
+	return out
+}
+
+// next finds the start of the next segment, or returns false if we need more input.
+// When final is set, missing input past the end of the stream is treated as silence.
+func (s *Scaler) next(final bool) (int, bool) {
+	if s.k == 0 {
+		return 0, final || s.available() >= s.size
+	}
+
+	a := int(math.Round(s.ideal))
+	lo, hi := a-s.tolerance, a+s.tolerance
+	if lo < 0 {
+		lo = 0
+	}
+	natural := s.prev + s.hop
+	need := hi + s.size
+	if natural+s.size > need {
+		need = natural + s.size
+	}
+	if !final && need > s.available() {
+		return 0, false
+	}
+	if lo == hi {
+		return lo, true
+	}
+
+	// the segment that would continue the previous one seamlessly, compared with every
+	// candidate around the ideal position
+	target := s.mono(natural, s.size)
+	region := s.mono(lo, hi-lo+s.size)
+	corr := audiomath.CrossCorrelate(target, region)
+
+	// normalise by the energy of the candidates, so loud segments aren't preferred
+	energy := 0.0
+	for _, f := range region[:s.size] {
+		energy += float64(f) * float64(f)
+	}
+	best, bestScore := a, math.Inf(-1)
+	for d := 0; d <= hi-lo; d++ {
+		if d > 0 {
+			old, new := float64(region[d-1]), float64(region[d+s.size-1])
+			energy += new*new - old*old
+		}
+		score := corr[d+len(target)-1] / math.Sqrt(math.Max(energy, 1e-12))
+		if score > bestScore {
+			best, bestScore = lo+d, score
+		}
+	}
+	return best, true
+}
+
+// add overlap-adds the segment starting at input sample start, and returns the output
+// that is complete
+func (s *Scaler) add(start int) []wave.Frame {
+	for c := range s.acc {
+		for i, w := range s.win {
+			s.acc[c][i] += s.sample(c, start+i) * w
+		}
+	}
+
+	// the first hop of output only holds the silence before the input
+	out := []wave.Frame{}
+	if s.k > 0 {
+		out = make([]wave.Frame, 0, s.hop*s.channels)
+		for i := 0; i < s.hop; i++ {
+			for c := range s.acc {
+				out = append(out, wave.Frame(s.acc[c][i]))
+			}
+		}
+	}
+	for c := range s.acc {
+		s.acc[c] = append(s.acc[c][s.hop:], make([]float64, s.hop)...)
+	}
+
+	s.last, s.lastSpeed = s.ideal, s.speed
+	if s.k == 0 {
+		// the output sample 0 lines up with input sample 0
+		s.ideal = float64(s.hop)
+	} else {
+		s.ideal += float64(s.hop) * s.speed
+	}
+	s.prev = start
+	s.k++
+	s.emitted += len(out) / s.channels
+	s.trim()
+	return out
+}
+
+// trim drops input samples that no segment can use anymore
+func (s *Scaler) trim() {
+	keep := s.prev + s.hop
+	if lo := int(math.Round(s.ideal)) - s.tolerance; lo < keep {
+		keep = lo
+	}
+	drop := keep - s.offset
+	if drop <= 0 {
+		return
+	}
+	for c := range s.history {
+		if drop > len(s.history[c]) {
+			drop = len(s.history[c])
+		}
+	}
+	// reslicing doesn't copy, append moves only what is left when the slice has to grow
+	for c := range s.history {
+		s.history[c] = s.history[c][drop:]
+	}
+	s.offset += drop
+}
+
+// available returns the (absolute) end of the input we have
+func (s *Scaler) available() int {
+	return s.offset + len(s.history[0])
+}
+
+// sample returns input sample i of channel c, silence outside of what we have
+func (s *Scaler) sample(c, i int) float64 {
+	i -= s.offset
+	if i < 0 || i >= len(s.history[c]) {
+		return 0
+	}
+	return s.history[c][i]
+}
+
+// mono returns n samples of the mix of all channels, starting at input sample start
+func (s *Scaler) mono(start, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		sum := 0.0
+		for c := range s.history {
+			sum += s.sample(c, start+i)
+		}
+		out[i] = wave.Frame(sum / float64(s.channels))
+	}
+	return out
+}
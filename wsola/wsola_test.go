@@ -0,0 +1,179 @@
+package wsola
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/DylanMeeus/GoAudio/wave"
+)
+
+const sr = 16000
+
+func sine(freq float64, n int) []wave.Frame {
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(0.5 * math.Sin(2*math.Pi*freq*float64(i)/sr))
+	}
+	return out
+}
+
+func noise(n int, seed int64) []wave.Frame {
+	rng := rand.New(rand.NewSource(seed))
+	out := make([]wave.Frame, n)
+	for i := range out {
+		out[i] = wave.Frame(rng.Float64()*2 - 1)
+	}
+	return out
+}
+
+// frequency counts the upward zero crossings of the middle half
+func frequency(frames []wave.Frame) float64 {
+	part := frames[len(frames)/4 : 3*len(frames)/4]
+	first, last, crossings := -1, 0, 0
+	for i := 1; i < len(part); i++ {
+		if part[i-1] < 0 && part[i] >= 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			crossings++
+		}
+	}
+	return float64(crossings-1) * sr / float64(last-first)
+}
+
+// rms of the middle half
+func rms(frames []wave.Frame) float64 {
+	part := frames[len(frames)/4 : 3*len(frames)/4]
+	sum := 0.0
+	for _, f := range part {
+		sum += float64(f) * float64(f)
+	}
+	return math.Sqrt(sum / float64(len(part)))
+}
+
+func TestScale(t *testing.T) {
+	in := sine(220, sr)
+	for _, speed := range []float64{0.5, 0.75, 1.25, 1.5, 2, 3} {
+		out, err := Scale(in, 1, sr, speed, Options{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := int(math.Round(sr / speed)); len(out) != expected {
+			t.Fatalf("Speed %v: expected %v samples, got %v", speed, expected, len(out))
+		}
+		if f := frequency(out); math.Abs(f-220) > 1 {
+			t.Fatalf("Speed %v: expected 220 Hz, got %v", speed, f)
+		}
+		if r := rms(out); math.Abs(r-rms(in)) > 0.03*rms(in) {
+			t.Fatalf("Speed %v: expected an RMS of %v, got %v", speed, rms(in), r)
+		}
+	}
+}
+
+// TestIdentity makes sure a speed of 1 gives back the input, every segment continues the
+// previous one exactly
+func TestIdentity(t *testing.T) {
+	in := noise(5000, 1)
+	out, err := Scale(in, 1, sr, 1, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("Expected %v samples, got %v", len(in), len(out))
+	}
+	for i := range in {
+		if math.Abs(float64(in[i]-out[i])) > 1e-9 {
+			t.Fatalf("Sample %v: expected %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+// TestStreaming makes sure processing in odd sized blocks gives the same output as
+// scaling at once, for interleaved channels
+func TestStreaming(t *testing.T) {
+	in := wave.JoinChannels([][]wave.Frame{sine(300, 7000), noise(7000, 2)})
+	expected, err := Scale(in, 2, sr, 1.7, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s, _ := New(2, sr, 1.7, Options{})
+	out := []wave.Frame{}
+	for start := 0; start < len(in); start += 333 {
+		end := start + 333
+		if end > len(in) {
+			end = len(in)
+		}
+		out = append(out, s.Process(in[start:end])...)
+	}
+	out = append(out, s.Flush()...)
+
+	if len(out) != len(expected) {
+		t.Fatalf("Expected %v samples, got %v", len(expected), len(out))
+	}
+	for i := range expected {
+		if out[i] != expected[i] {
+			t.Fatalf("Sample %v: expected %v, got %v", i, expected[i], out[i])
+		}
+	}
+}
+
+func TestSetSpeed(t *testing.T) {
+	in := sine(220, 2*sr)
+	s, _ := New(1, sr, 1, Options{})
+	// the first second at normal speed, the second twice as fast
+	out := s.Process(in[:sr])
+	if err := s.SetSpeed(2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out = append(out, s.Process(in[sr:])...)
+	out = append(out, s.Flush()...)
+
+	// the speed changes once the first second has been read, which is a segment later
+	if expected := 1.5 * sr; math.Abs(float64(len(out))-expected) > 0.03*sr {
+		t.Fatalf("Expected about %v samples, got %v", expected, len(out))
+	}
+	if f := frequency(out); math.Abs(f-220) > 1 {
+		t.Fatalf("Expected 220 Hz, got %v", f)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := New(1, sr, 0.4, Options{}); err == nil {
+		t.Fatalf("Expected an error for a speed of 0.4")
+	}
+	if _, err := New(1, sr, 3.5, Options{}); err == nil {
+		t.Fatalf("Expected an error for a speed of 3.5")
+	}
+	if _, err := New(0, sr, 1, Options{}); err == nil {
+		t.Fatalf("Expected an error for 0 channels")
+	}
+	if _, err := New(1, sr, 1, Options{FrameSize: 0.0001}); err == nil {
+		t.Fatalf("Expected an error for a frame of a single sample")
+	}
+	if out, err := Scale(nil, 1, sr, 2, Options{}); err != nil || len(out) != 0 {
+		t.Fatalf("Expected no output for no input, got %v (%v)", out, err)
+	}
+}
+
+// TestLongInput makes sure the cost of Scale grows linearly with the input: scaling four
+// times as much audio at once shouldn't allocate much more than four times as much
+func TestLongInput(t *testing.T) {
+	allocated := func(n int) uint64 {
+		in := sine(220, n)
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		if _, err := Scale(in, 1, sr, 1.5, Options{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	short, long := allocated(10*sr), allocated(40*sr)
+	if ratio := float64(long) / float64(short); ratio > 6 {
+		t.Fatalf("Expected 4 times the allocations for 4 times the input, got %.1f times", ratio)
+	}
+}